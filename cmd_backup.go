@@ -0,0 +1,114 @@
+package main
+
+import (
+	"FMgo/internal/config"
+	"FMgo/internal/db"
+	"FMgo/internal/model"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// backupName 返回备份目录中按时间命名的备份文件路径
+func backupName(prefix string) (string, error) {
+	dir := filepath.Join(config.AppDir, "backups")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("创建备份目录失败: %v", err)
+	}
+	return filepath.Join(dir, prefix+time.Now().Format("20060102-150405")+".db"), nil
+}
+
+// runBackup 将数据库备份到文件，程序运行中也可执行
+func runBackup(args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	output := fs.String("o", "", "备份文件路径(默认 .fmgo/backups/fmgo-<时间>.db)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "用法: FMgo backup [-o file]\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	database, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	path := *output
+	if path == "" {
+		if path, err = backupName("fmgo-"); err != nil {
+			return err
+		}
+	}
+	if err := database.Backup(path); err != nil {
+		return err
+	}
+	fmt.Printf("已备份到 %s\n", path)
+	return nil
+}
+
+// runRestore 用备份文件替换数据库，替换前将当前数据库备份到备份目录
+func runRestore(args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "用法: FMgo restore <file>\n恢复前请先退出正在运行的 FMgo\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("需要指定一个备份文件")
+	}
+
+	database, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	before, err := backupName("fmgo-before-restore-")
+	if err != nil {
+		return err
+	}
+	if err := database.Restore(fs.Arg(0), before); err != nil {
+		return err
+	}
+	fmt.Printf("已从 %s 恢复，原数据库已备份到 %s\n", fs.Arg(0), before)
+	return nil
+}
+
+// runSync 将收藏与收听历史与同步目录中的文件合并
+func runSync(args []string) error {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	dir := fs.String("dir", "", "同步目录(如网盘同步的文件夹)，其中的 "+db.UserDataFile+" 在各设备间共享")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "用法: FMgo sync -dir path\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *dir == "" {
+		fs.Usage()
+		return fmt.Errorf("需要指定同步目录")
+	}
+
+	database, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	report, err := database.MergeUserData(*dir)
+	if err != nil {
+		return err
+	}
+	fmt.Println(formatMergeReport(report))
+	return nil
+}
+
+// formatMergeReport 返回合并结果的摘要
+func formatMergeReport(r *model.MergeReport) string {
+	return fmt.Sprintf("已与 %s 同步: 收藏新增 %d、更新 %d、移除 %d，收听记录导入 %d、导出 %d、删除 %d",
+		r.Path, r.FavoritesAdded, r.FavoritesUpdated, r.FavoritesRemoved, r.SessionsImported, r.SessionsExported, r.SessionsRemoved)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"FMgo/internal/catalog"
+	"FMgo/internal/model"
+	"FMgo/internal/playlist"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// runExport 将电台目录、收藏或播放历史导出为通用格式
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "", "导出格式: 目录与收藏为 "+strings.Join(playlist.Formats, "/")+"，历史为 "+strings.Join(playlist.HistoryFormats, "/")+"(默认根据输出文件扩展名判断，否则为 json)")
+	output := fs.String("o", "", "输出文件路径(默认输出到标准输出)")
+	catalogFlags := addCatalogFlags(fs)
+	remoteFlags := addRemoteFlags(fs)
+	source := fs.String("source", "", "只导出指定来源的电台(如 本地)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "用法: FMgo export [flags] catalog|favorites|history\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("需要指定导出内容")
+	}
+	what := fs.Arg(0)
+
+	if *format == "" {
+		switch ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(*output)), "."); ext {
+		case "":
+			*format = playlist.FormatJSON
+		case "m3u8":
+			*format = playlist.FormatM3U
+		case "xml":
+			*format = playlist.FormatOPML
+		default:
+			*format = ext
+		}
+	}
+
+	database, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	var write func(w io.Writer) error
+	switch what {
+	case "catalog":
+		sources, err := catalogFlags.sources(remoteFlags, database)
+		if err != nil {
+			return err
+		}
+		stations := catalog.New(sources.providers...)
+		if *source != "" {
+			p := stations.Provider(*source)
+			if p == nil {
+				return fmt.Errorf("未知的来源: %s", *source)
+			}
+			stations = catalog.New(p)
+		}
+		categories, err := stations.Categories()
+		if err != nil {
+			return err
+		}
+		write = func(w io.Writer) error { return playlist.Write(w, categories, *format) }
+	case "favorites":
+		favorites, err := database.GetFavorites()
+		if err != nil {
+			return fmt.Errorf("获取收藏失败: %v", err)
+		}
+		// 每个收藏文件夹导出为一个分类，未分组的收藏在"收藏"分类中
+		var categories []model.Category
+		for _, f := range favorites {
+			name := f.Folder
+			if name == "" {
+				name = "收藏"
+			}
+			if len(categories) == 0 || categories[len(categories)-1].Name != name {
+				categories = append(categories, model.Category{Name: name})
+			}
+			cat := &categories[len(categories)-1]
+			cat.RadioList = append(cat.RadioList, f.Radio)
+		}
+		write = func(w io.Writer) error { return playlist.Write(w, categories, *format) }
+	case "history":
+		history, err := database.GetHistory(-1) // LIMIT -1 即不限制条数
+		if err != nil {
+			return err
+		}
+		write = func(w io.Writer) error { return playlist.WriteHistory(w, history, *format) }
+	default:
+		fs.Usage()
+		return fmt.Errorf("未知的导出内容: %s", what)
+	}
+
+	if *output == "" {
+		return write(os.Stdout)
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("创建输出文件失败: %v", err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"FMgo/internal/catalog"
+	"FMgo/internal/playlist"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// runImport 从 M3U/PLS/OPML/JSON 文件导入电台到本地目录
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	category := fs.String("category", "", "导入到指定分类(默认使用文件中的分组，没有分组时为 \""+catalog.DefaultImportCategory+"\")")
+	format := fs.String("format", "", "文件格式: "+strings.Join(playlist.Formats, "/")+"(默认根据扩展名与内容判断)")
+	catalogFlags := addCatalogFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "用法: FMgo import [flags] <file>...\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("缺少导入文件")
+	}
+
+	database, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	files, err := catalogFlags.provider()
+	if err != nil {
+		return err
+	}
+	stations := catalog.New(files, catalog.NewLocal(database))
+
+	for _, path := range fs.Args() {
+		result, err := catalog.ImportFile(stations, database, path, *format, *category)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		fmt.Printf("%s: %s\n", path, result)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"FMgo/internal/catalog"
+	"FMgo/internal/model"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// weekdayNames 是按周一开始排列的星期名称
+var weekdayNames = []string{"周一", "周二", "周三", "周四", "周五", "周六", "周日"}
+
+// runStats 输出收听统计报告：本周与本月排行、时段与星期分布、连续收听天数和分类统计
+func runStats(args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	days := fs.Int("days", 30, "总时长、时段分布与分类统计覆盖的天数，0 表示全部")
+	format := fs.String("format", "text", "输出格式: text/json")
+	output := fs.String("o", "", "输出文件路径(默认输出到标准输出)")
+	catalogFlags := addCatalogFlags(fs)
+	remoteFlags := addRemoteFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "用法: FMgo stats [flags]\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("不支持的格式: %s", *format)
+	}
+
+	database, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	// 分类统计按电台目录归类
+	sources, err := catalogFlags.sources(remoteFlags, database)
+	if err != nil {
+		return err
+	}
+	categories, err := catalog.New(sources.providers...).Categories()
+	if err != nil {
+		return err
+	}
+
+	report, err := database.ListeningReport(time.Now(), *days, categories)
+	if err != nil {
+		return err
+	}
+
+	write := func(w io.Writer) error { return writeReportText(w, report) }
+	if *format == "json" {
+		write = func(w io.Writer) error {
+			enc := json.NewEncoder(w)
+			enc.SetEscapeHTML(false)
+			enc.SetIndent("", "  ")
+			return enc.Encode(report)
+		}
+	}
+	if *output == "" {
+		return write(os.Stdout)
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("创建输出文件失败: %v", err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeReportText 以文本形式输出统计报告，分布用字符条形图表示
+func writeReportText(w io.Writer, r *model.ListeningReport) error {
+	var b strings.Builder
+	period := "全部记录"
+	if r.Days > 0 {
+		period = fmt.Sprintf("最近 %d 天", r.Days)
+	}
+	fmt.Fprintf(&b, "收听统计（%s，生成于 %s）\n", period, r.GeneratedAt.Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, "总收听时长: %s\n", formatSeconds(r.TotalSeconds))
+	fmt.Fprintf(&b, "连续收听: 当前 %d 天，最长 %d 天\n", r.CurrentStreak, r.LongestStreak)
+
+	writeTop := func(title string, top []model.StationTotal) {
+		fmt.Fprintf(&b, "\n%s:\n", title)
+		if len(top) == 0 {
+			b.WriteString("  暂无记录\n")
+		}
+		for i, t := range top {
+			fmt.Fprintf(&b, "  %2d. %s  %s (%d 次)\n", i+1, t.RadioName, formatSeconds(t.ListenedSeconds), t.Sessions)
+		}
+	}
+	writeTop("本周排行", r.TopWeek)
+	writeTop("本月排行", r.TopMonth)
+
+	b.WriteString("\n按时段:\n")
+	for hour, seconds := range r.ByHour {
+		fmt.Fprintf(&b, "  %02d:00 %-20s %s\n", hour, textBar(seconds, r.ByHour[:]), formatSeconds(seconds))
+	}
+	b.WriteString("\n按星期:\n")
+	for i, seconds := range r.ByWeekday {
+		fmt.Fprintf(&b, "  %s %-20s %s\n", weekdayNames[i], textBar(seconds, r.ByWeekday[:]), formatSeconds(seconds))
+	}
+
+	b.WriteString("\n按分类:\n")
+	if len(r.Categories) == 0 {
+		b.WriteString("  暂无记录\n")
+	}
+	for _, c := range r.Categories {
+		fmt.Fprintf(&b, "  %s  %s\n", c.Category, formatSeconds(c.ListenedSeconds))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// textBar 返回 value 相对 values 中最大值的字符条形图，最长 20 个字符
+func textBar(value int64, values []int64) string {
+	var peak int64
+	for _, v := range values {
+		if v > peak {
+			peak = v
+		}
+	}
+	if peak == 0 {
+		return ""
+	}
+	return strings.Repeat("█", int(value*20/peak))
+}
+
+// formatSeconds 返回收听时长的简短描述
+func formatSeconds(seconds int64) string {
+	d := time.Duration(seconds) * time.Second
+	switch {
+	case d >= time.Hour:
+		return fmt.Sprintf("%d 小时 %d 分", int(d.Hours()), int(d.Minutes())%60)
+	case d >= time.Minute:
+		return fmt.Sprintf("%d 分", int(d.Minutes()))
+	case d > 0:
+		return "不足 1 分"
+	default:
+		return "-"
+	}
+}
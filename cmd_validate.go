@@ -0,0 +1,51 @@
+package main
+
+import (
+	"FMgo/internal/catalog"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// runValidate 检查电台配置文件，存在错误时以非零状态退出，可用于提交前钩子
+func runValidate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	strict := fs.Bool("strict", false, "将警告（未知字段、分类间重复的电台等）也视为错误")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "用法: FMgo validate [flags] <file>...\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("缺少配置文件")
+	}
+
+	errors, warnings := 0, 0
+	for _, path := range fs.Args() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		issues := catalog.ValidateCatalog(path, data)
+		for _, issue := range issues {
+			fmt.Fprintln(os.Stderr, issue)
+			if issue.Warning {
+				warnings++
+			} else {
+				errors++
+			}
+		}
+		if len(issues) == 0 {
+			fmt.Printf("%s: ok\n", path)
+		}
+	}
+
+	if *strict {
+		errors += warnings
+	}
+	if errors > 0 {
+		return fmt.Errorf("发现 %d 个错误", errors)
+	}
+	return nil
+}
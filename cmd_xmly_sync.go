@@ -0,0 +1,37 @@
+package main
+
+import (
+	"FMgo/internal/catalog"
+	"flag"
+	"fmt"
+)
+
+// runXimalayaSync 拉取喜马拉雅直播电台目录并同步到本地目录
+func runXimalayaSync(args []string) error {
+	fs := flag.NewFlagSet("xmly-sync", flag.ExitOnError)
+	baseURL := fs.String("url", catalog.DefaultXimalayaURL, "喜马拉雅直播电台接口地址")
+	fs.Parse(args)
+
+	database, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	report, err := catalog.SyncXimalaya(catalog.NewXimalaya(*baseURL), database)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("同步完成: %s\n", catalog.FormatSyncReport(report))
+	for _, r := range report.Renamed {
+		fmt.Printf("  改名: %s -> %s\n", r.Old, r.New)
+	}
+	for _, name := range report.Removed {
+		fmt.Printf("  下架: %s\n", name)
+	}
+	for _, name := range report.Restored {
+		fmt.Printf("  恢复: %s\n", name)
+	}
+	return nil
+}
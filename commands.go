@@ -0,0 +1,160 @@
+package main
+
+import (
+	"FMgo/internal/catalog"
+	"FMgo/internal/config"
+	"FMgo/internal/db"
+	"FMgo/internal/logger"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// commands 是可用的子命令，使用方式为 `FMgo <command> [flags]`
+var commands = map[string]func(args []string) error{
+	"backup":    runBackup,
+	"export":    runExport,
+	"import":    runImport,
+	"restore":   runRestore,
+	"stats":     runStats,
+	"sync":      runSync,
+	"validate":  runValidate,
+	"xmly-sync": runXimalayaSync,
+}
+
+// runCommand 执行命令行中的子命令，未指定子命令时返回 false
+func runCommand() bool {
+	if len(os.Args) < 2 {
+		return false
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		return false
+	}
+	if err := cmd(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+	return true
+}
+
+// openDatabase 初始化目录、日志并打开数据库，供子命令使用
+func openDatabase() (*db.Database, error) {
+	if err := config.Init(); err != nil {
+		return nil, fmt.Errorf("初始化目录失败: %v", err)
+	}
+	if err := logger.Init(); err != nil {
+		return nil, err
+	}
+	return db.New()
+}
+
+// stringList 是可重复指定的字符串参数
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// catalogFlags 是组装配置文件目录的参数，主程序与子命令共用
+type catalogFlags struct {
+	fs      *flag.FlagSet
+	configs stringList
+	builtin *bool
+}
+
+func addCatalogFlags(fs *flag.FlagSet) *catalogFlags {
+	c := &catalogFlags{fs: fs}
+	fs.Var(&c.configs, "config", "外部电台配置文件路径(可选，可指定多次，后指定的文件优先)")
+	c.builtin = fs.Bool("builtin", true, "包含内置电台列表(指定 -config 时默认不包含)")
+	return c
+}
+
+// provider 返回叠加内置列表、catalog.d 与 -config 文件的来源，任一文件有误时返回错误
+func (c *catalogFlags) provider() (*catalog.LayeredProvider, error) {
+	includeBuiltin := len(c.configs) == 0
+	c.fs.Visit(func(f *flag.Flag) {
+		if f.Name == "builtin" {
+			includeBuiltin = *c.builtin
+		}
+	})
+
+	var builtin *catalog.JSONProvider
+	if includeBuiltin {
+		builtin = catalog.NewEmbedded(defaultRadioConfig)
+	}
+	p := catalog.NewLayered(builtin, config.CatalogDir, c.configs...)
+	issues, err := p.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if catalog.HasErrors(issues) {
+		return nil, &catalog.ValidationError{Issues: issues}
+	}
+	for _, issue := range issues {
+		logger.Info("%s", issue)
+	}
+	return p, nil
+}
+
+// remoteFlags 是订阅远程目录的参数，主程序与子命令共用
+type remoteFlags struct {
+	urls    stringList
+	refresh *time.Duration
+	sha256  *bool
+	pubkey  *string
+}
+
+func addRemoteFlags(fs *flag.FlagSet) *remoteFlags {
+	r := &remoteFlags{}
+	fs.Var(&r.urls, "remote-catalog", "远程电台目录地址(可选，可指定多次，JSON 格式同 radio.json)")
+	r.refresh = fs.Duration("remote-refresh", catalog.DefaultRemoteRefresh, "远程目录的刷新间隔，0 表示只在启动时获取")
+	r.sha256 = fs.Bool("remote-sha256", false, "校验远程目录地址加 .sha256 处发布的 SHA-256")
+	r.pubkey = fs.String("remote-pubkey", "", "校验远程目录地址加 .sig 处发布的签名所用的 ed25519 公钥(base64)")
+	return r
+}
+
+// providers 返回全部远程目录来源
+func (r *remoteFlags) providers() ([]*catalog.RemoteProvider, error) {
+	var remotes []*catalog.RemoteProvider
+	for _, u := range r.urls {
+		remote := catalog.NewRemote(u, config.RemoteDir)
+		remote.SetChecksumVerification(*r.sha256)
+		if err := remote.SetPublicKey(*r.pubkey); err != nil {
+			return nil, err
+		}
+		remotes = append(remotes, remote)
+	}
+	return remotes, nil
+}
+
+// catalogSources 是组装好的电台目录来源
+type catalogSources struct {
+	files     *catalog.LayeredProvider
+	remotes   []*catalog.RemoteProvider
+	providers []catalog.Provider // 按显示顺序：配置文件、本地目录、远程目录
+}
+
+// sources 组装配置文件、本地目录与远程目录的来源，主程序与子命令共用
+func (c *catalogFlags) sources(r *remoteFlags, local db.LocalCatalogStore) (*catalogSources, error) {
+	files, err := c.provider()
+	if err != nil {
+		return nil, err
+	}
+	remotes, err := r.providers()
+	if err != nil {
+		return nil, err
+	}
+	providers := []catalog.Provider{files, catalog.NewLocal(local)}
+	for _, remote := range remotes {
+		providers = append(providers, remote)
+	}
+	return &catalogSources{files: files, remotes: remotes, providers: providers}, nil
+}
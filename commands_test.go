@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"FMgo/internal/config"
+)
+
+func TestCatalogFlagsBuiltin(t *testing.T) {
+	dir := t.TempDir()
+	config.CatalogDir = filepath.Join(dir, "catalog.d")
+	user := filepath.Join(dir, "user.json")
+	if err := os.WriteFile(user, []byte(`[{"name": "本地", "radioList": [{"name": "城市之声", "playUrl": "http://example.com/city"}]}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args    []string
+		builtin bool
+	}{
+		{nil, true},
+		{[]string{"-builtin=false"}, false},
+		{[]string{"-config", user}, false},
+		{[]string{"-config", user, "-builtin"}, true},
+		{[]string{"-builtin=false", "-config", user}, false},
+	}
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("fmgo", flag.ContinueOnError)
+		c := addCatalogFlags(fs)
+		if err := fs.Parse(tt.args); err != nil {
+			t.Fatal(err)
+		}
+		p, err := c.provider()
+		if err != nil {
+			t.Errorf("%v: %v", tt.args, err)
+			continue
+		}
+		categories, err := p.Categories()
+		if err != nil {
+			t.Errorf("%v: %v", tt.args, err)
+			continue
+		}
+		hasBuiltin := false
+		for _, cat := range categories {
+			for _, radio := range cat.RadioList {
+				if radio.Source == "内置" {
+					hasBuiltin = true
+				}
+			}
+		}
+		if hasBuiltin != tt.builtin {
+			t.Errorf("%v: includes builtin = %v, want %v", tt.args, hasBuiltin, tt.builtin)
+		}
+	}
+}
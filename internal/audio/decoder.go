@@ -0,0 +1,64 @@
+package audio
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+)
+
+// Decoder 使用 ffmpeg 将压缩音频流解码为 PCM
+type Decoder struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+}
+
+// DecoderAvailable 返回系统中是否安装了 ffmpeg
+func DecoderAvailable() bool {
+	_, err := exec.LookPath("ffmpeg")
+	return err == nil
+}
+
+// NewDecoder 启动一个 ffmpeg 解码进程
+func NewDecoder() (*Decoder, error) {
+	cmd := exec.Command("ffmpeg",
+		"-loglevel", "quiet",
+		"-i", "pipe:0",
+		"-f", "s16le",
+		"-ar", strconv.Itoa(SampleRate),
+		"-ac", strconv.Itoa(Channels),
+		"pipe:1",
+	)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("创建解码器输入失败: %v", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("创建解码器输出失败: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("启动解码器失败: %v", err)
+	}
+	return &Decoder{cmd: cmd, stdin: stdin, stdout: stdout}, nil
+}
+
+// Write 写入压缩音频数据
+func (d *Decoder) Write(p []byte) (int, error) {
+	return d.stdin.Write(p)
+}
+
+// Read 读取解码后的 PCM 数据
+func (d *Decoder) Read(p []byte) (int, error) {
+	return d.stdout.Read(p)
+}
+
+// Close 结束解码进程
+func (d *Decoder) Close() error {
+	d.stdin.Close()
+	if d.cmd.Process != nil {
+		d.cmd.Process.Kill()
+	}
+	return d.cmd.Wait()
+}
@@ -0,0 +1,167 @@
+package audio
+
+import (
+	"math"
+	"sync"
+)
+
+// Band 是均衡器的一个频段
+type Band struct {
+	Freq float64 `json:"freq"` // 中心频率，Hz
+	Gain float64 `json:"gain"` // 增益，dB
+}
+
+// Preset 是一组命名的均衡器频段设置
+type Preset struct {
+	Name  string
+	Label string
+	Bands []Band
+}
+
+const (
+	// PresetFlat 表示不做任何处理
+	PresetFlat = "flat"
+	// PresetCustom 表示用户自定义的频段
+	PresetCustom = "custom"
+
+	// MaxGain 是单个频段允许的最大增益（绝对值），dB
+	MaxGain = 12
+	bandQ   = 1.0
+)
+
+// BandFrequencies 是均衡器使用的频段中心频率
+var BandFrequencies = []float64{60, 230, 910, 3600, 14000}
+
+// Presets 是内置的均衡器预设
+var Presets = []Preset{
+	{Name: PresetFlat, Label: "平直", Bands: bandsWithGains(0, 0, 0, 0, 0)},
+	{Name: "voice", Label: "人声", Bands: bandsWithGains(-6, -2, 2, 4, -1)},
+	{Name: "music", Label: "音乐", Bands: bandsWithGains(3, 1, 0, 2, 3)},
+	{Name: "bass_boost", Label: "低音增强", Bands: bandsWithGains(8, 5, 0, 0, 0)},
+}
+
+func bandsWithGains(gains ...float64) []Band {
+	bands := make([]Band, len(BandFrequencies))
+	for i, freq := range BandFrequencies {
+		bands[i] = Band{Freq: freq, Gain: gains[i]}
+	}
+	return bands
+}
+
+// FindPreset 按名称查找内置预设
+func FindPreset(name string) (Preset, bool) {
+	for _, p := range Presets {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Preset{}, false
+}
+
+// FlatBands 返回一组零增益的频段
+func FlatBands() []Band {
+	return bandsWithGains(0, 0, 0, 0, 0)
+}
+
+// biquad 是一个二阶 IIR 滤波器（RBJ Audio EQ Cookbook 的峰值滤波器）
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	// 每个声道的滤波状态
+	x1, x2, y1, y2 [Channels]float64
+}
+
+func newPeakingFilter(freq, gain float64) *biquad {
+	a := math.Pow(10, gain/40)
+	w0 := 2 * math.Pi * freq / SampleRate
+	alpha := math.Sin(w0) / (2 * bandQ)
+	cos := math.Cos(w0)
+
+	a0 := 1 + alpha/a
+	return &biquad{
+		b0: (1 + alpha*a) / a0,
+		b1: -2 * cos / a0,
+		b2: (1 - alpha*a) / a0,
+		a1: -2 * cos / a0,
+		a2: (1 - alpha/a) / a0,
+	}
+}
+
+func (f *biquad) process(ch int, x float64) float64 {
+	y := f.b0*x + f.b1*f.x1[ch] + f.b2*f.x2[ch] - f.a1*f.y1[ch] - f.a2*f.y2[ch]
+	f.x2[ch], f.x1[ch] = f.x1[ch], x
+	f.y2[ch], f.y1[ch] = f.y1[ch], y
+	return y
+}
+
+// Equalizer 是作用于 PCM 数据的多频段均衡器，可在播放过程中安全地修改频段
+type Equalizer struct {
+	mu      sync.Mutex
+	bands   []Band
+	filters []*biquad
+}
+
+// NewEqualizer 创建一个平直（不处理）的均衡器
+func NewEqualizer() *Equalizer {
+	return &Equalizer{bands: FlatBands()}
+}
+
+// SetBands 设置频段增益
+func (e *Equalizer) SetBands(bands []Band) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.bands = append([]Band(nil), bands...)
+	e.filters = nil
+	for _, b := range e.bands {
+		if b.Gain == 0 {
+			continue
+		}
+		e.filters = append(e.filters, newPeakingFilter(b.Freq, clampGain(b.Gain)))
+	}
+}
+
+// Bands 返回当前频段设置的副本
+func (e *Equalizer) Bands() []Band {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]Band(nil), e.bands...)
+}
+
+// Process 就地处理交错的 16 位立体声 PCM 样本
+func (e *Equalizer) Process(samples []int16) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.filters) == 0 {
+		return
+	}
+	for i := range samples {
+		ch := i % Channels
+		x := float64(samples[i])
+		for _, f := range e.filters {
+			x = f.process(ch, x)
+		}
+		samples[i] = ClampSample(x)
+	}
+}
+
+func clampGain(gain float64) float64 {
+	if gain > MaxGain {
+		return MaxGain
+	}
+	if gain < -MaxGain {
+		return -MaxGain
+	}
+	return gain
+}
+
+// ClampSample 将样本值截断到 16 位有符号整数范围
+func ClampSample(x float64) int16 {
+	if x > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	if x < math.MinInt16 {
+		return math.MinInt16
+	}
+	return int16(x)
+}
@@ -0,0 +1,38 @@
+package audio
+
+import "encoding/binary"
+
+const (
+	// SampleRate 是解码后 PCM 的采样率
+	SampleRate = 44100
+	// Channels 是解码后 PCM 的声道数
+	Channels = 2
+	// BytesPerFrame 是一个采样帧（所有声道）的字节数
+	BytesPerFrame = Channels * 2
+)
+
+// BytesToSamples 将小端 16 位 PCM 字节转换为样本
+func BytesToSamples(b []byte, samples []int16) []int16 {
+	n := len(b) / 2
+	if cap(samples) < n {
+		samples = make([]int16, n)
+	}
+	samples = samples[:n]
+	for i := 0; i < n; i++ {
+		samples[i] = int16(binary.LittleEndian.Uint16(b[i*2:]))
+	}
+	return samples
+}
+
+// SamplesToBytes 将样本写回小端 16 位 PCM 字节
+func SamplesToBytes(samples []int16, b []byte) []byte {
+	n := len(samples) * 2
+	if cap(b) < n {
+		b = make([]byte, n)
+	}
+	b = b[:n]
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(b[i*2:], uint16(s))
+	}
+	return b
+}
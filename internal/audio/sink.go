@@ -0,0 +1,227 @@
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Sink 是 PCM 数据的播放输出
+type Sink interface {
+	io.Writer
+	Close() error
+}
+
+// Backends 是支持的音频输出后端
+var Backends = []string{"afplay", "ffplay", "aplay", "play"}
+
+// DefaultBackend 根据平台和已安装的程序选择音频输出后端
+func DefaultBackend() string {
+	if runtime.GOOS == "darwin" {
+		return "afplay"
+	}
+	for _, name := range []string{"ffplay", "aplay", "play"} {
+		if _, err := exec.LookPath(name); err == nil {
+			return name
+		}
+	}
+	return "ffplay"
+}
+
+// OpenSink 打开指定后端的输出，tempDir 用于需要中间文件的后端
+func OpenSink(backend, tempDir string) (Sink, error) {
+	rate := strconv.Itoa(SampleRate)
+	channels := strconv.Itoa(Channels)
+
+	switch backend {
+	case "afplay":
+		return newFileSink(filepath.Join(tempDir, "stream-buffer.wav"))
+	case "ffplay":
+		return newPipeSink("ffplay", "-nodisp", "-autoexit", "-loglevel", "quiet",
+			"-f", "s16le", "-ar", rate, "-ac", channels, "-i", "pipe:0")
+	case "aplay":
+		return newPipeSink("aplay", "-q", "-f", "S16_LE", "-r", rate, "-c", channels)
+	case "play":
+		return newPipeSink("play", "-q", "-t", "raw", "-r", rate, "-e", "signed", "-b", "16", "-c", channels, "-")
+	default:
+		return nil, fmt.Errorf("不支持的音频后端: %s", backend)
+	}
+}
+
+// pipeSink 通过标准输入将 PCM 交给外部播放程序
+type pipeSink struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+}
+
+func newPipeSink(name string, args ...string) (*pipeSink, error) {
+	cmd := exec.Command(name, args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("创建 %s 输入失败: %v", name, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("启动 %s 失败: %v", name, err)
+	}
+	return &pipeSink{cmd: cmd, stdin: stdin}, nil
+}
+
+func (s *pipeSink) Write(p []byte) (int, error) {
+	return s.stdin.Write(p)
+}
+
+func (s *pipeSink) Close() error {
+	s.stdin.Close()
+	if s.cmd.Process != nil {
+		s.cmd.Process.Kill()
+	}
+	s.cmd.Wait()
+	return nil
+}
+
+// fileSegmentBytes 是单个缓冲文件的最大数据量（约 5 分钟），写满后换到新文件，
+// 使磁盘占用不随播放时长增长
+const fileSegmentBytes = 5 * 60 * SampleRate * BytesPerFrame
+
+// fileSink 将 PCM 依次写入若干 WAV 缓冲文件，并用 afplay 按顺序播放。
+// afplay 不能从标准输入读取，因此每个文件在写入的同时被播放，播放完后删除
+type fileSink struct {
+	mu       sync.Mutex
+	base     string
+	file     *os.File
+	path     string
+	written  int
+	seq      int
+	segments chan string // 等待播放的缓冲文件
+	done     chan struct{}
+
+	cmdMu  sync.Mutex
+	cmd    *exec.Cmd
+	closed bool
+	err    error // afplay 启动失败的错误，在下次写入时返回
+}
+
+func newFileSink(path string) (*fileSink, error) {
+	s := &fileSink{
+		base:     strings.TrimSuffix(path, filepath.Ext(path)),
+		segments: make(chan string, 4),
+		done:     make(chan struct{}),
+	}
+	if err := s.openSegment(); err != nil {
+		return nil, err
+	}
+	go s.play()
+	return s, nil
+}
+
+// openSegment 创建下一个缓冲文件，首次写入时交给播放协程
+func (s *fileSink) openSegment() error {
+	s.seq++
+	path := fmt.Sprintf("%s-%d.wav", s.base, s.seq)
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("创建缓冲文件失败: %v", err)
+	}
+	if err := writeStreamingWAVHeader(file); err != nil {
+		file.Close()
+		os.Remove(path)
+		return fmt.Errorf("写入 WAV 头失败: %v", err)
+	}
+	s.file, s.path, s.written = file, path, 0
+	return nil
+}
+
+func (s *fileSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cmdMu.Lock()
+	err := s.err
+	s.cmdMu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+
+	if s.written >= fileSegmentBytes {
+		s.file.Close()
+		if err := s.openSegment(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := s.file.Write(p)
+	if s.written == 0 && n > 0 {
+		s.segments <- s.path
+	}
+	s.written += n
+	return n, err
+}
+
+// play 依次播放缓冲文件，上一个文件播放结束后才开始下一个
+func (s *fileSink) play() {
+	defer close(s.done)
+	for path := range s.segments {
+		s.cmdMu.Lock()
+		if s.closed || s.err != nil {
+			s.cmdMu.Unlock()
+			os.Remove(path)
+			continue
+		}
+		cmd := exec.Command("afplay", path)
+		if err := cmd.Start(); err != nil {
+			s.err = fmt.Errorf("启动 afplay 失败: %v", err)
+			s.cmdMu.Unlock()
+			os.Remove(path)
+			continue
+		}
+		s.cmd = cmd
+		s.cmdMu.Unlock()
+
+		cmd.Wait()
+		os.Remove(path)
+	}
+}
+
+func (s *fileSink) Close() error {
+	s.mu.Lock()
+	s.file.Close()
+	path := s.path
+	close(s.segments)
+	s.mu.Unlock()
+
+	s.cmdMu.Lock()
+	s.closed = true
+	if s.cmd != nil && s.cmd.Process != nil {
+		s.cmd.Process.Kill()
+	}
+	s.cmdMu.Unlock()
+
+	<-s.done
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// writeStreamingWAVHeader 写入数据长度为最大值的 WAV 头，使播放器持续读取增长中的文件
+func writeStreamingWAVHeader(w io.Writer) error {
+	const maxSize = 0xFFFFFFFF - 36
+	header := []interface{}{
+		[]byte("RIFF"), uint32(maxSize + 36), []byte("WAVE"),
+		[]byte("fmt "), uint32(16), uint16(1), uint16(Channels),
+		uint32(SampleRate), uint32(SampleRate * BytesPerFrame), uint16(BytesPerFrame), uint16(16),
+		[]byte("data"), uint32(maxSize),
+	}
+	for _, v := range header {
+		if err := binary.Write(w, binary.LittleEndian, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
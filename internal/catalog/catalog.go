@@ -0,0 +1,173 @@
+package catalog
+
+import (
+	"FMgo/internal/logger"
+	"FMgo/internal/model"
+	"strings"
+)
+
+// Provider 是电台目录的来源
+type Provider interface {
+	// Name 返回来源名称，用于在合并视图中标注电台来源
+	Name() string
+	// Categories 返回该来源的全部分类及电台
+	Categories() ([]model.Category, error)
+	// Stations 返回指定分类下的电台
+	Stations(category string) ([]model.Radio, error)
+	// Search 按名称搜索电台
+	Search(query string) ([]model.Radio, error)
+	// ResolveStreamURL 返回电台实际的播放地址
+	ResolveStreamURL(radio model.Radio) (string, error)
+}
+
+// Catalog 将多个来源合并为一个视图，同名分类合并，电台标注来源（来源已标注时保留，如配置文件名）
+type Catalog struct {
+	providers []Provider
+}
+
+// New 创建合并目录，providers 的顺序即分类的显示顺序
+func New(providers ...Provider) *Catalog {
+	return &Catalog{providers: providers}
+}
+
+// Providers 返回全部来源
+func (c *Catalog) Providers() []Provider {
+	return c.providers
+}
+
+// Provider 按名称查找来源
+func (c *Catalog) Provider(name string) Provider {
+	for _, p := range c.providers {
+		if p.Name() == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// Categories 返回合并后的分类。单个来源失败时记录日志并跳过，全部失败时返回第一个错误
+func (c *Catalog) Categories() ([]model.Category, error) {
+	var merged []model.Category
+	index := make(map[string]int)
+	var firstErr error
+	failed := 0
+
+	for _, p := range c.providers {
+		categories, err := p.Categories()
+		if err != nil {
+			logger.Error("加载电台来源 %s 失败: %v", p.Name(), err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed++
+			continue
+		}
+		for _, cat := range categories {
+			i, ok := index[cat.Name]
+			if !ok {
+				i = len(merged)
+				index[cat.Name] = i
+				merged = append(merged, model.Category{Name: cat.Name})
+			}
+			for _, radio := range cat.RadioList {
+				if radio.Source == "" {
+					radio.Source = p.Name()
+				}
+				merged[i].RadioList = append(merged[i].RadioList, radio)
+			}
+		}
+	}
+
+	if failed > 0 && failed == len(c.providers) {
+		return nil, firstErr
+	}
+	return merged, nil
+}
+
+// Search 在所有来源中搜索电台
+func (c *Catalog) Search(query string) ([]model.Radio, error) {
+	var results []model.Radio
+	for _, p := range c.providers {
+		radios, err := p.Search(query)
+		if err != nil {
+			logger.Error("在 %s 中搜索失败: %v", p.Name(), err)
+			continue
+		}
+		for _, radio := range radios {
+			if radio.Source == "" {
+				radio.Source = p.Name()
+			}
+			results = append(results, radio)
+		}
+	}
+	return results, nil
+}
+
+// ResolveStreamURL 由电台所属来源解析播放地址，来源未知时直接使用 PlayURL
+func (c *Catalog) ResolveStreamURL(radio model.Radio) (string, error) {
+	if p := c.Provider(radio.Source); p != nil {
+		return p.ResolveStreamURL(radio)
+	}
+	return radio.PlayURL, nil
+}
+
+// stationsIn 返回 categories 中指定分类的电台
+func stationsIn(categories []model.Category, category string) []model.Radio {
+	for _, cat := range categories {
+		if cat.Name == category {
+			return cat.RadioList
+		}
+	}
+	return nil
+}
+
+// searchIn 在 categories 中搜索匹配 query 的电台，规则见 MatchRadio
+func searchIn(categories []model.Category, query string) []model.Radio {
+	var results []model.Radio
+	for _, cat := range categories {
+		for _, radio := range cat.RadioList {
+			if MatchRadio(radio, query) {
+				results = append(results, radio)
+			}
+		}
+	}
+	return results
+}
+
+// MatchRadio 判断电台是否匹配搜索输入（忽略大小写）。输入格式同 ParseDirectoryQuery：
+// 普通词需全部出现在名称、描述、标签或地区中，country:/region:/lang:/tag:/codec:/bitrate: 按字段过滤
+func MatchRadio(radio model.Radio, input string) bool {
+	q := ParseDirectoryQuery(input)
+	contains := func(s, sub string) bool {
+		return strings.Contains(strings.ToLower(s), strings.ToLower(sub))
+	}
+
+	if q.Country != "" && !contains(radio.Country, q.Country) {
+		return false
+	}
+	if q.Region != "" && !contains(radio.Province, q.Region) && !contains(radio.City, q.Region) {
+		return false
+	}
+	if q.Language != "" && !contains(radio.Language, q.Language) {
+		return false
+	}
+	if q.Tag != "" && !contains(strings.Join(radio.Tags, ","), q.Tag) {
+		return false
+	}
+	if q.Codec != "" && !strings.EqualFold(radio.Codec, q.Codec) {
+		return false
+	}
+	if q.BitrateMin > 0 && radio.Bitrate < q.BitrateMin {
+		return false
+	}
+
+	text := strings.Join([]string{
+		radio.Name, radio.Description, radio.Country, radio.Province, radio.City, strings.Join(radio.Tags, " "),
+	}, "\n")
+	for _, word := range strings.Fields(q.Name) {
+		if !contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
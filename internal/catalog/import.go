@@ -0,0 +1,78 @@
+package catalog
+
+import (
+	"fmt"
+	"os"
+
+	"FMgo/internal/db"
+	"FMgo/internal/model"
+	"FMgo/internal/playlist"
+)
+
+// DefaultImportCategory 是导入文件中没有分类信息的电台默认归入的分类
+const DefaultImportCategory = "导入"
+
+// ImportResult 是一次导入的结果
+type ImportResult struct {
+	Added   int
+	Skipped int // 地址已存在而跳过的电台
+}
+
+func (r ImportResult) String() string {
+	return fmt.Sprintf("导入 %d 个电台，跳过 %d 个重复电台", r.Added, r.Skipped)
+}
+
+// ImportFile 读取 M3U/PLS/OPML/JSON 文件并导入本地目录，format 为空时自动判断格式。
+// category 非空时全部电台归入该分类，否则使用文件中的分组（如 group-title），
+// 没有分组的归入 DefaultImportCategory。已在 c 的任一来源中出现的地址不会重复导入
+func ImportFile(c *Catalog, database db.LocalCatalogStore, path, format, category string) (ImportResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("读取导入文件失败: %v", err)
+	}
+	categories, err := playlist.Parse(path, data, format)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	return Import(c, database, categories, category)
+}
+
+// Import 将解析好的电台导入本地目录，规则同 ImportFile
+func Import(c *Catalog, database db.LocalCatalogStore, categories []model.Category, category string) (ImportResult, error) {
+	var grouped []model.Category
+	index := make(map[string]int)
+	for _, cat := range categories {
+		name := cat.Name
+		if category != "" {
+			name = category
+		} else if name == "" {
+			name = DefaultImportCategory
+		}
+		i, ok := index[name]
+		if !ok {
+			i = len(grouped)
+			index[name] = i
+			grouped = append(grouped, model.Category{Name: name})
+		}
+		grouped[i].RadioList = append(grouped[i].RadioList, cat.RadioList...)
+	}
+
+	known := make(map[string]bool)
+	if c != nil {
+		existing, err := c.Categories()
+		if err != nil {
+			return ImportResult{}, err
+		}
+		for _, cat := range existing {
+			for _, radio := range cat.RadioList {
+				known[radio.PlayURL] = true
+			}
+		}
+	}
+
+	added, skipped, err := database.ImportLocalStations(grouped, known)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	return ImportResult{Added: added, Skipped: skipped}, nil
+}
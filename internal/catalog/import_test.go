@@ -0,0 +1,55 @@
+package catalog
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"FMgo/internal/config"
+	"FMgo/internal/db"
+	"FMgo/internal/model"
+)
+
+func TestImportDedup(t *testing.T) {
+	config.DBFile = filepath.Join(t.TempDir(), "fmgo.db")
+	database, err := db.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+
+	c := New(NewEmbedded([]byte(`[{"name": "新闻", "radioList": [{"name": "中国之声", "playUrl": "http://example.com/cnr1"}]}]`)))
+	radio := func(name, url string) model.Radio { return model.Radio{Name: name, PlayURL: url} }
+	imported := []model.Category{
+		{Name: "新闻", RadioList: []model.Radio{radio("中国之声", "http://example.com/cnr1"), radio("经济之声", "http://example.com/cnr2")}},
+		{RadioList: []model.Radio{radio("经济之声 副本", "http://example.com/cnr2"), radio("音乐之声", "http://example.com/music")}},
+	}
+
+	result, err := Import(c, database, imported, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Added != 2 || result.Skipped != 2 {
+		t.Errorf("first import = %+v, want 2 added, 2 skipped", result)
+	}
+
+	local, err := database.GetLocalCatalog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []model.Category{
+		{Name: "新闻", RadioList: []model.Radio{radio("经济之声", "http://example.com/cnr2")}},
+		{Name: DefaultImportCategory, RadioList: []model.Radio{radio("音乐之声", "http://example.com/music")}},
+	}
+	if !reflect.DeepEqual(local, want) {
+		t.Errorf("local catalog = %+v, want %+v", local, want)
+	}
+
+	result, err = Import(nil, database, imported, "收藏")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Added != 1 || result.Skipped != 3 {
+		t.Errorf("second import = %+v, want 1 added, 3 skipped", result)
+	}
+}
@@ -0,0 +1,93 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"FMgo/internal/model"
+)
+
+// JSONProvider 从 JSON 格式（[]model.Category）加载电台目录，用于内置 radio.json 与外部配置文件
+type JSONProvider struct {
+	name string
+	path string // 用于在错误信息中标注位置
+	load func() ([]byte, error)
+}
+
+// NewEmbedded 创建内置电台目录来源
+func NewEmbedded(data []byte) *JSONProvider {
+	return &JSONProvider{
+		name: "内置",
+		path: "radio.json",
+		load: func() ([]byte, error) { return data, nil },
+	}
+}
+
+// NewFile 创建外部配置文件来源，每次加载都重新读取文件
+func NewFile(path string) *JSONProvider {
+	return &JSONProvider{
+		name: filepath.Base(path),
+		path: path,
+		load: func() ([]byte, error) { return os.ReadFile(path) },
+	}
+}
+
+func (p *JSONProvider) Name() string {
+	return p.name
+}
+
+func (p *JSONProvider) Categories() ([]model.Category, error) {
+	data, err := p.load()
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件 %s 失败: %v", p.path, err)
+	}
+	if issues := ValidateCatalog(p.path, data); HasErrors(issues) {
+		return nil, &ValidationError{Issues: errorsOnly(issues)}
+	}
+	var categories []model.Category
+	if err := json.Unmarshal(data, &categories); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %v", err)
+	}
+	return categories, nil
+}
+
+// Validate 读取并检查配置文件，返回全部错误与警告
+func (p *JSONProvider) Validate() ([]Issue, error) {
+	data, err := p.load()
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件 %s 失败: %v", p.path, err)
+	}
+	return ValidateCatalog(p.path, data), nil
+}
+
+func errorsOnly(issues []Issue) []Issue {
+	var errs []Issue
+	for _, issue := range issues {
+		if !issue.Warning {
+			errs = append(errs, issue)
+		}
+	}
+	return errs
+}
+
+func (p *JSONProvider) Stations(category string) ([]model.Radio, error) {
+	categories, err := p.Categories()
+	if err != nil {
+		return nil, err
+	}
+	return stationsIn(categories, category), nil
+}
+
+func (p *JSONProvider) Search(query string) ([]model.Radio, error) {
+	categories, err := p.Categories()
+	if err != nil {
+		return nil, err
+	}
+	return searchIn(categories, query), nil
+}
+
+func (p *JSONProvider) ResolveStreamURL(radio model.Radio) (string, error) {
+	return radio.PlayURL, nil
+}
@@ -0,0 +1,164 @@
+package catalog
+
+import (
+	"path/filepath"
+
+	"FMgo/internal/model"
+)
+
+// ConfigName 是配置文件目录的来源名称
+const ConfigName = "配置"
+
+// LayeredProvider 按内置列表、catalog.d/*.json（按文件名排序）、-config 文件的顺序叠加为一个来源，
+// 同名分类合并，电台 ID 相同（未指定 id 时为同一分类中名称相同）的电台以后加载的文件为准
+type LayeredProvider struct {
+	builtin *JSONProvider // 为 nil 时不包含内置列表
+	dir     string        // 为空时不扫描目录
+	files   []string
+}
+
+// NewLayered 创建叠加的配置文件来源
+func NewLayered(builtin *JSONProvider, dir string, files ...string) *LayeredProvider {
+	return &LayeredProvider{builtin: builtin, dir: dir, files: files}
+}
+
+func (p *LayeredProvider) Name() string {
+	return ConfigName
+}
+
+// Paths 返回当前参与叠加的全部文件，catalog.d 每次调用时重新扫描
+func (p *LayeredProvider) Paths() []string {
+	var paths []string
+	if p.dir != "" {
+		matches, _ := filepath.Glob(filepath.Join(p.dir, "*.json"))
+		paths = append(paths, matches...)
+	}
+	return append(paths, p.files...)
+}
+
+// layers 按加载顺序返回各层
+func (p *LayeredProvider) layers() []*JSONProvider {
+	var layers []*JSONProvider
+	if p.builtin != nil {
+		layers = append(layers, p.builtin)
+	}
+	for _, path := range p.Paths() {
+		layers = append(layers, NewFile(path))
+	}
+	return layers
+}
+
+// Validate 检查每一层，返回全部错误与警告
+func (p *LayeredProvider) Validate() ([]Issue, error) {
+	var issues []Issue
+	for _, layer := range p.layers() {
+		layerIssues, err := layer.Validate()
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, layerIssues...)
+	}
+	return issues, nil
+}
+
+// Categories 返回叠加后的分类，电台的来源标注为其所在文件。任一层有误时返回错误
+func (p *LayeredProvider) Categories() ([]model.Category, error) {
+	type stationKey struct{ category, name string }
+	type slot struct{ category, index int }
+	var merged []model.Category
+	categories := make(map[string]int)
+	byID := make(map[string]slot)
+	byName := make(map[stationKey]slot)
+	removed := make(map[slot]bool)
+	layerOf := make(map[slot]int) // 电台来自第几层，同一层中出现在多个分类的电台都保留
+
+	forget := func(at slot) {
+		old := merged[at.category].RadioList[at.index]
+		if byID[old.StationID()] == at {
+			delete(byID, old.StationID())
+		}
+		key := stationKey{merged[at.category].Name, old.Name}
+		if byName[key] == at {
+			delete(byName, key)
+		}
+	}
+
+	for l, layer := range p.layers() {
+		layerCategories, err := layer.Categories()
+		if err != nil {
+			return nil, err
+		}
+		for _, cat := range layerCategories {
+			i, ok := categories[cat.Name]
+			if !ok {
+				i = len(merged)
+				categories[cat.Name] = i
+				merged = append(merged, model.Category{Name: cat.Name})
+			}
+			for _, radio := range cat.RadioList {
+				radio.Source = layer.Name()
+				key := stationKey{cat.Name, radio.Name}
+				at, ok := byID[radio.StationID()]
+				if !ok && radio.ID == "" {
+					at, ok = byName[key]
+				}
+				if ok && layerOf[at] < l {
+					forget(at)
+					if at.category == i {
+						merged[i].RadioList[at.index] = radio
+						byID[radio.StationID()] = at
+						byName[key] = at
+						layerOf[at] = l
+						continue
+					}
+					removed[at] = true
+				}
+				at = slot{i, len(merged[i].RadioList)}
+				merged[i].RadioList = append(merged[i].RadioList, radio)
+				byID[radio.StationID()] = at
+				byName[key] = at
+				layerOf[at] = l
+			}
+		}
+	}
+	if len(removed) == 0 {
+		return merged, nil
+	}
+
+	// 去掉移到其他分类的电台，因此变空的分类不再显示
+	var result []model.Category
+	for i, cat := range merged {
+		var radios []model.Radio
+		for j, radio := range cat.RadioList {
+			if !removed[slot{i, j}] {
+				radios = append(radios, radio)
+			}
+		}
+		if len(radios) == 0 && len(cat.RadioList) > 0 {
+			continue
+		}
+		cat.RadioList = radios
+		result = append(result, cat)
+	}
+	return result, nil
+}
+
+func (p *LayeredProvider) Stations(category string) ([]model.Radio, error) {
+	categories, err := p.Categories()
+	if err != nil {
+		return nil, err
+	}
+	return stationsIn(categories, category), nil
+}
+
+func (p *LayeredProvider) Search(query string) ([]model.Radio, error) {
+	categories, err := p.Categories()
+	if err != nil {
+		return nil, err
+	}
+	return searchIn(categories, query), nil
+}
+
+func (p *LayeredProvider) ResolveStreamURL(radio model.Radio) (string, error) {
+	return radio.PlayURL, nil
+}
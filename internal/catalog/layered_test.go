@@ -0,0 +1,93 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"FMgo/internal/model"
+)
+
+func writeLayer(t *testing.T, path, data string) string {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLayeredCategories(t *testing.T) {
+	dir := t.TempDir()
+	builtin := NewEmbedded([]byte(`[
+  {"name": "新闻", "radioList": [
+    {"name": "中国之声", "playUrl": "http://example.com/builtin/cnr1"},
+    {"name": "经济之声", "playUrl": "http://example.com/cnr2"}
+  ]},
+  {"name": "音乐", "radioList": [{"name": "音乐之声", "playUrl": "http://example.com/music"}]}
+]`))
+	catalogDir := filepath.Join(dir, "catalog.d")
+	writeLayer(t, filepath.Join(catalogDir, "b.json"), `[{"name": "新闻", "radioList": [
+  {"name": "中国之声", "playUrl": "http://example.com/b/cnr1"}
+]}]`)
+	writeLayer(t, filepath.Join(catalogDir, "a.json"), `[{"name": "新闻", "radioList": [
+  {"name": "中国之声", "playUrl": "http://example.com/a/cnr1"},
+  {"name": "北京新闻", "playUrl": "http://example.com/bj"}
+]}]`)
+	user := writeLayer(t, filepath.Join(dir, "user.json"), `[
+  {"name": "本地", "radioList": [{"name": "城市之声", "playUrl": "http://example.com/city"}]},
+  {"name": "新闻", "radioList": [{"name": "经济之声", "playUrl": "http://example.com/user/cnr2"}]}
+]`)
+
+	radio := func(name, url, source string) model.Radio {
+		return model.Radio{Name: name, PlayURL: url, Source: source}
+	}
+	tests := []struct {
+		name     string
+		provider *LayeredProvider
+		want     []model.Category
+	}{
+		{
+			name:     "builtin only",
+			provider: NewLayered(builtin, ""),
+			want: []model.Category{
+				{Name: "新闻", RadioList: []model.Radio{radio("中国之声", "http://example.com/builtin/cnr1", "内置"), radio("经济之声", "http://example.com/cnr2", "内置")}},
+				{Name: "音乐", RadioList: []model.Radio{radio("音乐之声", "http://example.com/music", "内置")}},
+			},
+		},
+		{
+			name:     "same-name categories merge, later layers win",
+			provider: NewLayered(builtin, catalogDir, user),
+			want: []model.Category{
+				{Name: "新闻", RadioList: []model.Radio{
+					radio("中国之声", "http://example.com/b/cnr1", "b.json"),
+					radio("经济之声", "http://example.com/user/cnr2", "user.json"),
+					radio("北京新闻", "http://example.com/bj", "a.json"),
+				}},
+				{Name: "音乐", RadioList: []model.Radio{radio("音乐之声", "http://example.com/music", "内置")}},
+				{Name: "本地", RadioList: []model.Radio{radio("城市之声", "http://example.com/city", "user.json")}},
+			},
+		},
+		{
+			name:     "without builtin",
+			provider: NewLayered(nil, "", user),
+			want: []model.Category{
+				{Name: "本地", RadioList: []model.Radio{radio("城市之声", "http://example.com/city", "user.json")}},
+				{Name: "新闻", RadioList: []model.Radio{radio("经济之声", "http://example.com/user/cnr2", "user.json")}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		got, err := tt.provider.Categories()
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\n got %+v\nwant %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package catalog
+
+import (
+	"FMgo/internal/db"
+	"FMgo/internal/model"
+)
+
+// LocalName 是用户本地目录的来源名称
+const LocalName = "本地"
+
+// LocalProvider 是保存在数据库中、可由用户编辑的本地电台目录
+type LocalProvider struct {
+	db db.LocalCatalogStore
+}
+
+// NewLocal 创建本地目录来源
+func NewLocal(database db.LocalCatalogStore) *LocalProvider {
+	return &LocalProvider{db: database}
+}
+
+func (p *LocalProvider) Name() string {
+	return LocalName
+}
+
+func (p *LocalProvider) Categories() ([]model.Category, error) {
+	return p.db.GetLocalCatalog()
+}
+
+func (p *LocalProvider) Stations(category string) ([]model.Radio, error) {
+	categories, err := p.db.GetLocalCatalog()
+	if err != nil {
+		return nil, err
+	}
+	return stationsIn(categories, category), nil
+}
+
+func (p *LocalProvider) Search(query string) ([]model.Radio, error) {
+	categories, err := p.db.GetLocalCatalog()
+	if err != nil {
+		return nil, err
+	}
+	return searchIn(categories, query), nil
+}
+
+func (p *LocalProvider) ResolveStreamURL(radio model.Radio) (string, error) {
+	return radio.PlayURL, nil
+}
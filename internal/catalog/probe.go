@@ -0,0 +1,50 @@
+package catalog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// probeTimeout 是检查播放地址的超时时间
+const probeTimeout = 5 * time.Second
+
+// ProbeStreamURL 快速检查播放地址是否可用：请求成功，且内容为音频流或 M3U/PLS 播放列表
+func ProbeStreamURL(rawURL string) error {
+	if err := checkStreamURL(rawURL); err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: probeTimeout}
+	resp, err := client.Get(rawURL)
+	if err != nil {
+		return fmt.Errorf("连接失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	// 直播流不会结束，只读取开头用于判断内容
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(resp.Body, head)
+	head = bytes.TrimSpace(head[:n])
+	if len(head) == 0 {
+		return fmt.Errorf("没有收到数据")
+	}
+
+	contentType := strings.ToLower(resp.Header.Get("Content-Type"))
+	switch {
+	case strings.HasPrefix(contentType, "audio/"),
+		strings.Contains(contentType, "mpegurl"),
+		strings.Contains(contentType, "ogg"),
+		strings.Contains(contentType, "octet-stream"),
+		bytes.HasPrefix(head, []byte("#EXTM3U")),
+		bytes.HasPrefix(bytes.ToLower(head), []byte("[playlist]")):
+		return nil
+	}
+	return fmt.Errorf("内容类型 %q 不是音频流或播放列表", contentType)
+}
@@ -0,0 +1,235 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"FMgo/internal/db"
+	"FMgo/internal/logger"
+	"FMgo/internal/model"
+)
+
+const (
+	// RadioBrowserName 是 Radio Browser 来源名称
+	RadioBrowserName = "RadioBrowser"
+	// DefaultRadioBrowserURL 是 Radio Browser API 的默认地址
+	DefaultRadioBrowserURL = "https://de1.api.radio-browser.info"
+
+	radioBrowserUserAgent = "FMgo/0.1.0"
+	radioBrowserLimit     = 100
+)
+
+// RadioBrowserProvider 是社区电台目录 Radio Browser 的来源，结果缓存在数据库中供离线浏览
+type RadioBrowserProvider struct {
+	baseURL string
+	db      db.DirectoryCacheStore
+	client  *http.Client
+}
+
+// NewRadioBrowser 创建 Radio Browser 来源，baseURL 为空时使用默认地址
+func NewRadioBrowser(baseURL string, database db.DirectoryCacheStore) *RadioBrowserProvider {
+	if baseURL == "" {
+		baseURL = DefaultRadioBrowserURL
+	}
+	return &RadioBrowserProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		db:      database,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *RadioBrowserProvider) Name() string {
+	return RadioBrowserName
+}
+
+// Categories 不向主列表贡献分类，在线目录通过发现视图浏览
+func (p *RadioBrowserProvider) Categories() ([]model.Category, error) {
+	return nil, nil
+}
+
+// Stations 按标签列出电台
+func (p *RadioBrowserProvider) Stations(category string) ([]model.Radio, error) {
+	stations, err := p.SearchStations(model.DirectoryQuery{Tag: category})
+	if err != nil {
+		return nil, err
+	}
+	return toRadios(stations), nil
+}
+
+func (p *RadioBrowserProvider) Search(query string) ([]model.Radio, error) {
+	stations, err := p.SearchStations(model.DirectoryQuery{Name: query})
+	if err != nil {
+		return nil, err
+	}
+	return toRadios(stations), nil
+}
+
+// Lookup 按播放地址在缓存的目录中查找电台，供收藏与历史记录解析来自发现视图的电台
+func (p *RadioBrowserProvider) Lookup(playURL string) (model.Radio, bool) {
+	station, err := p.db.GetDirectoryStationByURL(playURL)
+	if err != nil {
+		logger.Error("查找 Radio Browser 电台失败: %v", err)
+	}
+	if station == nil {
+		return model.Radio{}, false
+	}
+	radio := station.Radio()
+	radio.Source = RadioBrowserName
+	return radio, true
+}
+
+// ResolveStreamURL 通过 /json/url 接口上报一次点击并获取播放地址，失败时使用已知地址
+func (p *RadioBrowserProvider) ResolveStreamURL(radio model.Radio) (string, error) {
+	station, err := p.db.GetDirectoryStationByURL(radio.PlayURL)
+	if err != nil || station == nil {
+		return radio.PlayURL, nil
+	}
+
+	var result struct {
+		OK  bool   `json:"ok"`
+		URL string `json:"url"`
+	}
+	if err := p.get("/json/url/"+url.PathEscape(station.UUID), nil, &result); err != nil {
+		logger.Error("上报电台点击失败: %v", err)
+		return radio.PlayURL, nil
+	}
+	if result.OK && result.URL != "" {
+		return result.URL, nil
+	}
+	return radio.PlayURL, nil
+}
+
+// SearchStations 按条件搜索电台，网络不可用时回退到本地缓存
+func (p *RadioBrowserProvider) SearchStations(q model.DirectoryQuery) ([]model.DirectoryStation, error) {
+	params := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			params.Set(key, value)
+		}
+	}
+	set("name", q.Name)
+	set("country", q.Country)
+	set("state", q.Region)
+	set("language", q.Language)
+	set("tag", q.Tag)
+	set("codec", q.Codec)
+	if q.BitrateMin > 0 {
+		params.Set("bitrateMin", strconv.Itoa(q.BitrateMin))
+	}
+	params.Set("limit", strconv.Itoa(limitOrDefault(q.Limit)))
+	params.Set("hidebroken", "true")
+	params.Set("order", "votes")
+	params.Set("reverse", "true")
+
+	return p.fetch("/json/stations/search", params, q, "votes")
+}
+
+// TopVoted 返回投票最多的电台
+func (p *RadioBrowserProvider) TopVoted(limit int) ([]model.DirectoryStation, error) {
+	limit = limitOrDefault(limit)
+	return p.fetch("/json/stations/topvote/"+strconv.Itoa(limit), nil, model.DirectoryQuery{Limit: limit}, "votes")
+}
+
+// TopClicked 返回点击最多的电台
+func (p *RadioBrowserProvider) TopClicked(limit int) ([]model.DirectoryStation, error) {
+	limit = limitOrDefault(limit)
+	return p.fetch("/json/stations/topclick/"+strconv.Itoa(limit), nil, model.DirectoryQuery{Limit: limit}, "click_count")
+}
+
+// fetch 请求 API 并缓存结果，失败时从缓存中按相同条件查询
+func (p *RadioBrowserProvider) fetch(path string, params url.Values, q model.DirectoryQuery, orderBy string) ([]model.DirectoryStation, error) {
+	var stations []model.DirectoryStation
+	err := p.get(path, params, &stations)
+	if err == nil {
+		if err := p.db.CacheDirectoryStations(stations); err != nil {
+			logger.Error("缓存 Radio Browser 结果失败: %v", err)
+		}
+		return stations, nil
+	}
+
+	logger.Error("请求 Radio Browser 失败，使用本地缓存: %v", err)
+	cached, cacheErr := p.db.SearchDirectoryCache(q, orderBy)
+	if cacheErr != nil || len(cached) == 0 {
+		return nil, err
+	}
+	return cached, nil
+}
+
+func (p *RadioBrowserProvider) get(path string, params url.Values, v interface{}) error {
+	endpoint := p.baseURL + path
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", radioBrowserUserAgent)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求 %s 失败: %v", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("请求 %s 失败: HTTP %d", path, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("解析 %s 响应失败: %v", path, err)
+	}
+	return nil
+}
+
+// ParseDirectoryQuery 解析搜索输入，如 "jazz country:Germany region:Bavaria codec:mp3 bitrate:128"
+func ParseDirectoryQuery(input string) model.DirectoryQuery {
+	var q model.DirectoryQuery
+	var names []string
+	for _, field := range strings.Fields(input) {
+		key, value, ok := strings.Cut(field, ":")
+		if !ok {
+			names = append(names, field)
+			continue
+		}
+		switch strings.ToLower(key) {
+		case "country":
+			q.Country = value
+		case "region", "province", "city", "state":
+			q.Region = value
+		case "lang", "language":
+			q.Language = value
+		case "tag":
+			q.Tag = value
+		case "codec":
+			q.Codec = value
+		case "bitrate":
+			q.BitrateMin, _ = strconv.Atoi(value)
+		default:
+			names = append(names, field)
+		}
+	}
+	q.Name = strings.Join(names, " ")
+	return q
+}
+
+// toRadios 将目录电台转换为可播放的电台
+func toRadios(stations []model.DirectoryStation) []model.Radio {
+	radios := make([]model.Radio, 0, len(stations))
+	for _, s := range stations {
+		radio := s.Radio()
+		radio.Source = RadioBrowserName
+		radios = append(radios, radio)
+	}
+	return radios
+}
+
+func limitOrDefault(limit int) int {
+	if limit <= 0 {
+		return radioBrowserLimit
+	}
+	return limit
+}
@@ -0,0 +1,341 @@
+package catalog
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"FMgo/internal/logger"
+	"FMgo/internal/model"
+)
+
+// DefaultRemoteRefresh 是远程目录默认的刷新间隔
+const DefaultRemoteRefresh = time.Hour
+
+// RemoteProvider 从 HTTP(S) 地址订阅 JSON 格式（[]model.Category）的电台目录。
+// 获取结果连同 ETag/Last-Modified 缓存在本地，有缓存时立即使用并在后台发送条件请求重新验证
+type RemoteProvider struct {
+	url      string
+	cacheDir string
+	client   *http.Client
+
+	fetchMu sync.Mutex // 串行化网络请求，请求期间不持有 mu
+
+	mu             sync.Mutex
+	verifyChecksum bool              // 校验 <url>.sha256 中发布的 SHA-256
+	publicKey      ed25519.PublicKey // 非空时校验 <url>.sig 中的 ed25519 签名
+	categories     []model.Category
+	etag           string
+	lastModified   string
+	fetchedAt      time.Time
+	onUpdate       func()
+	missedUpdate   bool // 后台更新时尚未设置 onUpdate
+}
+
+// remoteCache 是远程目录在本地缓存的元数据
+type remoteCache struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag"`
+	LastModified string    `json:"last_modified"`
+	FetchedAt    time.Time `json:"fetched_at"`
+}
+
+// NewRemote 创建远程目录来源，cacheDir 为空时不缓存
+func NewRemote(rawURL, cacheDir string) *RemoteProvider {
+	return &RemoteProvider{
+		url:      rawURL,
+		cacheDir: cacheDir,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// SetChecksumVerification 设置是否校验与目录一同发布的 <url>.sha256
+func (p *RemoteProvider) SetChecksumVerification(enabled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.verifyChecksum = enabled
+}
+
+// SetPublicKey 设置用于校验 <url>.sig 签名的 ed25519 公钥（base64 编码），为空时不校验
+func (p *RemoteProvider) SetPublicKey(encoded string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if encoded == "" {
+		p.publicKey = nil
+		return nil
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("无效的 ed25519 公钥")
+	}
+	p.publicKey = key
+	return nil
+}
+
+func (p *RemoteProvider) Name() string {
+	if u, err := url.Parse(p.url); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return p.url
+}
+
+// OnUpdate 设置后台重新验证发现目录有变化时的回调
+func (p *RemoteProvider) OnUpdate(fn func()) {
+	p.mu.Lock()
+	p.onUpdate = fn
+	missed := p.missedUpdate
+	p.missedUpdate = false
+	p.mu.Unlock()
+	if missed && fn != nil {
+		go fn()
+	}
+}
+
+// Categories 返回远程目录。首次调用时立即返回本地缓存并在后台重新验证，没有缓存时从网络获取
+func (p *RemoteProvider) Categories() ([]model.Category, error) {
+	p.mu.Lock()
+	if p.categories == nil {
+		if err := p.loadCacheLocked(); err == nil {
+			logger.Info("使用远程目录缓存: %s (获取于 %s)", p.url, p.fetchedAt.Format(time.RFC3339))
+			go p.revalidate()
+		}
+	}
+	categories := p.categories
+	p.mu.Unlock()
+	if categories != nil {
+		return categories, nil
+	}
+
+	if _, err := p.Refresh(); err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.categories, nil
+}
+
+// revalidate 在后台对缓存的目录发送条件请求，有变化时调用 onUpdate
+func (p *RemoteProvider) revalidate() {
+	changed, err := p.Refresh()
+	if err != nil {
+		logger.Error("重新验证远程目录失败，继续使用缓存: %s: %v", p.url, err)
+		return
+	}
+	if !changed {
+		return
+	}
+	p.mu.Lock()
+	fn := p.onUpdate
+	p.missedUpdate = fn == nil
+	p.mu.Unlock()
+	if fn != nil {
+		fn()
+	}
+}
+
+// FetchedAt 返回上次成功获取（或确认未变化）的时间
+func (p *RemoteProvider) FetchedAt() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.fetchedAt
+}
+
+// Refresh 发送条件请求刷新远程目录，返回目录是否有变化。失败时保留当前目录
+func (p *RemoteProvider) Refresh() (bool, error) {
+	p.fetchMu.Lock()
+	defer p.fetchMu.Unlock()
+
+	p.mu.Lock()
+	cached := p.categories != nil
+	etag, lastModified := p.etag, p.lastModified
+	verifyChecksum, publicKey := p.verifyChecksum, p.publicKey
+	p.mu.Unlock()
+
+	req, err := http.NewRequest(http.MethodGet, p.url, nil)
+	if err != nil {
+		return false, fmt.Errorf("获取远程目录失败: %v", err)
+	}
+	if cached {
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("获取远程目录失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.fetchedAt = time.Now()
+		p.saveMetaLocked()
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("获取远程目录失败: HTTP %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, fmt.Errorf("获取远程目录失败: %v", err)
+	}
+	if err := p.verify(body, verifyChecksum, publicKey); err != nil {
+		return false, err
+	}
+	if issues := ValidateCatalog(p.url, body); HasErrors(issues) {
+		return false, &ValidationError{Issues: errorsOnly(issues)}
+	}
+	var categories []model.Category
+	if err := json.Unmarshal(body, &categories); err != nil {
+		return false, fmt.Errorf("解析远程目录失败: %v", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.categories = categories
+	p.etag = resp.Header.Get("ETag")
+	p.lastModified = resp.Header.Get("Last-Modified")
+	p.fetchedAt = time.Now()
+	if err := p.saveCacheLocked(body); err != nil {
+		logger.Error("保存远程目录缓存失败: %v", err)
+	}
+	return true, nil
+}
+
+// verify 按设置校验目录内容的 SHA-256 与签名
+func (p *RemoteProvider) verify(body []byte, verifyChecksum bool, publicKey ed25519.PublicKey) error {
+	if verifyChecksum {
+		published, err := p.fetchCompanion(".sha256")
+		if err != nil {
+			return err
+		}
+		fields := strings.Fields(string(published))
+		sum := sha256.Sum256(body)
+		if len(fields) == 0 || !strings.EqualFold(fields[0], hex.EncodeToString(sum[:])) {
+			return fmt.Errorf("远程目录 SHA-256 校验失败")
+		}
+	}
+	if publicKey != nil {
+		published, err := p.fetchCompanion(".sig")
+		if err != nil {
+			return err
+		}
+		sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(published)))
+		if err != nil || !ed25519.Verify(publicKey, body, sig) {
+			return fmt.Errorf("远程目录签名校验失败")
+		}
+	}
+	return nil
+}
+
+// fetchCompanion 获取与目录一同发布的文件（如 catalog.json.sha256）
+func (p *RemoteProvider) fetchCompanion(suffix string) ([]byte, error) {
+	resp, err := p.client.Get(p.url + suffix)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 失败: %v", p.url+suffix, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("获取 %s 失败: HTTP %d", p.url+suffix, resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 4096))
+}
+
+// cachePath 返回缓存文件的路径前缀，按地址的哈希区分不同的订阅
+func (p *RemoteProvider) cachePath() string {
+	sum := sha256.Sum256([]byte(p.url))
+	return filepath.Join(p.cacheDir, hex.EncodeToString(sum[:8]))
+}
+
+func (p *RemoteProvider) loadCacheLocked() error {
+	if p.cacheDir == "" {
+		return fmt.Errorf("未启用缓存")
+	}
+	data, err := os.ReadFile(p.cachePath() + ".json")
+	if err != nil {
+		return err
+	}
+	if issues := ValidateCatalog(p.url, data); HasErrors(issues) {
+		return &ValidationError{Issues: errorsOnly(issues)}
+	}
+	var categories []model.Category
+	if err := json.Unmarshal(data, &categories); err != nil {
+		return err
+	}
+	var meta remoteCache
+	if metaData, err := os.ReadFile(p.cachePath() + ".meta.json"); err == nil {
+		json.Unmarshal(metaData, &meta)
+	}
+
+	p.categories = categories
+	p.etag = meta.ETag
+	p.lastModified = meta.LastModified
+	p.fetchedAt = meta.FetchedAt
+	return nil
+}
+
+func (p *RemoteProvider) saveCacheLocked(body []byte) error {
+	if p.cacheDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(p.cacheDir, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(p.cachePath()+".json", body, 0644); err != nil {
+		return err
+	}
+	return p.saveMetaLocked()
+}
+
+func (p *RemoteProvider) saveMetaLocked() error {
+	if p.cacheDir == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(remoteCache{
+		URL:          p.url,
+		ETag:         p.etag,
+		LastModified: p.lastModified,
+		FetchedAt:    p.fetchedAt,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p.cachePath()+".meta.json", data, 0644)
+}
+
+func (p *RemoteProvider) Stations(category string) ([]model.Radio, error) {
+	categories, err := p.Categories()
+	if err != nil {
+		return nil, err
+	}
+	return stationsIn(categories, category), nil
+}
+
+func (p *RemoteProvider) Search(query string) ([]model.Radio, error) {
+	categories, err := p.Categories()
+	if err != nil {
+		return nil, err
+	}
+	return searchIn(categories, query), nil
+}
+
+func (p *RemoteProvider) ResolveStreamURL(radio model.Radio) (string, error) {
+	return radio.PlayURL, nil
+}
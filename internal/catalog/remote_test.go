@@ -0,0 +1,67 @@
+package catalog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRemoteServesCacheWhileRevalidating(t *testing.T) {
+	cached := `[{"name": "新闻", "radioList": [{"name": "中国之声", "playUrl": "http://example.com/cnr1"}]}]`
+	updated := `[{"name": "新闻", "radioList": [{"name": "经济之声", "playUrl": "http://example.com/cnr2"}]}]`
+
+	release := make(chan struct{})
+	requests := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- r.Header.Get("If-None-Match")
+		<-release
+		w.Header().Set("ETag", `"v2"`)
+		w.Write([]byte(updated))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	seed := NewRemote(server.URL, dir)
+	seed.etag = `"v1"`
+	if err := seed.saveCacheLocked([]byte(cached)); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewRemote(server.URL, dir)
+	updates := make(chan struct{}, 1)
+	p.OnUpdate(func() { updates <- struct{}{} })
+
+	categories, err := p.Categories()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if categories[0].RadioList[0].Name != "中国之声" {
+		t.Fatalf("Categories = %+v, want cached catalog", categories)
+	}
+	select {
+	case etag := <-requests:
+		if etag != `"v1"` {
+			t.Errorf("If-None-Match = %q, want %q", etag, `"v1"`)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("cached catalog was not revalidated")
+	}
+
+	// 重新验证进行中时仍然可以读取目录
+	if _, err := p.Categories(); err != nil {
+		t.Fatal(err)
+	}
+	p.FetchedAt()
+
+	close(release)
+	select {
+	case <-updates:
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnUpdate was not called")
+	}
+	categories, _ = p.Categories()
+	if categories[0].RadioList[0].Name != "经济之声" {
+		t.Errorf("Categories after revalidation = %+v", categories)
+	}
+}
@@ -0,0 +1,378 @@
+package catalog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// Issue 是电台配置文件中的一个问题，Warning 为 false 时文件无法使用
+type Issue struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+	Warning bool
+}
+
+func (i Issue) String() string {
+	level := "error"
+	if i.Warning {
+		level = "warning"
+	}
+	return fmt.Sprintf("%s:%d:%d: %s: %s", i.File, i.Line, i.Column, level, i.Message)
+}
+
+// ValidationError 包含电台配置文件中的全部错误
+type ValidationError struct {
+	Issues []Issue
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		lines[i] = issue.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// HasErrors 返回 issues 中是否有错误（而非警告）
+func HasErrors(issues []Issue) bool {
+	for _, issue := range issues {
+		if !issue.Warning {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonNode 是带有源文件偏移量的 JSON 值，用于定位问题所在的行列
+type jsonNode struct {
+	offset int64
+	kind   byte // '{' 对象, '[' 数组, '"' 字符串, '#' 数字, 其他值为 0
+	str    string
+	keys   []jsonKey
+	items  []*jsonNode
+}
+
+type jsonKey struct {
+	name   string
+	offset int64
+	value  *jsonNode
+}
+
+// field 返回对象中的字段，与 encoding/json 一样优先精确匹配，其次忽略大小写匹配
+func (n *jsonNode) field(name string) *jsonNode {
+	var folded *jsonNode
+	for _, k := range n.keys {
+		if k.name == name {
+			return k.value
+		}
+		if folded == nil && strings.EqualFold(k.name, name) {
+			folded = k.value
+		}
+	}
+	return folded
+}
+
+type jsonParser struct {
+	data []byte
+	dec  *json.Decoder
+}
+
+// start 返回下一个值在源文件中的起始偏移量
+func (p *jsonParser) start() int64 {
+	off := p.dec.InputOffset()
+	for off < int64(len(p.data)) && strings.IndexByte(" \t\r\n,:", p.data[off]) >= 0 {
+		off++
+	}
+	return off
+}
+
+func (p *jsonParser) value() (*jsonNode, error) {
+	n := &jsonNode{offset: p.start()}
+	tok, err := p.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		n.kind = byte(t)
+		for p.dec.More() {
+			if t == '{' {
+				off := p.start()
+				key, err := p.dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				v, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				n.keys = append(n.keys, jsonKey{name: key.(string), offset: off, value: v})
+			} else {
+				v, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				n.items = append(n.items, v)
+			}
+		}
+		if _, err := p.dec.Token(); err != nil {
+			return nil, err
+		}
+	case string:
+		n.kind = '"'
+		n.str = t
+	case float64:
+		n.kind = '#'
+	}
+	return n, nil
+}
+
+// validator 收集问题并把偏移量换算为行列
+type validator struct {
+	file   string
+	data   []byte
+	issues []Issue
+}
+
+func (v *validator) position(offset int64) (line, col int) {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > int64(len(v.data)) {
+		offset = int64(len(v.data))
+	}
+	before := v.data[:offset]
+	line = bytes.Count(before, []byte("\n")) + 1
+	lineStart := bytes.LastIndexByte(before, '\n') + 1
+	return line, utf8.RuneCount(before[lineStart:]) + 1
+}
+
+func (v *validator) report(offset int64, warning bool, format string, args ...interface{}) {
+	line, col := v.position(offset)
+	v.issues = append(v.issues, Issue{
+		File:    v.file,
+		Line:    line,
+		Column:  col,
+		Message: fmt.Sprintf(format, args...),
+		Warning: warning,
+	})
+}
+
+func (v *validator) pos(offset int64) string {
+	line, col := v.position(offset)
+	return fmt.Sprintf("%d:%d", line, col)
+}
+
+// ValidateCatalog 检查电台配置文件（[]model.Category 格式）：JSON 语法与类型、
+// 缺失或未知的字段、空的或无效的播放地址、分类内与分类间重复的电台名称。
+// 未知字段、分类间重复与重复的分类名称为警告，其余为错误
+func ValidateCatalog(file string, data []byte) []Issue {
+	v := &validator{file: file, data: data}
+	p := &jsonParser{data: data, dec: json.NewDecoder(bytes.NewReader(data))}
+
+	root, err := p.value()
+	if err != nil {
+		v.reportSyntax(p, err)
+		return v.issues
+	}
+	if _, err := p.dec.Token(); err != io.EOF {
+		v.report(p.start(), false, "数组结束后有多余的内容")
+	}
+	if root.kind != '[' {
+		v.report(root.offset, false, "顶层应为分类数组")
+		return v.issues
+	}
+
+	type seen struct {
+		category string
+		offset   int64
+	}
+	categories := make(map[string]int64)
+	stations := make(map[string]seen)
+
+	for _, cat := range root.items {
+		if cat.kind != '{' {
+			v.report(cat.offset, false, "分类应为对象")
+			continue
+		}
+		v.checkKeys(cat, "分类", "name", "radioList")
+
+		catName := v.requireString(cat, "name", "分类")
+		if catName != "" {
+			if first, ok := categories[catName]; ok {
+				v.report(cat.field("name").offset, true, "分类 %q 重复（首次出现于 %s），电台将合并显示", catName, v.pos(first))
+			} else {
+				categories[catName] = cat.field("name").offset
+			}
+		}
+
+		list := cat.field("radioList")
+		if list == nil {
+			v.report(cat.offset, false, "分类 %q 缺少字段 \"radioList\"", catName)
+			continue
+		}
+		if list.kind != '[' {
+			v.report(list.offset, false, "\"radioList\" 应为数组")
+			continue
+		}
+
+		inCategory := make(map[string]int64)
+		for _, radio := range list.items {
+			v.validateRadio(radio)
+			name := radio.field("name")
+			if radio.kind != '{' || name == nil || name.kind != '"' || name.str == "" {
+				continue
+			}
+			if first, ok := inCategory[name.str]; ok {
+				v.report(name.offset, false, "电台 %q 在分类 %q 中重复（首次出现于 %s）", name.str, catName, v.pos(first))
+				continue
+			}
+			inCategory[name.str] = name.offset
+			if first, ok := stations[name.str]; ok && first.category != catName {
+				v.report(name.offset, true, "电台 %q 同时出现在分类 %q（%s）与 %q 中", name.str, first.category, v.pos(first.offset), catName)
+				continue
+			}
+			stations[name.str] = seen{category: catName, offset: name.offset}
+		}
+	}
+	return v.issues
+}
+
+func (v *validator) validateRadio(radio *jsonNode) {
+	if radio.kind != '{' {
+		v.report(radio.offset, false, "电台应为对象")
+		return
+	}
+	v.checkKeys(radio, "电台", radioFields...)
+	v.requireString(radio, "name", "电台")
+	v.checkMetadata(radio)
+	playURL := v.requireString(radio, "playUrl", "电台")
+	if playURL == "" {
+		return
+	}
+	if err := checkStreamURL(playURL); err != nil {
+		v.report(radio.field("playUrl").offset, false, "无效的播放地址 %q: %v", playURL, err)
+	}
+}
+
+// radioFields 是电台支持的字段，除 name 与 playUrl 外均为可选
+var radioFields = []string{
+	"id", "name", "playUrl", "description", "homepage", "country", "province", "city",
+	"language", "tags", "codec", "bitrate", "logo",
+}
+
+// checkMetadata 检查电台可选字段的类型：tags 为字符串数组，bitrate 为数字，其余为字符串；
+// homepage 与 logo 不是有效地址时给出警告
+func (v *validator) checkMetadata(radio *jsonNode) {
+	for _, k := range radio.keys {
+		if indexOf(radioFields, k.name) < 0 {
+			continue // 未知字段已在 checkKeys 中报告
+		}
+		value := k.value
+		switch k.name {
+		case "name", "playUrl":
+		case "tags":
+			if value.kind != '[' {
+				v.report(value.offset, false, "\"tags\" 应为字符串数组")
+				continue
+			}
+			for _, tag := range value.items {
+				if tag.kind != '"' {
+					v.report(tag.offset, false, "\"tags\" 中的元素应为字符串")
+				}
+			}
+		case "bitrate":
+			if value.kind != '#' {
+				v.report(value.offset, false, "\"bitrate\" 应为数字(kbps)")
+			}
+		default:
+			if value.kind != '"' {
+				v.report(value.offset, false, "%q 应为字符串", k.name)
+				continue
+			}
+			if (k.name == "homepage" || k.name == "logo") && value.str != "" {
+				if err := checkStreamURL(value.str); err != nil {
+					v.report(value.offset, true, "无效的 %s 地址 %q: %v", k.name, value.str, err)
+				}
+			}
+		}
+	}
+}
+
+// checkKeys 报告对象中的未知字段，以及大小写与 known 不一致的字段
+func (v *validator) checkKeys(node *jsonNode, what string, known ...string) {
+next:
+	for _, k := range node.keys {
+		for _, name := range known {
+			if k.name == name {
+				continue next
+			}
+			if strings.EqualFold(k.name, name) {
+				v.report(k.offset, true, "字段 %q 应为 %q", k.name, name)
+				continue next
+			}
+		}
+		v.report(k.offset, true, "%s中的未知字段 %q", what, k.name)
+	}
+}
+
+// requireString 检查 node 中的 field 为非空字符串并返回其值
+func (v *validator) requireString(node *jsonNode, field, what string) string {
+	value := node.field(field)
+	switch {
+	case value == nil:
+		v.report(node.offset, false, "%s缺少字段 %q", what, field)
+	case value.kind != '"':
+		v.report(value.offset, false, "%q 应为字符串", field)
+	case strings.TrimSpace(value.str) == "":
+		v.report(value.offset, false, "%s的 %q 不能为空", what, field)
+	default:
+		return value.str
+	}
+	return ""
+}
+
+// reportSyntax 报告 JSON 语法错误的位置
+func (v *validator) reportSyntax(p *jsonParser, err error) {
+	var syntax *json.SyntaxError
+	switch {
+	case errors.As(err, &syntax):
+		v.report(syntax.Offset-1, false, "JSON 语法错误: %v", err)
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		v.report(int64(len(v.data)), false, "JSON 不完整，文件意外结束")
+	default:
+		v.report(p.dec.InputOffset(), false, "JSON 解析失败: %v", err)
+	}
+}
+
+// checkStreamURL 检查播放地址是否为有效的 http(s) 地址
+func checkStreamURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return errors.Unwrap(err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("协议应为 http 或 https")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("缺少主机名")
+	}
+	return nil
+}
+
+func indexOf(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
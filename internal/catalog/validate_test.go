@@ -0,0 +1,74 @@
+package catalog
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestValidateCatalog(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []Issue // 只比较 Line、Column、Warning 与 Message 中的关键字
+	}{
+		{
+			name: "syntax error",
+			data: "[\n  {\"name\": \"新闻\", \"radioList\": [}\n]",
+			want: []Issue{{Line: 2, Column: 32, Message: "JSON 语法错误"}},
+		},
+		{
+			name: "duplicate in category",
+			data: `[{"name": "新闻", "radioList": [
+  {"name": "中国之声", "playUrl": "http://example.com/a"},
+  {"name": "中国之声", "playUrl": "http://example.com/b"}
+]}]`,
+			want: []Issue{{Line: 3, Column: 12, Message: "首次出现于 2:12"}},
+		},
+		{
+			name: "duplicate across categories",
+			data: `[
+  {"name": "新闻", "radioList": [{"name": "中国之声", "playUrl": "http://example.com/a"}]},
+  {"name": "精选", "radioList": [{"name": "中国之声", "playUrl": "http://example.com/a"}]}
+]`,
+			want: []Issue{{Line: 3, Column: 41, Message: "同时出现在分类", Warning: true}},
+		},
+		{
+			name: "unknown field",
+			data: `[{"name": "新闻", "radioList": [{"name": "中国之声", "playUrl": "http://example.com/a", "website": "x"}]}]`,
+			want: []Issue{{Line: 1, Column: 83, Message: `未知字段 "website"`, Warning: true}},
+		},
+		{
+			name: "invalid url",
+			data: `[{"name": "新闻", "radioList": [{"name": "中国之声", "playUrl": "ftp://example.com/a"}]}]`,
+			want: []Issue{{Line: 1, Column: 59, Message: "无效的播放地址"}},
+		},
+	}
+	for _, tt := range tests {
+		issues := ValidateCatalog("radio.json", []byte(tt.data))
+		if len(issues) != len(tt.want) {
+			t.Errorf("%s: issues = %v, want %d", tt.name, issues, len(tt.want))
+			continue
+		}
+		for i, want := range tt.want {
+			got := issues[i]
+			if got.File != "radio.json" || got.Line != want.Line || got.Column != want.Column ||
+				got.Warning != want.Warning || !strings.Contains(got.Message, want.Message) {
+				t.Errorf("%s: issue = %v, want %d:%d %q (warning %v)", tt.name, got, want.Line, want.Column, want.Message, want.Warning)
+			}
+		}
+		if HasErrors(issues) == tt.want[0].Warning {
+			t.Errorf("%s: HasErrors = %v", tt.name, HasErrors(issues))
+		}
+	}
+}
+
+func TestValidateBundledCatalog(t *testing.T) {
+	data, err := os.ReadFile("../../radio.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issues := ValidateCatalog("radio.json", data); len(issues) != 0 {
+		t.Errorf("radio.json issues: %v", issues)
+	}
+}
@@ -0,0 +1,69 @@
+package catalog
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"FMgo/internal/model"
+)
+
+// WatchFiles 每隔 interval 检查一次 paths 返回的文件（列表及各文件的修改时间与大小），
+// 发生变化时调用 changed
+func WatchFiles(paths func() []string, interval time.Duration, changed func()) {
+	fingerprint := func() string {
+		var b strings.Builder
+		for _, path := range paths() {
+			info, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(&b, "%s|%d|%d\n", path, info.ModTime().UnixNano(), info.Size())
+		}
+		return b.String()
+	}
+
+	last := fingerprint()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		current := fingerprint()
+		if current == last {
+			continue
+		}
+		last = current
+		changed()
+	}
+}
+
+// DiffCategories 比较两份目录，返回新增与移除的电台名称（按分类与播放地址判断）
+func DiffCategories(old, new []model.Category) (added, removed []string) {
+	type key struct{ category, name, url string }
+	keys := func(categories []model.Category) map[key]bool {
+		m := make(map[key]bool)
+		for _, cat := range categories {
+			for _, radio := range cat.RadioList {
+				m[key{cat.Name, radio.Name, radio.PlayURL}] = true
+			}
+		}
+		return m
+	}
+	before, after := keys(old), keys(new)
+
+	for _, cat := range new {
+		for _, radio := range cat.RadioList {
+			if !before[key{cat.Name, radio.Name, radio.PlayURL}] {
+				added = append(added, radio.Name)
+			}
+		}
+	}
+	for _, cat := range old {
+		for _, radio := range cat.RadioList {
+			if !after[key{cat.Name, radio.Name, radio.PlayURL}] {
+				removed = append(removed, radio.Name)
+			}
+		}
+	}
+	return added, removed
+}
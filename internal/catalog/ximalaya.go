@@ -0,0 +1,237 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"FMgo/internal/db"
+	"FMgo/internal/model"
+)
+
+const (
+	// XimalayaSource 是喜马拉雅电台在本地目录中的来源标识
+	XimalayaSource = "ximalaya"
+	// DefaultXimalayaURL 是喜马拉雅直播电台接口的默认地址
+	DefaultXimalayaURL = "https://live.ximalaya.com"
+
+	ximalayaPageSize = 100
+	// ximalayaNational 是国家台所在的分类与省份名称
+	ximalayaNational = "国家台"
+)
+
+// XimalayaClient 是喜马拉雅直播电台目录的客户端
+type XimalayaClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewXimalaya 创建喜马拉雅客户端，baseURL 为空时使用默认地址
+func NewXimalaya(baseURL string) *XimalayaClient {
+	if baseURL == "" {
+		baseURL = DefaultXimalayaURL
+	}
+	return &XimalayaClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+type ximalayaProvince struct {
+	Code int    `json:"provinceCode"`
+	Name string `json:"provinceName"`
+}
+
+type ximalayaCategory struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type ximalayaRadio struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	CoverLarge  string `json:"coverLarge"`
+	CoverSmall  string `json:"coverSmall"`
+	ProgramName string `json:"programName"`
+	PlayURL     struct {
+		AAC64 string `json:"aac64"`
+		AAC24 string `json:"aac24"`
+		TS64  string `json:"ts64"`
+		TS24  string `json:"ts24"`
+	} `json:"playUrl"`
+}
+
+// streamURL 返回优先的播放地址
+func (r ximalayaRadio) streamURL() string {
+	for _, u := range []string{r.PlayURL.AAC64, r.PlayURL.TS64, r.PlayURL.AAC24, r.PlayURL.TS24} {
+		if u != "" {
+			return u
+		}
+	}
+	return ""
+}
+
+func (r ximalayaRadio) logo() string {
+	if r.CoverLarge != "" {
+		return r.CoverLarge
+	}
+	return r.CoverSmall
+}
+
+// FetchDirectory 拉取完整的直播电台目录：国家台、各省市台以及各分类
+func (c *XimalayaClient) FetchDirectory() ([]model.ExternalStation, error) {
+	stations := make(map[int]*model.ExternalStation)
+	var order []int
+	add := func(r ximalayaRadio, province, category string) {
+		s, ok := stations[r.ID]
+		if !ok {
+			s = &model.ExternalStation{
+				ExternalID: strconv.Itoa(r.ID),
+				Name:       strings.TrimSpace(r.Name),
+				PlayURL:    r.streamURL(),
+				Logo:       r.logo(),
+				Program:    r.ProgramName,
+			}
+			stations[r.ID] = s
+			order = append(order, r.ID)
+		}
+		if province != "" && s.Province == "" {
+			s.Province = province
+		}
+		if category != "" && s.Category == "" {
+			s.Category = category
+		}
+	}
+
+	national, err := c.radios("/live-web/v2/radio/national", nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range national {
+		add(r, ximalayaNational, "")
+	}
+
+	var provinces []ximalayaProvince
+	if err := c.get("/live-web/v1/getProvinceList", nil, &provinces); err != nil {
+		return nil, err
+	}
+	for _, p := range provinces {
+		radios, err := c.radios("/live-web/v2/radio/province", url.Values{"provinceCode": {strconv.Itoa(p.Code)}})
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range radios {
+			add(r, p.Name, "")
+		}
+	}
+
+	var categories []ximalayaCategory
+	if err := c.get("/live-web/v1/getRadioCategoryList", nil, &categories); err != nil {
+		return nil, err
+	}
+	for _, cat := range categories {
+		radios, err := c.radios("/live-web/v2/radio/category", url.Values{"categoryId": {strconv.Itoa(cat.ID)}})
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range radios {
+			add(r, "", cat.Name)
+		}
+	}
+
+	result := make([]model.ExternalStation, 0, len(order))
+	for _, id := range order {
+		s := stations[id]
+		if s.PlayURL == "" {
+			continue
+		}
+		if s.Category == "" {
+			s.Category = s.Province
+		}
+		if s.Category == "" {
+			s.Category = ximalayaNational
+		}
+		result = append(result, *s)
+	}
+	return result, nil
+}
+
+// radios 分页拉取电台列表
+func (c *XimalayaClient) radios(path string, params url.Values) ([]ximalayaRadio, error) {
+	var all []ximalayaRadio
+	for page := 1; ; page++ {
+		query := url.Values{}
+		for k, v := range params {
+			query[k] = v
+		}
+		query.Set("pageNum", strconv.Itoa(page))
+		query.Set("pageSize", strconv.Itoa(ximalayaPageSize))
+
+		var result struct {
+			TotalSize int             `json:"totalSize"`
+			Data      []ximalayaRadio `json:"data"`
+		}
+		if err := c.get(path, query, &result); err != nil {
+			return nil, err
+		}
+		all = append(all, result.Data...)
+		if len(result.Data) < ximalayaPageSize || len(all) >= result.TotalSize {
+			return all, nil
+		}
+	}
+}
+
+// get 请求接口并解析 {"ret":0,"data":...} 格式的响应
+func (c *XimalayaClient) get(path string, params url.Values, data interface{}) error {
+	endpoint := c.baseURL + path
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+	resp, err := c.client.Get(endpoint)
+	if err != nil {
+		return fmt.Errorf("请求 %s 失败: %v", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("请求 %s 失败: HTTP %d", path, resp.StatusCode)
+	}
+
+	var envelope struct {
+		Ret  int             `json:"ret"`
+		Msg  string          `json:"msg"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("解析 %s 响应失败: %v", path, err)
+	}
+	if envelope.Ret != 0 {
+		return fmt.Errorf("请求 %s 失败: %s (ret=%d)", path, envelope.Msg, envelope.Ret)
+	}
+	if err := json.Unmarshal(envelope.Data, data); err != nil {
+		return fmt.Errorf("解析 %s 数据失败: %v", path, err)
+	}
+	return nil
+}
+
+// SyncXimalaya 拉取喜马拉雅电台目录并同步到本地目录
+func SyncXimalaya(client *XimalayaClient, database db.SyncStore) (*model.SyncReport, error) {
+	stations, err := client.FetchDirectory()
+	if err != nil {
+		return nil, fmt.Errorf("拉取喜马拉雅电台目录失败: %v", err)
+	}
+	if len(stations) == 0 {
+		// 空目录多半是接口异常，避免把所有电台标记为下架
+		return nil, fmt.Errorf("喜马拉雅电台目录为空，已跳过同步")
+	}
+	return database.SyncExternalStations(XimalayaSource, stations)
+}
+
+// FormatSyncReport 返回同步结果的简要说明
+func FormatSyncReport(r *model.SyncReport) string {
+	return fmt.Sprintf("新增 %d，更新 %d，改名 %d，下架 %d，恢复 %d",
+		len(r.Added), r.Updated, len(r.Renamed), len(r.Removed), len(r.Restored))
+}
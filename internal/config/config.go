@@ -17,6 +17,12 @@ var (
 
 	// TempDir is the directory for temporary files
 	TempDir string
+
+	// CatalogDir is the directory for additional station config files (catalog.d)
+	CatalogDir string
+
+	// RemoteDir is the directory for cached remote catalogs
+	RemoteDir string
 )
 
 // Init initializes all paths relative to the current working directory
@@ -49,5 +55,17 @@ func Init() error {
 		return err
 	}
 
+	// Setup catalog directory
+	CatalogDir = filepath.Join(AppDir, "catalog.d")
+	if err := os.MkdirAll(CatalogDir, 0755); err != nil {
+		return err
+	}
+
+	// Setup remote catalog cache directory
+	RemoteDir = filepath.Join(AppDir, "remote")
+	if err := os.MkdirAll(RemoteDir, 0755); err != nil {
+		return err
+	}
+
 	return nil
 }
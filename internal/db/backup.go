@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// Backup 将数据库备份到 dest，程序运行中也能得到一致的快照
+func (d *Database) Backup(dest string) error {
+	return backupTo(d.db, dest)
+}
+
+// backupTo 使用 SQLite 在线备份接口将 db 复制到 dest，得到一致的快照。dest 已存在时被覆盖
+func backupTo(db *sql.DB, dest string) error {
+	if err := os.Remove(dest); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove old backup: %v", err)
+	}
+	target, err := sql.Open("sqlite3", dest)
+	if err != nil {
+		return fmt.Errorf("failed to open backup file: %v", err)
+	}
+	defer target.Close()
+	return copyDatabase(target, db)
+}
+
+// Restore 用备份文件 src 替换数据库的全部内容，随后将其升级到最新结构。
+// 恢复前先校验备份文件的完整性与结构版本，并将当前数据库备份到 before
+func (d *Database) Restore(src, before string) error {
+	if _, err := os.Stat(src); err != nil {
+		return fmt.Errorf("failed to open backup file: %v", err)
+	}
+	source, err := sql.Open("sqlite3", "file:"+src+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("failed to open backup file: %v", err)
+	}
+	defer source.Close()
+	if err := checkBackup(source); err != nil {
+		return err
+	}
+
+	if before != "" {
+		if err := d.Backup(before); err != nil {
+			return err
+		}
+	}
+	if err := copyDatabase(d.db, source); err != nil {
+		return err
+	}
+	// 备份可能来自旧版本，就地升级；升级前的原数据库已备份，无需再次备份
+	return migrate(d.db, "")
+}
+
+// checkBackup 确认备份文件是完好的 FMgo 数据库，且结构版本不高于程序支持的版本
+func checkBackup(source *sql.DB) error {
+	var result string
+	if err := source.QueryRow(`PRAGMA integrity_check`).Scan(&result); err != nil {
+		return fmt.Errorf("failed to check backup file: %v", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("backup file is corrupted: %s", result)
+	}
+
+	var tables int
+	if err := source.QueryRow(`
+		SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_version'
+	`).Scan(&tables); err != nil {
+		return fmt.Errorf("failed to check backup file: %v", err)
+	}
+	if tables == 0 {
+		return fmt.Errorf("not an FMgo database")
+	}
+	version, err := schemaVersion(source)
+	if err != nil {
+		return err
+	}
+	if version > SchemaVersion() {
+		return fmt.Errorf("backup schema version %d is newer than supported version %d", version, SchemaVersion())
+	}
+	return nil
+}
+
+// copyDatabase 通过 SQLite 备份接口将 src 的 main 数据库完整复制到 dst
+func copyDatabase(dst, src *sql.DB) error {
+	ctx := context.Background()
+	dstConn, err := dst.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %v", err)
+	}
+	defer dstConn.Close()
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %v", err)
+	}
+	defer srcConn.Close()
+
+	return dstConn.Raw(func(dstDriver interface{}) error {
+		return srcConn.Raw(func(srcDriver interface{}) error {
+			to, ok := dstDriver.(*sqlite3.SQLiteConn)
+			from, ok2 := srcDriver.(*sqlite3.SQLiteConn)
+			if !ok || !ok2 {
+				return fmt.Errorf("unexpected sqlite driver connection")
+			}
+			backup, err := to.Backup("main", from, "main")
+			if err != nil {
+				return fmt.Errorf("failed to start backup: %v", err)
+			}
+			if _, err := backup.Step(-1); err != nil {
+				backup.Finish()
+				return fmt.Errorf("failed to copy database: %v", err)
+			}
+			if err := backup.Finish(); err != nil {
+				return fmt.Errorf("failed to finish backup: %v", err)
+			}
+			return nil
+		})
+	})
+}
@@ -0,0 +1,137 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"FMgo/internal/model"
+)
+
+// GetLocalCatalog 获取本地电台目录。外部目录同步的电台以来源与外部 ID 作为电台 ID，
+// 用户添加的电台按播放地址生成 ID
+func (d *Database) GetLocalCatalog() ([]model.Category, error) {
+	rows, err := d.db.Query(`
+		SELECT c.name, s.name, s.play_url, s.province, s.logo, s.source, s.external_id
+		FROM local_categories c
+		LEFT JOIN local_stations s ON s.category_id = c.id AND s.removed_at IS NULL
+		ORDER BY c.position, c.id, s.position, s.id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get local catalog: %v", err)
+	}
+	defer rows.Close()
+
+	var categories []model.Category
+	for rows.Next() {
+		var catName string
+		var name, playURL, province, logo, source, externalID sql.NullString
+		if err := rows.Scan(&catName, &name, &playURL, &province, &logo, &source, &externalID); err != nil {
+			return nil, err
+		}
+		if len(categories) == 0 || categories[len(categories)-1].Name != catName {
+			categories = append(categories, model.Category{Name: catName})
+		}
+		if name.Valid {
+			radio := model.Radio{
+				Name:     name.String,
+				PlayURL:  playURL.String,
+				Province: province.String,
+				Logo:     logo.String,
+			}
+			if externalID.String != "" && source.String != "user" {
+				radio.ID = syncedStationID(source.String, externalID.String)
+			}
+			cat := &categories[len(categories)-1]
+			cat.RadioList = append(cat.RadioList, radio)
+		}
+	}
+	return categories, rows.Err()
+}
+
+// AddLocalCategory 添加本地分类，已存在时返回其 ID
+func (d *Database) AddLocalCategory(name string) (int64, error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	id, err := addLocalCategory(tx, name)
+	if err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// AddLocalStation 向本地分类添加电台，分类不存在时自动创建
+func (d *Database) AddLocalStation(category string, radio model.Radio) error {
+	categoryID, err := d.AddLocalCategory(category)
+	if err != nil {
+		return err
+	}
+	_, err = d.db.Exec(`
+		INSERT INTO local_stations (category_id, name, play_url, position)
+		VALUES (?, ?, ?, (SELECT COALESCE(MAX(position), 0) + 1 FROM local_stations WHERE category_id = ?))
+	`, categoryID, radio.Name, radio.PlayURL, categoryID)
+	if err != nil {
+		return fmt.Errorf("failed to add local station: %v", err)
+	}
+	return nil
+}
+
+// ImportLocalStations 将电台批量导入本地目录，按播放地址去重：
+// 本地目录或 known 中已有的地址跳过。返回新增与跳过的数量
+func (d *Database) ImportLocalStations(categories []model.Category, known map[string]bool) (added, skipped int, err error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	seen := make(map[string]bool, len(known))
+	for url := range known {
+		seen[url] = true
+	}
+	rows, err := tx.Query(`SELECT play_url FROM local_stations WHERE removed_at IS NULL`)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to load local stations: %v", err)
+	}
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		seen[url] = true
+	}
+	rows.Close()
+
+	for _, cat := range categories {
+		var categoryID int64
+		for _, radio := range cat.RadioList {
+			if seen[radio.PlayURL] {
+				skipped++
+				continue
+			}
+			seen[radio.PlayURL] = true
+
+			if categoryID == 0 {
+				if categoryID, err = addLocalCategory(tx, cat.Name); err != nil {
+					return 0, 0, err
+				}
+			}
+			if _, err := tx.Exec(`
+				INSERT INTO local_stations (category_id, name, play_url, position)
+				VALUES (?, ?, ?, (SELECT COALESCE(MAX(position), 0) + 1 FROM local_stations WHERE category_id = ?))
+			`, categoryID, radio.Name, radio.PlayURL, categoryID); err != nil {
+				return 0, 0, fmt.Errorf("failed to import local station: %v", err)
+			}
+			added++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit import: %v", err)
+	}
+	return added, skipped, nil
+}
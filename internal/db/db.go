@@ -4,7 +4,6 @@ import (
 	"FMgo/internal/config"
 	"database/sql"
 	"fmt"
-	"log"
 	"time"
 
 	"FMgo/internal/model"
@@ -15,34 +14,21 @@ type Database struct {
 	db *sql.DB
 }
 
+// New 打开程序目录中的数据库文件
 func New() (*Database, error) {
-	db, err := sql.Open("sqlite3", config.DBFile)
+	return Open(config.DBFile)
+}
+
+// Open 打开 path 处的数据库并升级到最新结构，文件不存在时创建
+func Open(path string) (*Database, error) {
+	db, err := sql.Open("sqlite3", path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %v", err)
 	}
 
-	// 创建历史记录表
-	if _, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS history (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			radio_name TEXT NOT NULL,
-			play_url TEXT NOT NULL,
-			played_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		)
-	`); err != nil {
-		return nil, fmt.Errorf("failed to create history table: %v", err)
-	}
-
-	// 创建收藏表
-	if _, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS favorites (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			radio_name TEXT NOT NULL UNIQUE,
-			play_url TEXT NOT NULL,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		)
-	`); err != nil {
-		return nil, fmt.Errorf("failed to create favorites table: %v", err)
+	if err := migrate(db, path); err != nil {
+		db.Close()
+		return nil, err
 	}
 
 	return &Database{db: db}, nil
@@ -52,92 +38,79 @@ func (d *Database) Close() error {
 	return d.db.Close()
 }
 
-func (d *Database) AddHistory(radio model.Radio) error {
-	query := `
-	INSERT INTO history (radio_name, play_url, played_at)
-	VALUES (?, ?, ?)`
-
-	_, err := d.db.Exec(query, radio.Name, radio.PlayURL, time.Now())
+// AddFavorite 添加收藏到未分组收藏的末尾，已收藏时更新名称与播放地址
+func (d *Database) AddFavorite(radio model.Radio) error {
+	tx, err := d.db.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to add history: %v", err)
+		return fmt.Errorf("failed to begin transaction: %v", err)
 	}
-
-	return nil
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO favorites (station_id, radio_name, play_url, position, updated_at)
+		VALUES (?, ?, ?, (SELECT COALESCE(MAX(position), 0) + 1 FROM favorites WHERE folder = ''), ?)
+		ON CONFLICT(station_id) DO UPDATE SET
+			radio_name = excluded.radio_name, play_url = excluded.play_url, updated_at = excluded.updated_at
+	`, radio.StationID(), radio.Name, radio.PlayURL, time.Now()); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM favorite_tombstones WHERE station_id = ?`, radio.StationID()); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func (d *Database) GetHistory(limit int) ([]model.PlayHistory, error) {
-	query := `
-	SELECT id, radio_name, play_url, played_at
-	FROM history
-	ORDER BY played_at DESC
-	LIMIT ?`
-
-	rows, err := d.db.Query(query, limit)
+// RemoveFavorite 移除收藏，并记录移除时间供同步时使用
+func (d *Database) RemoveFavorite(stationID string) error {
+	tx, err := d.db.Begin()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get history: %v", err)
+		return fmt.Errorf("failed to begin transaction: %v", err)
 	}
-	defer rows.Close()
+	defer tx.Rollback()
 
-	var history []model.PlayHistory
-	for rows.Next() {
-		var h model.PlayHistory
-		err := rows.Scan(&h.ID, &h.RadioName, &h.PlayURL, &h.PlayedAt)
-		if err != nil {
-			log.Printf("Error scanning history row: %v", err)
-			continue
+	result, err := tx.Exec(`
+		DELETE FROM favorites
+		WHERE station_id = ?
+	`, stationID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		if err := addTombstone(tx, stationID, time.Now()); err != nil {
+			return err
 		}
-		history = append(history, h)
 	}
-
-	return history, nil
-}
-
-// AddFavorite 添加收藏
-func (d *Database) AddFavorite(radio model.Radio) error {
-	_, err := d.db.Exec(`
-		INSERT OR REPLACE INTO favorites (radio_name, play_url)
-		VALUES (?, ?)
-	`, radio.Name, radio.PlayURL)
-	return err
-}
-
-// RemoveFavorite 移除收藏
-func (d *Database) RemoveFavorite(radioName string) error {
-	_, err := d.db.Exec(`
-		DELETE FROM favorites
-		WHERE radio_name = ?
-	`, radioName)
-	return err
+	return tx.Commit()
 }
 
 // IsFavorite 检查是否已收藏
-func (d *Database) IsFavorite(radioName string) (bool, error) {
+func (d *Database) IsFavorite(stationID string) (bool, error) {
 	var count int
 	err := d.db.QueryRow(`
 		SELECT COUNT(*) FROM favorites
-		WHERE radio_name = ?
-	`, radioName).Scan(&count)
+		WHERE station_id = ?
+	`, stationID).Scan(&count)
 	return count > 0, err
 }
 
-// GetFavorites 获取收藏列表
-func (d *Database) GetFavorites() ([]model.Radio, error) {
+// GetFavorites 获取收藏列表：未分组的收藏在前，文件夹按名称排列，文件夹内按手动排列的顺序
+func (d *Database) GetFavorites() ([]model.Favorite, error) {
 	rows, err := d.db.Query(`
-		SELECT radio_name, play_url FROM favorites
-		ORDER BY created_at DESC
+		SELECT station_id, radio_name, play_url, folder, note, rating, position FROM favorites
+		ORDER BY folder, position, id
 	`)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var favorites []model.Radio
+	var favorites []model.Favorite
 	for rows.Next() {
-		var radio model.Radio
-		if err := rows.Scan(&radio.Name, &radio.PlayURL); err != nil {
+		var f model.Favorite
+		if err := rows.Scan(&f.ID, &f.Name, &f.PlayURL, &f.Folder, &f.Note, &f.Rating, &f.Position); err != nil {
 			return nil, err
 		}
-		favorites = append(favorites, radio)
+		favorites = append(favorites, f)
 	}
-	return favorites, nil
+	return favorites, rows.Err()
 }
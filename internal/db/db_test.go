@@ -0,0 +1,19 @@
+package db_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"FMgo/internal/db"
+	"FMgo/internal/db/storetest"
+)
+
+func TestDatabase(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) db.Store {
+		d, err := db.Open(filepath.Join(t.TempDir(), "fmgo.db"))
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		return d
+	})
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"FMgo/internal/model"
+)
+
+// CacheDirectoryStations 缓存在线目录返回的电台，供离线浏览
+func (d *Database) CacheDirectoryStations(stations []model.DirectoryStation) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT OR REPLACE INTO directory_cache (
+			uuid, name, url, url_resolved, homepage, favicon, tags, country, country_code,
+			language, codec, bitrate, votes, click_count, fetched_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare directory cache: %v", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for _, s := range stations {
+		if _, err := stmt.Exec(s.UUID, s.Name, s.URL, s.URLResolved, s.Homepage, s.Favicon, s.Tags,
+			s.Country, s.CountryCode, s.Language, s.Codec, s.Bitrate, s.Votes, s.ClickCount, now); err != nil {
+			return fmt.Errorf("failed to cache directory station: %v", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// SearchDirectoryCache 在缓存中按条件搜索电台，按投票数排序
+func (d *Database) SearchDirectoryCache(q model.DirectoryQuery, orderBy string) ([]model.DirectoryStation, error) {
+	var where []string
+	var args []interface{}
+	like := func(column, value string) {
+		if value != "" {
+			where = append(where, column+" LIKE ?")
+			args = append(args, "%"+value+"%")
+		}
+	}
+	like("name", q.Name)
+	like("country", q.Country)
+	like("language", q.Language)
+	like("tags", q.Tag)
+	like("codec", q.Codec)
+	if q.BitrateMin > 0 {
+		where = append(where, "bitrate >= ?")
+		args = append(args, q.BitrateMin)
+	}
+
+	query := `
+		SELECT uuid, name, url, url_resolved, homepage, favicon, tags, country, country_code,
+			language, codec, bitrate, votes, click_count, fetched_at
+		FROM directory_cache`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	if orderBy != "click_count" {
+		orderBy = "votes"
+	}
+	query += " ORDER BY " + orderBy + " DESC LIMIT ?"
+	limit := q.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	args = append(args, limit)
+
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search directory cache: %v", err)
+	}
+	defer rows.Close()
+
+	var stations []model.DirectoryStation
+	for rows.Next() {
+		var s model.DirectoryStation
+		if err := rows.Scan(&s.UUID, &s.Name, &s.URL, &s.URLResolved, &s.Homepage, &s.Favicon, &s.Tags,
+			&s.Country, &s.CountryCode, &s.Language, &s.Codec, &s.Bitrate, &s.Votes, &s.ClickCount, &s.FetchedAt); err != nil {
+			return nil, err
+		}
+		stations = append(stations, s)
+	}
+	return stations, rows.Err()
+}
+
+// GetDirectoryStationByURL 按播放地址查找缓存中的电台
+func (d *Database) GetDirectoryStationByURL(url string) (*model.DirectoryStation, error) {
+	var s model.DirectoryStation
+	err := d.db.QueryRow(`
+		SELECT uuid, name, url, url_resolved FROM directory_cache
+		WHERE url_resolved = ? OR url = ?
+		LIMIT 1
+	`, url, url).Scan(&s.UUID, &s.Name, &s.URL, &s.URLResolved)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get directory station: %v", err)
+	}
+	return &s, nil
+}
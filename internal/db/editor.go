@@ -0,0 +1,227 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"FMgo/internal/model"
+)
+
+// RenameLocalCategory 重命名本地分类
+func (d *Database) RenameLocalCategory(oldName, newName string) error {
+	res, err := d.db.Exec(`UPDATE local_categories SET name = ? WHERE name = ?`, newName, oldName)
+	if err != nil {
+		return fmt.Errorf("failed to rename local category: %v", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("local category not found: %s", oldName)
+	}
+	return nil
+}
+
+// DeleteLocalCategory 删除本地分类及其中的电台
+func (d *Database) DeleteLocalCategory(name string) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		DELETE FROM local_stations WHERE category_id = (SELECT id FROM local_categories WHERE name = ?)
+	`, name); err != nil {
+		return fmt.Errorf("failed to delete local stations: %v", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM local_categories WHERE name = ?`, name); err != nil {
+		return fmt.Errorf("failed to delete local category: %v", err)
+	}
+	return tx.Commit()
+}
+
+// MoveLocalCategory 将本地分类向前（delta < 0）或向后移动
+func (d *Database) MoveLocalCategory(name string, delta int) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	ids, names, err := orderedIDs(tx, `SELECT id, name FROM local_categories ORDER BY position, id`)
+	if err != nil {
+		return err
+	}
+	if err := reorder(tx, "local_categories", ids, indexOf(names, name), delta); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateLocalStation 修改本地电台的名称与播放地址，同步更新引用该电台的收藏、历史记录、统计与均衡器设置
+func (d *Database) UpdateLocalStation(category, name string, radio model.Radio) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	id, err := localStationID(tx, category, name)
+	if err != nil {
+		return err
+	}
+	var oldURL, source, externalID string
+	if err := tx.QueryRow(`
+		SELECT play_url, source, external_id FROM local_stations WHERE id = ?
+	`, id).Scan(&oldURL, &source, &externalID); err != nil {
+		return fmt.Errorf("failed to get local station: %v", err)
+	}
+	if _, err := tx.Exec(`
+		UPDATE local_stations SET name = ?, play_url = ? WHERE id = ?
+	`, radio.Name, radio.PlayURL, id); err != nil {
+		return fmt.Errorf("failed to update local station: %v", err)
+	}
+	if radio.Name != name || radio.PlayURL != oldURL {
+		oldID := model.URLStationID(oldURL)
+		radio.ID = ""
+		if externalID != "" && source != "user" {
+			radio.ID = syncedStationID(source, externalID)
+		}
+		if err := updateStationRefs(tx, radio, oldID, radio.StationID()); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteLocalStation 删除本地电台
+func (d *Database) DeleteLocalStation(category, name string) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	id, err := localStationID(tx, category, name)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM local_stations WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete local station: %v", err)
+	}
+	return tx.Commit()
+}
+
+// MoveLocalStation 在分类内将电台向前（delta < 0）或向后移动
+func (d *Database) MoveLocalStation(category, name string, delta int) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	ids, names, err := orderedIDs(tx, `
+		SELECT s.id, s.name FROM local_stations s
+		JOIN local_categories c ON c.id = s.category_id
+		WHERE c.name = ? AND s.removed_at IS NULL
+		ORDER BY s.position, s.id
+	`, category)
+	if err != nil {
+		return err
+	}
+	if err := reorder(tx, "local_stations", ids, indexOf(names, name), delta); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// MoveLocalStationTo 将电台移动到另一个本地分类的末尾，分类不存在时自动创建
+func (d *Database) MoveLocalStationTo(category, name, target string) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	id, err := localStationID(tx, category, name)
+	if err != nil {
+		return err
+	}
+	targetID, err := addLocalCategory(tx, target)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		UPDATE local_stations
+		SET category_id = ?, position = (SELECT COALESCE(MAX(position), 0) + 1 FROM local_stations WHERE category_id = ?)
+		WHERE id = ?
+	`, targetID, targetID, id); err != nil {
+		return fmt.Errorf("failed to move local station: %v", err)
+	}
+	return tx.Commit()
+}
+
+// localStationID 返回本地分类中指定名称的电台 ID
+func localStationID(tx *sql.Tx, category, name string) (int64, error) {
+	var id int64
+	err := tx.QueryRow(`
+		SELECT s.id FROM local_stations s
+		JOIN local_categories c ON c.id = s.category_id
+		WHERE c.name = ? AND s.name = ? AND s.removed_at IS NULL
+		ORDER BY s.position, s.id LIMIT 1
+	`, category, name).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("local station not found: %s/%s", category, name)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get local station: %v", err)
+	}
+	return id, nil
+}
+
+// orderedIDs 返回按显示顺序排列的 ID 与名称
+func orderedIDs(tx *sql.Tx, query string, args ...interface{}) ([]int64, []string, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load order: %v", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	var names []string
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, nil, err
+		}
+		ids = append(ids, id)
+		names = append(names, name)
+	}
+	return ids, names, rows.Err()
+}
+
+// reorder 将 ids[i] 移动 delta 个位置后按顺序重写 position，超出范围时不移动
+func reorder(tx *sql.Tx, table string, ids []int64, i, delta int) error {
+	if i < 0 {
+		return fmt.Errorf("item not found in %s", table)
+	}
+	j := i + delta
+	if j < 0 || j >= len(ids) {
+		return nil
+	}
+	ids[i], ids[j] = ids[j], ids[i]
+	for pos, id := range ids {
+		if _, err := tx.Exec(`UPDATE `+table+` SET position = ? WHERE id = ?`, pos+1, id); err != nil {
+			return fmt.Errorf("failed to reorder %s: %v", table, err)
+		}
+	}
+	return nil
+}
+
+func indexOf(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,142 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"FMgo/internal/model"
+)
+
+// legacyEQID 是升级时找不到对应电台的均衡器设置使用的 ID，读取时按电台名称回退到它
+func legacyEQID(radioName string) string {
+	return "name:" + radioName
+}
+
+// migrateStationEQ 将按电台名称保存的均衡器设置改为按电台 ID 保存，需在收藏与历史记录补充 ID 之后执行。
+// 旧记录依次按收藏、最近的收听记录与本地目录中的同名电台确定 ID，
+// 都找不到时使用 legacyEQID，在该名称的电台下次保存均衡器时移除
+func migrateStationEQ(tx *sql.Tx, synced map[string]string) error {
+	if _, err := tx.Exec(`
+		CREATE TABLE station_eq_new (
+			station_id TEXT PRIMARY KEY,
+			radio_name TEXT NOT NULL,
+			preset TEXT NOT NULL,
+			gains TEXT NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create station_eq table: %v", err)
+	}
+
+	rows, err := tx.Query(`SELECT radio_name, preset, gains FROM station_eq`)
+	if err != nil {
+		return fmt.Errorf("failed to load station eq: %v", err)
+	}
+	var settings []model.EQSetting
+	var gains []string
+	for rows.Next() {
+		var s model.EQSetting
+		var g string
+		if err := rows.Scan(&s.RadioName, &s.Preset, &g); err != nil {
+			rows.Close()
+			return err
+		}
+		settings = append(settings, s)
+		gains = append(gains, g)
+	}
+	rows.Close()
+
+	for i, s := range settings {
+		id, err := stationIDByName(tx, s.RadioName, synced)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`
+			INSERT OR IGNORE INTO station_eq_new (station_id, radio_name, preset, gains) VALUES (?, ?, ?, ?)
+		`, id, s.RadioName, s.Preset, gains[i]); err != nil {
+			return fmt.Errorf("failed to migrate station eq: %v", err)
+		}
+	}
+
+	if _, err := tx.Exec(`DROP TABLE station_eq`); err != nil {
+		return fmt.Errorf("failed to drop old station_eq table: %v", err)
+	}
+	if _, err := tx.Exec(`ALTER TABLE station_eq_new RENAME TO station_eq`); err != nil {
+		return fmt.Errorf("failed to rename station_eq table: %v", err)
+	}
+	return nil
+}
+
+// stationIDByName 按名称查找电台 ID，找不到时返回 legacyEQID
+func stationIDByName(tx *sql.Tx, name string, synced map[string]string) (string, error) {
+	var id string
+	err := tx.QueryRow(`SELECT station_id FROM favorites WHERE radio_name = ? LIMIT 1`, name).Scan(&id)
+	if err == sql.ErrNoRows {
+		err = tx.QueryRow(`
+			SELECT station_id FROM history WHERE radio_name = ?
+			ORDER BY played_at DESC LIMIT 1
+		`, name).Scan(&id)
+	}
+	if err == sql.ErrNoRows {
+		var playURL string
+		err = tx.QueryRow(`SELECT play_url FROM local_stations WHERE name = ? LIMIT 1`, name).Scan(&playURL)
+		if err == nil {
+			if id = synced[playURL]; id == "" {
+				id = model.URLStationID(playURL)
+			}
+		}
+	}
+	if err == sql.ErrNoRows {
+		return legacyEQID(name), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve station for eq: %v", err)
+	}
+	return id, nil
+}
+
+// SaveStationEQ 保存电台的均衡器设置
+func (d *Database) SaveStationEQ(setting model.EQSetting) error {
+	gains, err := json.Marshal(setting.Gains)
+	if err != nil {
+		return fmt.Errorf("failed to encode eq gains: %v", err)
+	}
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT OR REPLACE INTO station_eq (station_id, radio_name, preset, gains)
+		VALUES (?, ?, ?, ?)
+	`, setting.StationID, setting.RadioName, setting.Preset, string(gains)); err != nil {
+		return fmt.Errorf("failed to save station eq: %v", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM station_eq WHERE station_id = ?`, legacyEQID(setting.RadioName)); err != nil {
+		return fmt.Errorf("failed to save station eq: %v", err)
+	}
+	return tx.Commit()
+}
+
+// GetStationEQ 获取电台的均衡器设置，未设置时返回 nil。
+// 升级时未能确定电台的旧设置按电台名称读取
+func (d *Database) GetStationEQ(radio model.Radio) (*model.EQSetting, error) {
+	var gains string
+	var setting model.EQSetting
+	err := d.db.QueryRow(`
+		SELECT station_id, radio_name, preset, gains FROM station_eq
+		WHERE station_id IN (?, ?)
+		ORDER BY station_id = ? DESC LIMIT 1
+	`, radio.StationID(), legacyEQID(radio.Name), radio.StationID()).Scan(&setting.StationID, &setting.RadioName, &setting.Preset, &gains)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get station eq: %v", err)
+	}
+	if err := json.Unmarshal([]byte(gains), &setting.Gains); err != nil {
+		return nil, fmt.Errorf("failed to decode eq gains: %v", err)
+	}
+	return &setting, nil
+}
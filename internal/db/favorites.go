@@ -0,0 +1,124 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migrateFavoriteFolders 为收藏增加文件夹、备注、评分与手动排序，
+// 原有收藏按之前的显示顺序（最新收藏在前）编号
+func migrateFavoriteFolders(tx *sql.Tx) error {
+	for _, column := range []string{
+		`folder TEXT NOT NULL DEFAULT ''`,
+		`note TEXT NOT NULL DEFAULT ''`,
+		`rating INTEGER NOT NULL DEFAULT 0`,
+		`position INTEGER NOT NULL DEFAULT 0`,
+	} {
+		if _, err := tx.Exec(`ALTER TABLE favorites ADD COLUMN ` + column); err != nil {
+			return fmt.Errorf("failed to add favorites column: %v", err)
+		}
+	}
+	if _, err := tx.Exec(`
+		UPDATE favorites SET position = (
+			SELECT rn FROM (
+				SELECT id, ROW_NUMBER() OVER (ORDER BY created_at DESC, id DESC) AS rn FROM favorites
+			) ranked WHERE ranked.id = favorites.id
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to number favorites: %v", err)
+	}
+	return nil
+}
+
+// MoveFavorite 在所在文件夹内将收藏向前（delta < 0）或向后移动
+func (d *Database) MoveFavorite(stationID string, delta int) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	folder, err := favoriteFolder(tx, stationID)
+	if err != nil {
+		return err
+	}
+	ids, stationIDs, err := orderedIDs(tx, `
+		SELECT id, station_id FROM favorites WHERE folder = ? ORDER BY position, id
+	`, folder)
+	if err != nil {
+		return err
+	}
+	if err := reorder(tx, "favorites", ids, indexOf(stationIDs, stationID), delta); err != nil {
+		return err
+	}
+	// 同一文件夹的收藏都可能被重新编号
+	if _, err := tx.Exec(`UPDATE favorites SET updated_at = ? WHERE folder = ?`, time.Now(), folder); err != nil {
+		return fmt.Errorf("failed to move favorite: %v", err)
+	}
+	return tx.Commit()
+}
+
+// SetFavoriteFolder 将收藏移到文件夹 folder 的末尾，folder 为空表示移出文件夹
+func (d *Database) SetFavoriteFolder(stationID, folder string) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	current, err := favoriteFolder(tx, stationID)
+	if err != nil {
+		return err
+	}
+	if current == folder {
+		return nil
+	}
+	if _, err := tx.Exec(`
+		UPDATE favorites
+		SET folder = ?, position = (SELECT COALESCE(MAX(position), 0) + 1 FROM favorites WHERE folder = ?), updated_at = ?
+		WHERE station_id = ?
+	`, folder, folder, time.Now(), stationID); err != nil {
+		return fmt.Errorf("failed to move favorite: %v", err)
+	}
+	return tx.Commit()
+}
+
+// SetFavoriteNote 设置收藏的备注
+func (d *Database) SetFavoriteNote(stationID, note string) error {
+	return d.updateFavorite(stationID, "note", note)
+}
+
+// SetFavoriteRating 设置收藏的评分（1-5），0 表示清除评分
+func (d *Database) SetFavoriteRating(stationID string, rating int) error {
+	if rating < 0 || rating > 5 {
+		return fmt.Errorf("rating must be between 0 and 5")
+	}
+	return d.updateFavorite(stationID, "rating", rating)
+}
+
+func (d *Database) updateFavorite(stationID, column string, value interface{}) error {
+	result, err := d.db.Exec(`
+		UPDATE favorites SET `+column+` = ?, updated_at = ? WHERE station_id = ?
+	`, value, time.Now(), stationID)
+	if err != nil {
+		return fmt.Errorf("failed to update favorite: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("favorite not found")
+	}
+	return nil
+}
+
+// favoriteFolder 返回收藏所在的文件夹
+func favoriteFolder(tx *sql.Tx, stationID string) (string, error) {
+	var folder string
+	err := tx.QueryRow(`SELECT folder FROM favorites WHERE station_id = ?`, stationID).Scan(&folder)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("favorite not found")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get favorite: %v", err)
+	}
+	return folder, nil
+}
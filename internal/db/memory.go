@@ -0,0 +1,1081 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"FMgo/internal/model"
+)
+
+// Memory 是保存在内存中的 Store 实现，不读写磁盘，供界面与功能的测试使用。
+// 备份、恢复与同步目录合并不在 Store 中，仍需使用 Database
+type Memory struct {
+	mu         sync.Mutex
+	nextID     int64
+	sessions   []model.PlayHistory
+	favorites  []memoryFavorite
+	categories []memoryCategory
+	stations   []memoryStation
+	streams    []model.StreamStats
+	usage      map[memoryUsageKey]int64
+	eq         map[string]model.EQSetting
+	directory  map[string]model.DirectoryStation // 按 UUID 缓存的在线目录电台
+	syncedAt   map[string]time.Time              // 各外部目录上次同步的时间
+	settings   map[string]string
+}
+
+// memoryUsageKey 对应 data_usage 表的主键
+type memoryUsageKey struct {
+	month     string
+	stationID string
+}
+
+type memoryFavorite struct {
+	model.Favorite
+	id int64
+}
+
+type memoryCategory struct {
+	id       int64
+	name     string
+	position int
+}
+
+type memoryStation struct {
+	id         int64
+	categoryID int64
+	name       string
+	playURL    string
+	position   int
+	source     string // 外部目录同步的电台为来源名称，用户添加的为空
+	externalID string
+	province   string
+	logo       string
+	program    string
+	removed    bool // 外部目录中已下架
+}
+
+// NewMemory 创建空的内存存储
+func NewMemory() *Memory {
+	return &Memory{
+		usage:     make(map[memoryUsageKey]int64),
+		eq:        make(map[string]model.EQSetting),
+		directory: make(map[string]model.DirectoryStation),
+		syncedAt:  make(map[string]time.Time),
+		settings:  make(map[string]string),
+	}
+}
+
+func (m *Memory) Close() error {
+	return nil
+}
+
+func (m *Memory) newID() int64 {
+	m.nextID++
+	return m.nextID
+}
+
+// StartSession 记录开始收听电台，返回会话 ID
+func (m *Memory) StartSession(radio model.Radio, startedAt time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := m.newID()
+	m.sessions = append(m.sessions, model.PlayHistory{
+		ID:        id,
+		StationID: radio.StationID(),
+		RadioName: radio.Name,
+		PlayURL:   radio.PlayURL,
+		PlayedAt:  startedAt,
+	})
+	return id, nil
+}
+
+// EndSession 记录收听结束：结束时间、收听时长（不含暂停）、结束原因与下载字节数
+func (m *Memory) EndSession(id int64, endedAt time.Time, listened time.Duration, reason string, bytes int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.sessions {
+		if s := &m.sessions[i]; s.ID == id {
+			s.EndedAt = &endedAt
+			s.ListenedMs = listened.Milliseconds()
+			s.EndReason = reason
+			s.BytesReceived = bytes
+		}
+	}
+	return nil
+}
+
+// CloseOpenSessions 结束未正常结束的会话，时长按未知记为 0
+func (m *Memory) CloseOpenSessions() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.sessions {
+		if s := &m.sessions[i]; s.EndedAt == nil {
+			endedAt := s.PlayedAt
+			s.EndedAt = &endedAt
+			s.EndReason = model.EndReasonExit
+		}
+	}
+	return nil
+}
+
+// GetHistory 按开始时间倒序返回收听会话，limit 为 -1 时不限制条数
+func (m *Memory) GetHistory(limit int) ([]model.PlayHistory, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	history := m.sortedSessions(true)
+	if limit >= 0 && len(history) > limit {
+		history = history[:limit]
+	}
+	return history, nil
+}
+
+// GetStationHistory 按电台汇总收听会话，按最近播放倒序。名称与地址取自最近一次会话
+func (m *Memory) GetStationHistory(limit int) ([]model.StationHistory, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var history []model.StationHistory
+	index := make(map[string]int)
+	for _, s := range m.sortedSessions(true) {
+		i, ok := index[s.StationID]
+		if !ok {
+			i = len(history)
+			index[s.StationID] = i
+			history = append(history, model.StationHistory{
+				StationID:  s.StationID,
+				RadioName:  s.RadioName,
+				PlayURL:    s.PlayURL,
+				LastPlayed: s.PlayedAt,
+			})
+		}
+		history[i].Sessions++
+		history[i].Listened += s.Listened()
+	}
+	if limit >= 0 && len(history) > limit {
+		history = history[:limit]
+	}
+	return history, nil
+}
+
+// ClearHistory 删除全部收听记录
+func (m *Memory) ClearHistory() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.clearHistory(allStations, time.Now())
+	return nil
+}
+
+// DeleteStationHistory 删除一个电台的全部收听记录
+func (m *Memory) DeleteStationHistory(stationID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.clearHistory(stationID, time.Now())
+	return nil
+}
+
+// PruneHistory 按保留策略删除已结束的收听记录，规则同 Database.PruneHistory
+func (m *Memory) PruneHistory(now time.Time, maxAge time.Duration, maxRows int) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var cutoff time.Time
+	if maxAge > 0 {
+		cutoff = now.Add(-maxAge)
+	}
+	if maxRows > 0 {
+		var ended []model.PlayHistory
+		for _, s := range m.sortedSessions(true) {
+			if s.EndedAt != nil {
+				ended = append(ended, s)
+			}
+		}
+		if len(ended) > maxRows && ended[maxRows].PlayedAt.After(cutoff) {
+			cutoff = ended[maxRows].PlayedAt
+		}
+	}
+	if cutoff.IsZero() {
+		return 0, nil
+	}
+
+	var count int64
+	kept := m.sessions[:0]
+	for _, s := range m.sessions {
+		if s.EndedAt != nil && !s.PlayedAt.After(cutoff) {
+			count++
+			continue
+		}
+		kept = append(kept, s)
+	}
+	m.sessions = kept
+	return count, nil
+}
+
+// clearHistory 删除 stationID 在 before 及之前开始的收听记录
+func (m *Memory) clearHistory(stationID string, before time.Time) {
+	kept := m.sessions[:0]
+	for _, s := range m.sessions {
+		if !s.PlayedAt.After(before) && (stationID == allStations || s.StationID == stationID) {
+			continue
+		}
+		kept = append(kept, s)
+	}
+	m.sessions = kept
+}
+
+// ListeningReport 根据收听会话生成统计报告，参数含义同 Database.ListeningReport
+func (m *Memory) ListeningReport(now time.Time, days int, categories []model.Category) (*model.ListeningReport, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var sessions []model.PlayHistory
+	for _, s := range m.sortedSessions(false) {
+		if s.ListenedMs > 0 {
+			sessions = append(sessions, s)
+		}
+	}
+	return buildReport(sessions, now, days, categories), nil
+}
+
+// sortedSessions 返回按开始时间排序的会话副本
+func (m *Memory) sortedSessions(desc bool) []model.PlayHistory {
+	sessions := make([]model.PlayHistory, len(m.sessions))
+	for i, s := range m.sessions {
+		if s.EndedAt != nil {
+			endedAt := *s.EndedAt
+			s.EndedAt = &endedAt
+		}
+		sessions[i] = s
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		a, b := sessions[i], sessions[j]
+		if !a.PlayedAt.Equal(b.PlayedAt) {
+			return a.PlayedAt.Before(b.PlayedAt) != desc
+		}
+		return (a.ID < b.ID) != desc
+	})
+	return sessions
+}
+
+// AddFavorite 添加收藏到未分组收藏的末尾，已收藏时更新名称与播放地址
+func (m *Memory) AddFavorite(radio model.Radio) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if f := m.favorite(radio.StationID()); f != nil {
+		f.Name = radio.Name
+		f.PlayURL = radio.PlayURL
+		return nil
+	}
+	m.favorites = append(m.favorites, memoryFavorite{
+		Favorite: model.Favorite{
+			Radio:    model.Radio{ID: radio.StationID(), Name: radio.Name, PlayURL: radio.PlayURL},
+			Position: m.maxFavoritePosition("") + 1,
+		},
+		id: m.newID(),
+	})
+	return nil
+}
+
+// RemoveFavorite 移除收藏
+func (m *Memory) RemoveFavorite(stationID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kept := m.favorites[:0]
+	for _, f := range m.favorites {
+		if f.ID != stationID {
+			kept = append(kept, f)
+		}
+	}
+	m.favorites = kept
+	return nil
+}
+
+// IsFavorite 检查是否已收藏
+func (m *Memory) IsFavorite(stationID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.favorite(stationID) != nil, nil
+}
+
+// GetFavorites 获取收藏列表，顺序同 Database.GetFavorites
+func (m *Memory) GetFavorites() ([]model.Favorite, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var favorites []model.Favorite
+	for _, f := range m.sortedFavorites("", false) {
+		favorites = append(favorites, f.Favorite)
+	}
+	return favorites, nil
+}
+
+// MoveFavorite 在所在文件夹内将收藏向前（delta < 0）或向后移动
+func (m *Memory) MoveFavorite(stationID string, delta int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f := m.favorite(stationID)
+	if f == nil {
+		return fmt.Errorf("favorite not found")
+	}
+	ordered := m.sortedFavorites(f.Folder, true)
+	ids := make([]int64, len(ordered))
+	stationIDs := make([]string, len(ordered))
+	for i, o := range ordered {
+		ids[i], stationIDs[i] = o.id, o.ID
+	}
+	i := indexOf(stationIDs, stationID)
+	j := i + delta
+	if j < 0 || j >= len(ids) {
+		return nil
+	}
+	ids[i], ids[j] = ids[j], ids[i]
+	for pos, id := range ids {
+		for k := range m.favorites {
+			if m.favorites[k].id == id {
+				m.favorites[k].Position = pos + 1
+			}
+		}
+	}
+	return nil
+}
+
+// SetFavoriteFolder 将收藏移到文件夹 folder 的末尾，folder 为空表示移出文件夹
+func (m *Memory) SetFavoriteFolder(stationID, folder string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f := m.favorite(stationID)
+	if f == nil {
+		return fmt.Errorf("favorite not found")
+	}
+	if f.Folder == folder {
+		return nil
+	}
+	f.Position = m.maxFavoritePosition(folder) + 1
+	f.Folder = folder
+	return nil
+}
+
+// SetFavoriteNote 设置收藏的备注
+func (m *Memory) SetFavoriteNote(stationID, note string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f := m.favorite(stationID)
+	if f == nil {
+		return fmt.Errorf("favorite not found")
+	}
+	f.Note = note
+	return nil
+}
+
+// SetFavoriteRating 设置收藏的评分（1-5），0 表示清除评分
+func (m *Memory) SetFavoriteRating(stationID string, rating int) error {
+	if rating < 0 || rating > 5 {
+		return fmt.Errorf("rating must be between 0 and 5")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f := m.favorite(stationID)
+	if f == nil {
+		return fmt.Errorf("favorite not found")
+	}
+	f.Rating = rating
+	return nil
+}
+
+func (m *Memory) favorite(stationID string) *memoryFavorite {
+	for i := range m.favorites {
+		if m.favorites[i].ID == stationID {
+			return &m.favorites[i]
+		}
+	}
+	return nil
+}
+
+func (m *Memory) maxFavoritePosition(folder string) int {
+	max := 0
+	for _, f := range m.favorites {
+		if f.Folder == folder && f.Position > max {
+			max = f.Position
+		}
+	}
+	return max
+}
+
+// sortedFavorites 返回按文件夹、位置排序的收藏副本，inFolder 为 true 时只返回 folder 中的收藏
+func (m *Memory) sortedFavorites(folder string, inFolder bool) []memoryFavorite {
+	var favorites []memoryFavorite
+	for _, f := range m.favorites {
+		if !inFolder || f.Folder == folder {
+			favorites = append(favorites, f)
+		}
+	}
+	sort.Slice(favorites, func(i, j int) bool {
+		a, b := favorites[i], favorites[j]
+		if a.Folder != b.Folder {
+			return a.Folder < b.Folder
+		}
+		if a.Position != b.Position {
+			return a.Position < b.Position
+		}
+		return a.id < b.id
+	})
+	return favorites
+}
+
+// GetLocalCatalog 获取本地电台目录
+func (m *Memory) GetLocalCatalog() ([]model.Category, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var categories []model.Category
+	for _, c := range m.sortedCategories() {
+		cat := model.Category{Name: c.name}
+		for _, s := range m.sortedStations(c.id) {
+			if s.removed {
+				continue
+			}
+			radio := model.Radio{Name: s.name, PlayURL: s.playURL, Province: s.province, Logo: s.logo}
+			if s.externalID != "" {
+				radio.ID = syncedStationID(s.source, s.externalID)
+			}
+			cat.RadioList = append(cat.RadioList, radio)
+		}
+		categories = append(categories, cat)
+	}
+	return categories, nil
+}
+
+// AddLocalCategory 添加本地分类，已存在时返回其 ID
+func (m *Memory) AddLocalCategory(name string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.addCategory(name), nil
+}
+
+// RenameLocalCategory 重命名本地分类
+func (m *Memory) RenameLocalCategory(oldName, newName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c := m.category(oldName)
+	if c == nil {
+		return fmt.Errorf("local category not found: %s", oldName)
+	}
+	if newName != oldName && m.category(newName) != nil {
+		return fmt.Errorf("local category already exists: %s", newName)
+	}
+	c.name = newName
+	return nil
+}
+
+// DeleteLocalCategory 删除本地分类及其中的电台
+func (m *Memory) DeleteLocalCategory(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c := m.category(name)
+	if c == nil {
+		return nil
+	}
+	id := c.id
+	stations := m.stations[:0]
+	for _, s := range m.stations {
+		if s.categoryID != id {
+			stations = append(stations, s)
+		}
+	}
+	m.stations = stations
+	categories := m.categories[:0]
+	for _, c := range m.categories {
+		if c.id != id {
+			categories = append(categories, c)
+		}
+	}
+	m.categories = categories
+	return nil
+}
+
+// MoveLocalCategory 将本地分类向前（delta < 0）或向后移动
+func (m *Memory) MoveLocalCategory(name string, delta int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ordered := m.sortedCategories()
+	names := make([]string, len(ordered))
+	for i, c := range ordered {
+		names[i] = c.name
+	}
+	i := indexOf(names, name)
+	if i < 0 {
+		return fmt.Errorf("item not found in local_categories")
+	}
+	j := i + delta
+	if j < 0 || j >= len(ordered) {
+		return nil
+	}
+	ordered[i], ordered[j] = ordered[j], ordered[i]
+	for pos, o := range ordered {
+		m.category(o.name).position = pos + 1
+	}
+	return nil
+}
+
+// AddLocalStation 向本地分类添加电台，分类不存在时自动创建
+func (m *Memory) AddLocalStation(category string, radio model.Radio) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.addStation(m.addCategory(category), radio)
+	return nil
+}
+
+// ImportLocalStations 将电台批量导入本地目录，按播放地址去重，规则同 Database.ImportLocalStations
+func (m *Memory) ImportLocalStations(categories []model.Category, known map[string]bool) (added, skipped int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seen := make(map[string]bool, len(known))
+	for url := range known {
+		seen[url] = true
+	}
+	for _, s := range m.stations {
+		if !s.removed {
+			seen[s.playURL] = true
+		}
+	}
+	for _, cat := range categories {
+		var categoryID int64
+		for _, radio := range cat.RadioList {
+			if seen[radio.PlayURL] {
+				skipped++
+				continue
+			}
+			seen[radio.PlayURL] = true
+			if categoryID == 0 {
+				categoryID = m.addCategory(cat.Name)
+			}
+			m.addStation(categoryID, radio)
+			added++
+		}
+	}
+	return added, skipped, nil
+}
+
+// UpdateLocalStation 修改本地电台的名称与播放地址，改名时同步更新收藏与历史记录
+func (m *Memory) UpdateLocalStation(category, name string, radio model.Radio) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, err := m.station(category, name)
+	if err != nil {
+		return err
+	}
+	oldURL := s.playURL
+	s.name = radio.Name
+	s.playURL = radio.PlayURL
+	if radio.Name != name || radio.PlayURL != oldURL {
+		radio.ID = ""
+		m.updateStationRefs(radio, model.URLStationID(oldURL), radio.StationID())
+	}
+	return nil
+}
+
+// DeleteLocalStation 删除本地电台
+func (m *Memory) DeleteLocalStation(category, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, err := m.station(category, name)
+	if err != nil {
+		return err
+	}
+	id := s.id
+	stations := m.stations[:0]
+	for _, s := range m.stations {
+		if s.id != id {
+			stations = append(stations, s)
+		}
+	}
+	m.stations = stations
+	return nil
+}
+
+// MoveLocalStation 在分类内将电台向前（delta < 0）或向后移动
+func (m *Memory) MoveLocalStation(category, name string, delta int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var ordered []memoryStation
+	if c := m.category(category); c != nil {
+		ordered = m.sortedStations(c.id)
+	}
+	names := make([]string, len(ordered))
+	for i, s := range ordered {
+		names[i] = s.name
+	}
+	i := indexOf(names, name)
+	if i < 0 {
+		return fmt.Errorf("item not found in local_stations")
+	}
+	j := i + delta
+	if j < 0 || j >= len(ordered) {
+		return nil
+	}
+	ordered[i], ordered[j] = ordered[j], ordered[i]
+	for pos, o := range ordered {
+		for k := range m.stations {
+			if m.stations[k].id == o.id {
+				m.stations[k].position = pos + 1
+			}
+		}
+	}
+	return nil
+}
+
+// MoveLocalStationTo 将电台移动到另一个本地分类的末尾，分类不存在时自动创建
+func (m *Memory) MoveLocalStationTo(category, name, target string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, err := m.station(category, name)
+	if err != nil {
+		return err
+	}
+	id := s.id
+	targetID := m.addCategory(target)
+	s = m.stationByID(id)
+	s.position = m.maxStationPosition(targetID) + 1
+	s.categoryID = targetID
+	return nil
+}
+
+func (m *Memory) category(name string) *memoryCategory {
+	for i := range m.categories {
+		if m.categories[i].name == name {
+			return &m.categories[i]
+		}
+	}
+	return nil
+}
+
+func (m *Memory) addCategory(name string) int64 {
+	if c := m.category(name); c != nil {
+		return c.id
+	}
+	max := 0
+	for _, c := range m.categories {
+		if c.position > max {
+			max = c.position
+		}
+	}
+	id := m.newID()
+	m.categories = append(m.categories, memoryCategory{id: id, name: name, position: max + 1})
+	return id
+}
+
+func (m *Memory) addStation(categoryID int64, radio model.Radio) {
+	m.stations = append(m.stations, memoryStation{
+		id:         m.newID(),
+		categoryID: categoryID,
+		name:       radio.Name,
+		playURL:    radio.PlayURL,
+		position:   m.maxStationPosition(categoryID) + 1,
+	})
+}
+
+// station 返回本地分类中指定名称的电台，同名时取排在最前的
+func (m *Memory) station(category, name string) (*memoryStation, error) {
+	if c := m.category(category); c != nil {
+		for _, s := range m.sortedStations(c.id) {
+			if s.name == name {
+				return m.stationByID(s.id), nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("local station not found: %s/%s", category, name)
+}
+
+func (m *Memory) stationByID(id int64) *memoryStation {
+	for i := range m.stations {
+		if m.stations[i].id == id {
+			return &m.stations[i]
+		}
+	}
+	return nil
+}
+
+func (m *Memory) maxStationPosition(categoryID int64) int {
+	max := 0
+	for _, s := range m.stations {
+		if s.categoryID == categoryID && s.position > max {
+			max = s.position
+		}
+	}
+	return max
+}
+
+func (m *Memory) sortedCategories() []memoryCategory {
+	categories := append([]memoryCategory(nil), m.categories...)
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].position != categories[j].position {
+			return categories[i].position < categories[j].position
+		}
+		return categories[i].id < categories[j].id
+	})
+	return categories
+}
+
+func (m *Memory) sortedStations(categoryID int64) []memoryStation {
+	var stations []memoryStation
+	for _, s := range m.stations {
+		if s.categoryID == categoryID {
+			stations = append(stations, s)
+		}
+	}
+	sort.Slice(stations, func(i, j int) bool {
+		if stations[i].position != stations[j].position {
+			return stations[i].position < stations[j].position
+		}
+		return stations[i].id < stations[j].id
+	})
+	return stations
+}
+
+// updateStationRefs 将收藏与历史记录中指向 oldIDs 的记录更新为 radio，规则同 updateStationRefs
+func (m *Memory) updateStationRefs(radio model.Radio, oldIDs ...string) {
+	newID := radio.StationID()
+	for _, oldID := range oldIDs {
+		if f := m.favorite(oldID); f != nil && (oldID == newID || m.favorite(newID) == nil) {
+			f.ID = newID
+			f.Name = radio.Name
+			f.PlayURL = radio.PlayURL
+		}
+		for i := range m.sessions {
+			if s := &m.sessions[i]; s.StationID == oldID {
+				s.StationID = newID
+				s.RadioName = radio.Name
+			}
+		}
+		for i := range m.streams {
+			if s := &m.streams[i]; s.StationID == oldID {
+				s.StationID = newID
+				s.RadioName = radio.Name
+			}
+		}
+		if setting, ok := m.eq[oldID]; ok {
+			if _, exists := m.eq[newID]; oldID == newID || !exists {
+				delete(m.eq, oldID)
+				setting.StationID = newID
+				setting.RadioName = radio.Name
+				m.eq[newID] = setting
+			}
+		}
+		if oldID == newID {
+			continue
+		}
+		for key, bytes := range m.usage {
+			if key.stationID == oldID {
+				delete(m.usage, key)
+				m.usage[memoryUsageKey{month: key.month, stationID: newID}] += bytes
+			}
+		}
+	}
+}
+
+// AddStreamStats 保存一次播放会话的网络统计摘要
+func (m *Memory) AddStreamStats(s model.StreamStats) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s.ID = m.newID()
+	m.streams = append(m.streams, s)
+	return nil
+}
+
+// GetFlakyStations 按欠载、重连和错误次数汇总，返回最不稳定的电台
+func (m *Memory) GetFlakyStations(limit int) ([]model.StationHealth, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var stations []model.StationHealth
+	index := make(map[string]int)
+	latest := make(map[string]time.Time)
+	for _, s := range m.streams {
+		i, ok := index[s.StationID]
+		if !ok {
+			i = len(stations)
+			index[s.StationID] = i
+			stations = append(stations, model.StationHealth{StationID: s.StationID})
+		}
+		h := &stations[i]
+		// 电台名称取最近一次播放时的名称
+		if !s.StartedAt.Before(latest[s.StationID]) {
+			latest[s.StationID] = s.StartedAt
+			h.RadioName = s.RadioName
+		}
+		h.Sessions++
+		h.Underruns += s.Underruns
+		h.Reconnects += s.Reconnects
+		h.Errors += s.Errors
+	}
+	problems := func(h model.StationHealth) int {
+		return h.Underruns + h.Reconnects + h.Errors
+	}
+	flaky := stations[:0]
+	for _, h := range stations {
+		if problems(h) > 0 {
+			flaky = append(flaky, h)
+		}
+	}
+	sort.SliceStable(flaky, func(i, j int) bool {
+		return problems(flaky[i]) > problems(flaky[j])
+	})
+	if limit >= 0 && len(flaky) > limit {
+		flaky = flaky[:limit]
+	}
+	if len(flaky) == 0 {
+		return nil, nil
+	}
+	return flaky, nil
+}
+
+// AddDataUsage 累加电台在指定时间所在月份的流量使用量
+func (m *Memory) AddDataUsage(at time.Time, stationID string, bytes int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.usage[memoryUsageKey{month: at.Format("2006-01"), stationID: stationID}] += bytes
+	return nil
+}
+
+// GetMonthlyDataUsage 获取指定时间所在月份全部电台的流量使用量
+func (m *Memory) GetMonthlyDataUsage(at time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var bytes int64
+	for key, b := range m.usage {
+		if key.month == at.Format("2006-01") {
+			bytes += b
+		}
+	}
+	return bytes, nil
+}
+
+// SaveStationEQ 保存电台的均衡器设置
+func (m *Memory) SaveStationEQ(setting model.EQSetting) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	setting.Gains = append([]float64(nil), setting.Gains...)
+	m.eq[setting.StationID] = setting
+	return nil
+}
+
+// GetStationEQ 获取电台的均衡器设置，未设置时返回 nil
+func (m *Memory) GetStationEQ(radio model.Radio) (*model.EQSetting, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	setting, ok := m.eq[radio.StationID()]
+	if !ok {
+		return nil, nil
+	}
+	setting.Gains = append([]float64(nil), setting.Gains...)
+	return &setting, nil
+}
+
+// GetSetting 返回设置项的值，未设置时 ok 为 false
+func (m *Memory) GetSetting(key string) (value string, ok bool, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	value, ok = m.settings[key]
+	return value, ok, nil
+}
+
+// SetSetting 保存设置项
+func (m *Memory) SetSetting(key, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.settings[key] = value
+	return nil
+}
+
+// SetSettings 保存多个设置项
+func (m *Memory) SetSettings(values map[string]string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, value := range values {
+		m.settings[key] = value
+	}
+	return nil
+}
+
+// GetSettings 返回全部设置项
+func (m *Memory) GetSettings() (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	settings := make(map[string]string, len(m.settings))
+	for key, value := range m.settings {
+		settings[key] = value
+	}
+	return settings, nil
+}
+
+// CacheDirectoryStations 缓存在线目录返回的电台，同一 UUID 的电台被覆盖
+func (m *Memory) CacheDirectoryStations(stations []model.DirectoryStation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for _, s := range stations {
+		s.FetchedAt = now
+		m.directory[s.UUID] = s
+	}
+	return nil
+}
+
+// SearchDirectoryCache 在缓存中按条件搜索电台，规则同 Database.SearchDirectoryCache
+func (m *Memory) SearchDirectoryCache(q model.DirectoryQuery, orderBy string) ([]model.DirectoryStation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	like := func(value, pattern string) bool {
+		return pattern == "" || strings.Contains(strings.ToLower(value), strings.ToLower(pattern))
+	}
+	var stations []model.DirectoryStation
+	for _, s := range m.sortedDirectory() {
+		if like(s.Name, q.Name) && like(s.Country, q.Country) && like(s.Language, q.Language) &&
+			like(s.Tags, q.Tag) && like(s.Codec, q.Codec) && s.Bitrate >= q.BitrateMin {
+			stations = append(stations, s)
+		}
+	}
+	rank := func(s model.DirectoryStation) int {
+		if orderBy == "click_count" {
+			return s.ClickCount
+		}
+		return s.Votes
+	}
+	sort.SliceStable(stations, func(i, j int) bool {
+		return rank(stations[i]) > rank(stations[j])
+	})
+	limit := q.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	if len(stations) > limit {
+		stations = stations[:limit]
+	}
+	return stations, nil
+}
+
+// GetDirectoryStationByURL 按播放地址查找缓存中的电台，未找到时返回 nil
+func (m *Memory) GetDirectoryStationByURL(url string) (*model.DirectoryStation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, s := range m.sortedDirectory() {
+		if s.URLResolved == url || s.URL == url {
+			return &model.DirectoryStation{UUID: s.UUID, Name: s.Name, URL: s.URL, URLResolved: s.URLResolved}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *Memory) sortedDirectory() []model.DirectoryStation {
+	stations := make([]model.DirectoryStation, 0, len(m.directory))
+	for _, s := range m.directory {
+		stations = append(stations, s)
+	}
+	sort.Slice(stations, func(i, j int) bool { return stations[i].UUID < stations[j].UUID })
+	return stations
+}
+
+// SyncExternalStations 将外部目录的电台同步到本地目录，规则同 Database.SyncExternalStations
+func (m *Memory) SyncExternalStations(source string, stations []model.ExternalStation) (*model.SyncReport, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	known := make(map[string]int64)
+	for _, s := range m.stations {
+		if s.source == source && s.externalID != "" {
+			known[s.externalID] = s.id
+		}
+	}
+
+	now := time.Now()
+	report := &model.SyncReport{Source: source, SyncedAt: now}
+	seen := make(map[string]bool)
+	for _, e := range stations {
+		if seen[e.ExternalID] {
+			continue
+		}
+		seen[e.ExternalID] = true
+		categoryID := m.addCategory(e.Category)
+
+		id, ok := known[e.ExternalID]
+		if !ok {
+			m.addStation(categoryID, model.Radio{Name: e.Name, PlayURL: e.PlayURL})
+			s := &m.stations[len(m.stations)-1]
+			s.source, s.externalID = source, e.ExternalID
+			s.province, s.logo, s.program = e.Province, e.Logo, e.Program
+			report.Added = append(report.Added, e.Name)
+			continue
+		}
+
+		s := m.stationByID(id)
+		if s.name != e.Name {
+			report.Renamed = append(report.Renamed, model.Rename{Old: s.name, New: e.Name})
+		}
+		if s.name != e.Name || s.playURL != e.PlayURL {
+			radio := model.Radio{ID: syncedStationID(source, e.ExternalID), Name: e.Name, PlayURL: e.PlayURL}
+			m.updateStationRefs(radio, radio.ID, model.URLStationID(s.playURL))
+		}
+		if s.removed {
+			report.Restored = append(report.Restored, e.Name)
+		}
+		s.categoryID = categoryID
+		s.name, s.playURL = e.Name, e.PlayURL
+		s.province, s.logo, s.program = e.Province, e.Logo, e.Program
+		s.removed = false
+		report.Updated++
+	}
+
+	for externalID, id := range known {
+		if s := m.stationByID(id); !seen[externalID] && !s.removed {
+			s.removed = true
+			report.Removed = append(report.Removed, s.name)
+		}
+	}
+	m.syncedAt[source] = now
+	return report, nil
+}
+
+// LastSyncedAt 返回外部目录上次同步的时间，从未同步时返回零值
+func (m *Memory) LastSyncedAt(source string) (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.syncedAt[source], nil
+}
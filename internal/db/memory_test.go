@@ -0,0 +1,12 @@
+package db_test
+
+import (
+	"testing"
+
+	"FMgo/internal/db"
+	"FMgo/internal/db/storetest"
+)
+
+func TestMemory(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) db.Store { return db.NewMemory() })
+}
@@ -0,0 +1,228 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"FMgo/internal/logger"
+)
+
+// migration 是一次数据库结构升级，按 version 顺序在事务中执行
+type migration struct {
+	version int
+	name    string
+	up      func(tx *sql.Tx) error
+}
+
+// migrations 是全部结构升级，只能在末尾追加，已发布的升级不可修改
+var migrations = []migration{
+	{1, "initial schema", migrateInitialSchema},
+	{2, "station ids", migrateStationIDs},
+	{3, "listening sessions", migrateListeningSessions},
+	{4, "favorite folders", migrateFavoriteFolders},
+	{5, "favorite sync", migrateFavoriteSync},
+	{6, "history retention", migrateHistoryRetention},
+	{7, "settings", migrateSettings},
+}
+
+// SchemaVersion 返回程序支持的最新数据库结构版本
+func SchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// migrate 将数据库升级到最新结构。有待执行的升级且数据库中已有表时，在任何写入之前
+// 先将其备份到 <path>.v<当前版本>.bak，path 为空时不备份。
+// 每个升级在单独的事务中执行并记录到 schema_version 表
+func migrate(db *sql.DB, path string) error {
+	current, err := schemaVersion(db)
+	if err != nil {
+		return err
+	}
+	if current > SchemaVersion() {
+		return fmt.Errorf("database schema version %d is newer than supported version %d", current, SchemaVersion())
+	}
+	if current == SchemaVersion() {
+		return nil
+	}
+
+	var tables int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table'`).Scan(&tables); err != nil {
+		return fmt.Errorf("failed to inspect database: %v", err)
+	}
+	if path != "" && tables > 0 {
+		backup := fmt.Sprintf("%s.v%d.bak", path, current)
+		if err := backupTo(db, backup); err != nil {
+			return fmt.Errorf("failed to back up database before migration: %v", err)
+		}
+		logger.Info("数据库升级前已备份到 %s", backup)
+	}
+
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at DATETIME NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create schema_version table: %v", err)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := applyMigration(db, m); err != nil {
+			return err
+		}
+		logger.Info("数据库已升级到版本 %d (%s)", m.version, m.name)
+	}
+	return nil
+}
+
+// schemaVersion 返回数据库当前的结构版本，从未升级过（没有 schema_version 表）时为 0
+func schemaVersion(db *sql.DB) (int, error) {
+	var tables int
+	if err := db.QueryRow(`
+		SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_version'
+	`).Scan(&tables); err != nil {
+		return 0, fmt.Errorf("failed to get schema version: %v", err)
+	}
+	if tables == 0 {
+		return 0, nil
+	}
+	var version int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to get schema version: %v", err)
+	}
+	return version, nil
+}
+
+func applyMigration(db *sql.DB, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %d: %v", m.version, err)
+	}
+	defer tx.Rollback()
+
+	if err := m.up(tx); err != nil {
+		return fmt.Errorf("migration %d (%s) failed: %v", m.version, m.name, err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)
+	`, m.version, m.name, time.Now()); err != nil {
+		return fmt.Errorf("failed to record migration %d: %v", m.version, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d: %v", m.version, err)
+	}
+	return nil
+}
+
+// migrateInitialSchema 创建引入版本管理之前的表结构。已有数据库中的表保持不变
+func migrateInitialSchema(tx *sql.Tx) error {
+	tables := []struct {
+		name string
+		ddl  string
+	}{
+		// 历史记录表
+		{"history", `
+			CREATE TABLE IF NOT EXISTS history (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				radio_name TEXT NOT NULL,
+				play_url TEXT NOT NULL,
+				played_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			)`},
+		// 收藏表
+		{"favorites", `
+			CREATE TABLE IF NOT EXISTS favorites (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				radio_name TEXT NOT NULL UNIQUE,
+				play_url TEXT NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			)`},
+		// 播放会话网络统计表
+		{"stream_stats", `
+			CREATE TABLE IF NOT EXISTS stream_stats (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				radio_name TEXT NOT NULL,
+				play_url TEXT NOT NULL,
+				started_at DATETIME NOT NULL,
+				ended_at DATETIME NOT NULL,
+				bytes_received INTEGER NOT NULL DEFAULT 0,
+				segments INTEGER NOT NULL DEFAULT 0,
+				avg_latency_ms INTEGER NOT NULL DEFAULT 0,
+				avg_throughput REAL NOT NULL DEFAULT 0,
+				underruns INTEGER NOT NULL DEFAULT 0,
+				reconnects INTEGER NOT NULL DEFAULT 0,
+				errors INTEGER NOT NULL DEFAULT 0
+			)`},
+		// 月度流量统计表
+		{"data_usage", `
+			CREATE TABLE IF NOT EXISTS data_usage (
+				month TEXT PRIMARY KEY,
+				bytes INTEGER NOT NULL DEFAULT 0
+			)`},
+		// 电台均衡器设置表
+		{"station_eq", `
+			CREATE TABLE IF NOT EXISTS station_eq (
+				radio_name TEXT PRIMARY KEY,
+				preset TEXT NOT NULL,
+				gains TEXT NOT NULL
+			)`},
+		// 本地电台目录表
+		{"local_categories", `
+			CREATE TABLE IF NOT EXISTS local_categories (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT NOT NULL UNIQUE,
+				position INTEGER NOT NULL DEFAULT 0
+			)`},
+		{"local_stations", `
+			CREATE TABLE IF NOT EXISTS local_stations (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				category_id INTEGER NOT NULL,
+				name TEXT NOT NULL,
+				play_url TEXT NOT NULL,
+				position INTEGER NOT NULL DEFAULT 0,
+				source TEXT NOT NULL DEFAULT 'user',
+				external_id TEXT NOT NULL DEFAULT '',
+				province TEXT NOT NULL DEFAULT '',
+				logo TEXT NOT NULL DEFAULT '',
+				program TEXT NOT NULL DEFAULT '',
+				synced_at DATETIME,
+				removed_at DATETIME,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			)`},
+		// 外部目录同步状态表
+		{"sync_state", `
+			CREATE TABLE IF NOT EXISTS sync_state (
+				source TEXT PRIMARY KEY,
+				synced_at DATETIME NOT NULL
+			)`},
+		// 在线目录缓存表
+		{"directory_cache", `
+			CREATE TABLE IF NOT EXISTS directory_cache (
+				uuid TEXT PRIMARY KEY,
+				name TEXT NOT NULL,
+				url TEXT NOT NULL,
+				url_resolved TEXT NOT NULL DEFAULT '',
+				homepage TEXT NOT NULL DEFAULT '',
+				favicon TEXT NOT NULL DEFAULT '',
+				tags TEXT NOT NULL DEFAULT '',
+				country TEXT NOT NULL DEFAULT '',
+				country_code TEXT NOT NULL DEFAULT '',
+				language TEXT NOT NULL DEFAULT '',
+				codec TEXT NOT NULL DEFAULT '',
+				bitrate INTEGER NOT NULL DEFAULT 0,
+				votes INTEGER NOT NULL DEFAULT 0,
+				click_count INTEGER NOT NULL DEFAULT 0,
+				fetched_at DATETIME NOT NULL
+			)`},
+	}
+	for _, t := range tables {
+		if _, err := tx.Exec(t.ddl); err != nil {
+			return fmt.Errorf("failed to create %s table: %v", t.name, err)
+		}
+	}
+	return nil
+}
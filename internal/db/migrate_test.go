@@ -0,0 +1,208 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"FMgo/internal/model"
+)
+
+// baselineSchema 是引入 schema_version 之前的表结构与数据：
+// 同一地址以不同名称收藏过两次，均衡器设置按电台名称保存
+const baselineSchema = `
+	CREATE TABLE history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		radio_name TEXT NOT NULL,
+		play_url TEXT NOT NULL,
+		played_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE TABLE favorites (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		radio_name TEXT NOT NULL UNIQUE,
+		play_url TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE TABLE station_eq (
+		radio_name TEXT PRIMARY KEY,
+		preset TEXT NOT NULL,
+		gains TEXT NOT NULL
+	);
+	INSERT INTO history (radio_name, play_url, played_at) VALUES
+		('中国之声', 'http://example.com/cnr1.m3u8', '2024-01-01 08:00:00'),
+		('音乐之声', 'http://example.com/music.m3u8', '2024-01-01 09:00:00'),
+		('中国之声', 'http://example.com/cnr1.m3u8', '2024-01-02 08:00:00');
+	INSERT INTO favorites (radio_name, play_url, created_at) VALUES
+		('中国之声', 'http://example.com/cnr1.m3u8', '2024-01-01 08:00:00'),
+		('央广中国之声', 'http://example.com/cnr1.m3u8', '2024-01-03 08:00:00'),
+		('音乐之声', 'http://example.com/music.m3u8', '2024-01-02 08:00:00');
+	INSERT INTO station_eq (radio_name, preset, gains) VALUES
+		('音乐之声', 'rock', '[3,1,0,1,3]'),
+		('已删除的电台', 'vocal', '[0,2,3,2,0]');
+`
+
+func TestMigrateBaseline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fmgo.db")
+	raw, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := raw.Exec(baselineSchema); err != nil {
+		t.Fatal(err)
+	}
+	raw.Close()
+
+	raw, err = sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := dumpDatabase(t, raw)
+	raw.Close()
+
+	d, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if version, err := schemaVersion(d.db); err != nil || version != SchemaVersion() {
+		t.Fatalf("schema version = %d, %v, want %d", version, err, SchemaVersion())
+	}
+
+	// 备份在任何写入之前完成，与升级前的数据库完全一致
+	if _, err := os.Stat(path + ".v0.bak"); err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+	backup, err := sql.Open("sqlite3", path+".v0.bak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := dumpDatabase(t, backup); !reflect.DeepEqual(got, original) {
+		t.Fatalf("backup differs from original:\nbackup   %v\noriginal %v", got, original)
+	}
+	backup.Close()
+
+	cnr := model.Radio{Name: "央广中国之声", PlayURL: "http://example.com/cnr1.m3u8"}
+	music := model.Radio{Name: "音乐之声", PlayURL: "http://example.com/music.m3u8"}
+
+	favorites, err := d.GetFavorites()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range favorites {
+		names = append(names, f.Name)
+		if f.ID != model.URLStationID(f.PlayURL) {
+			t.Errorf("favorite %s id = %s", f.Name, f.ID)
+		}
+	}
+	sort.Strings(names)
+	if want := []string{cnr.Name, music.Name}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("favorites = %v, want %v", names, want)
+	}
+
+	history, err := d.GetHistory(-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 3 {
+		t.Fatalf("history = %d sessions, want 3", len(history))
+	}
+	for _, h := range history {
+		if h.StationID != model.URLStationID(h.PlayURL) || h.EndedAt == nil {
+			t.Errorf("session = %+v", h)
+		}
+	}
+	var tables int
+	if err := d.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'history'`).Scan(&tables); err != nil || tables != 0 {
+		t.Fatalf("history table still exists: %d, %v", tables, err)
+	}
+
+	if setting, err := d.GetStationEQ(music); err != nil || setting == nil || setting.Preset != "rock" ||
+		setting.StationID != music.StationID() {
+		t.Fatalf("station eq = %+v, %v", setting, err)
+	}
+	// 找不到对应电台的设置仍可按名称读取
+	if setting, err := d.GetStationEQ(model.Radio{Name: "已删除的电台", PlayURL: "http://example.com/gone"}); err != nil ||
+		setting == nil || setting.Preset != "vocal" {
+		t.Fatalf("legacy station eq = %+v, %v", setting, err)
+	}
+
+	before := dumpDatabase(t, d.db)
+	d.Close()
+
+	// 已是最新结构时再次打开不做任何修改
+	d, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer d.Close()
+	if after := dumpDatabase(t, d.db); !reflect.DeepEqual(before, after) {
+		t.Fatalf("reopen changed database:\nbefore %v\nafter  %v", before, after)
+	}
+	backups, _ := filepath.Glob(path + ".v*.bak")
+	if len(backups) != 1 {
+		t.Fatalf("backups = %v", backups)
+	}
+}
+
+func TestMigrateNewDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fmgo.db")
+	d, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer d.Close()
+	if version, err := schemaVersion(d.db); err != nil || version != SchemaVersion() {
+		t.Fatalf("schema version = %d, %v, want %d", version, err, SchemaVersion())
+	}
+	// 新数据库没有需要保留的数据，不备份
+	if backups, _ := filepath.Glob(path + ".v*.bak"); len(backups) != 0 {
+		t.Fatalf("backups = %v", backups)
+	}
+}
+
+// dumpDatabase 返回全部表结构与数据，用于比较数据库是否被修改
+func dumpDatabase(t *testing.T, db *sql.DB) []string {
+	t.Helper()
+	rows, err := db.Query(`SELECT name, sql FROM sqlite_master WHERE sql IS NOT NULL ORDER BY name`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tables, dump []string
+	for rows.Next() {
+		var name, ddl string
+		if err := rows.Scan(&name, &ddl); err != nil {
+			t.Fatal(err)
+		}
+		dump = append(dump, ddl)
+		if strings.HasPrefix(ddl, "CREATE TABLE") {
+			tables = append(tables, name)
+		}
+	}
+	rows.Close()
+
+	for _, table := range tables {
+		rows, err := db.Query(`SELECT * FROM "` + table + `"`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		columns, _ := rows.Columns()
+		for rows.Next() {
+			values := make([]interface{}, len(columns))
+			ptrs := make([]interface{}, len(columns))
+			for i := range values {
+				ptrs[i] = &values[i]
+			}
+			if err := rows.Scan(ptrs...); err != nil {
+				t.Fatal(err)
+			}
+			dump = append(dump, fmt.Sprintf("%s%v", table, values))
+		}
+		rows.Close()
+	}
+	return dump
+}
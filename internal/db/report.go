@@ -0,0 +1,225 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"FMgo/internal/model"
+)
+
+const (
+	// reportTopLimit 是统计报告中排行榜的电台数量
+	reportTopLimit = 10
+	// UncategorizedName 是统计报告中不在目录里的电台所归入的分类
+	UncategorizedName = "其他"
+)
+
+// ListeningReport 根据收听会话生成统计报告。days 为总时长、时段分布与分类统计覆盖的天数（0 为全部），
+// 排行榜固定为本周与本月，连续收听天数按全部记录计算。
+// 电台归入 categories 中第一个包含它的分类
+func (d *Database) ListeningReport(now time.Time, days int, categories []model.Category) (*model.ListeningReport, error) {
+	rows, err := d.db.Query(`
+		SELECT station_id, radio_name, started_at, listened_ms
+		FROM listening_sessions
+		WHERE listened_ms > 0
+		ORDER BY started_at, id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get listening sessions: %v", err)
+	}
+	defer rows.Close()
+
+	var sessions []model.PlayHistory
+	for rows.Next() {
+		var s model.PlayHistory
+		if err := rows.Scan(&s.StationID, &s.RadioName, &s.PlayedAt, &s.ListenedMs); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return buildReport(sessions, now, days, categories), nil
+}
+
+func buildReport(sessions []model.PlayHistory, now time.Time, days int, categories []model.Category) *model.ListeningReport {
+	report := &model.ListeningReport{GeneratedAt: now, Days: days}
+
+	today := startOfDay(now)
+	weekStart := today.AddDate(0, 0, -mondayIndex(today))
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	var periodStart time.Time
+	if days > 0 {
+		periodStart = today.AddDate(0, 0, -(days - 1))
+	}
+
+	// 同时按电台 ID 与播放地址生成的 ID 归类，兼容迁移前的记录
+	categoryOf := make(map[string]string)
+	for _, cat := range categories {
+		for _, radio := range cat.RadioList {
+			for _, id := range []string{radio.StationID(), model.URLStationID(radio.PlayURL)} {
+				if _, ok := categoryOf[id]; !ok {
+					categoryOf[id] = cat.Name
+				}
+			}
+		}
+	}
+
+	week := make(map[string]*stationTotal)
+	month := make(map[string]*stationTotal)
+	var total time.Duration
+	var byHour [24]time.Duration
+	var byWeekday [7]time.Duration
+	byCategory := make(map[string]time.Duration)
+	activeDays := make(map[string]bool)
+
+	for _, s := range sessions {
+		start := s.PlayedAt.In(now.Location())
+		listened := s.Listened()
+		activeDays[start.Format("2006-01-02")] = true
+		if !start.Before(weekStart) {
+			addStationTotal(week, s)
+		}
+		if !start.Before(monthStart) {
+			addStationTotal(month, s)
+		}
+		if start.Before(periodStart) {
+			continue
+		}
+
+		total += listened
+		spreadByHour(start, listened, func(t time.Time, d time.Duration) {
+			byHour[t.Hour()] += d
+			byWeekday[mondayIndex(t)] += d
+		})
+		category, ok := categoryOf[s.StationID]
+		if !ok {
+			category = UncategorizedName
+		}
+		byCategory[category] += listened
+	}
+
+	report.TotalSeconds = int64(total.Seconds())
+	for i, d := range byHour {
+		report.ByHour[i] = int64(d.Seconds())
+	}
+	for i, d := range byWeekday {
+		report.ByWeekday[i] = int64(d.Seconds())
+	}
+	report.TopWeek = topStations(week)
+	report.TopMonth = topStations(month)
+	for name, d := range byCategory {
+		report.Categories = append(report.Categories, model.CategoryTotal{Category: name, ListenedSeconds: int64(d.Seconds())})
+	}
+	sort.Slice(report.Categories, func(i, j int) bool {
+		a, b := report.Categories[i], report.Categories[j]
+		if a.ListenedSeconds != b.ListenedSeconds {
+			return a.ListenedSeconds > b.ListenedSeconds
+		}
+		return a.Category < b.Category
+	})
+	report.CurrentStreak, report.LongestStreak = streaks(activeDays, today)
+	return report
+}
+
+// stationTotal 累计一个电台的收听会话，名称取最近一次会话的名称
+type stationTotal struct {
+	model.StationTotal
+	listened time.Duration
+}
+
+func addStationTotal(totals map[string]*stationTotal, s model.PlayHistory) {
+	t, ok := totals[s.StationID]
+	if !ok {
+		t = &stationTotal{StationTotal: model.StationTotal{StationID: s.StationID}}
+		totals[s.StationID] = t
+	}
+	t.RadioName = s.RadioName
+	t.Sessions++
+	t.listened += s.Listened()
+}
+
+// topStations 返回收听时长最长的电台
+func topStations(totals map[string]*stationTotal) []model.StationTotal {
+	list := make([]*stationTotal, 0, len(totals))
+	for _, t := range totals {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].listened != list[j].listened {
+			return list[i].listened > list[j].listened
+		}
+		return list[i].StationID < list[j].StationID
+	})
+	if len(list) > reportTopLimit {
+		list = list[:reportTopLimit]
+	}
+	top := make([]model.StationTotal, 0, len(list))
+	for _, t := range list {
+		t.ListenedSeconds = int64(t.listened.Seconds())
+		top = append(top, t.StationTotal)
+	}
+	return top
+}
+
+// spreadByHour 将从 start 开始、持续 d 的收听按整点切分，依次回调每一段的开始时间与时长
+func spreadByHour(start time.Time, d time.Duration, fn func(t time.Time, d time.Duration)) {
+	for d > 0 {
+		next := time.Date(start.Year(), start.Month(), start.Day(), start.Hour(), 0, 0, 0, start.Location()).Add(time.Hour)
+		chunk := next.Sub(start)
+		if chunk > d {
+			chunk = d
+		}
+		fn(start, chunk)
+		start = next
+		d -= chunk
+	}
+}
+
+// streaks 返回截至 today 的连续收听天数（今天尚未收听时从昨天算起）与最长连续天数
+func streaks(activeDays map[string]bool, today time.Time) (current, longest int) {
+	dates := make([]string, 0, len(activeDays))
+	for day := range activeDays {
+		dates = append(dates, day)
+	}
+	sort.Strings(dates)
+
+	run := 0
+	var prev time.Time
+	for _, date := range dates {
+		day, err := time.ParseInLocation("2006-01-02", date, today.Location())
+		if err != nil {
+			continue
+		}
+		if run > 0 && prev.AddDate(0, 0, 1).Equal(day) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+		prev = day
+	}
+
+	day := today
+	if !activeDays[day.Format("2006-01-02")] {
+		day = day.AddDate(0, 0, -1)
+	}
+	for activeDays[day.Format("2006-01-02")] {
+		current++
+		day = day.AddDate(0, 0, -1)
+	}
+	return current, longest
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// mondayIndex 返回星期几的序号，周一为 0
+func mondayIndex(t time.Time) int {
+	return (int(t.Weekday()) + 6) % 7
+}
@@ -0,0 +1,203 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// allStations 是 history_clears 中表示清除全部电台历史的键
+const allStations = ""
+
+// migrateHistoryRetention 记录清除历史的范围与数据库维护时间。
+// history_clears 中的 cleared_at 表示该电台（station_id 为空时为全部电台）
+// 在此时间及之前开始的收听会话已被删除，合并同步文件时不再导入
+func migrateHistoryRetention(tx *sql.Tx) error {
+	if _, err := tx.Exec(`
+		CREATE TABLE history_clears (
+			station_id TEXT PRIMARY KEY,
+			cleared_at DATETIME NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create history_clears table: %v", err)
+	}
+	if _, err := tx.Exec(`
+		CREATE TABLE maintenance (
+			task TEXT PRIMARY KEY,
+			ran_at DATETIME NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create maintenance table: %v", err)
+	}
+	return nil
+}
+
+// ClearHistory 删除全部收听记录
+func (d *Database) ClearHistory() error {
+	return d.clearHistory(allStations, time.Now())
+}
+
+// DeleteStationHistory 删除一个电台的全部收听记录
+func (d *Database) DeleteStationHistory(stationID string) error {
+	return d.clearHistory(stationID, time.Now())
+}
+
+// PruneHistory 按保留策略删除已结束的收听记录：开始时间早于 maxAge 之前的，
+// 以及最近 maxRows 条之外的。maxAge 或 maxRows 为 0 时不按该项限制，返回删除的条数。
+// 未结束的会话不计入保留条数，也不会被删除
+func (d *Database) PruneHistory(now time.Time, maxAge time.Duration, maxRows int) (int64, error) {
+	var cutoff time.Time
+	if maxAge > 0 {
+		cutoff = now.Add(-maxAge)
+	}
+	if maxRows > 0 {
+		// 第 maxRows+1 新的会话及更早的会话都超出保留条数
+		var startedAt time.Time
+		err := d.db.QueryRow(`
+			SELECT started_at FROM listening_sessions
+			WHERE ended_at IS NOT NULL
+			ORDER BY julianday(started_at) DESC, id DESC
+			LIMIT 1 OFFSET ?
+		`, maxRows).Scan(&startedAt)
+		if err != nil && err != sql.ErrNoRows {
+			return 0, fmt.Errorf("failed to apply history retention: %v", err)
+		}
+		if startedAt.After(cutoff) {
+			cutoff = startedAt
+		}
+	}
+	if cutoff.IsZero() {
+		return 0, nil
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	// 未结束的会话仍在收听中，不在保留策略的删除范围内
+	result, err := tx.Exec(`
+		DELETE FROM listening_sessions WHERE ended_at IS NOT NULL AND julianday(started_at) <= julianday(?)
+	`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to apply history retention: %v", err)
+	}
+	count, err := result.RowsAffected()
+	if err != nil || count == 0 {
+		return 0, err
+	}
+	if err := addHistoryClear(tx, allStations, cutoff); err != nil {
+		return 0, err
+	}
+	return count, tx.Commit()
+}
+
+// clearHistory 删除 stationID 在 before 及之前开始的收听记录并记下清除范围
+func (d *Database) clearHistory(stationID string, before time.Time) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := addHistoryClear(tx, stationID, before); err != nil {
+		return err
+	}
+	if _, err := applyHistoryClear(tx, stationID, before); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// addHistoryClear 记录清除范围，已有更晚的清除记录时保持不变
+func addHistoryClear(tx *sql.Tx, stationID string, before time.Time) error {
+	var current time.Time
+	err := tx.QueryRow(`SELECT cleared_at FROM history_clears WHERE station_id = ?`, stationID).Scan(&current)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get history clear: %v", err)
+	}
+	if err == nil && !before.After(current) {
+		return nil
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO history_clears (station_id, cleared_at) VALUES (?, ?)
+		ON CONFLICT(station_id) DO UPDATE SET cleared_at = excluded.cleared_at
+	`, stationID, before); err != nil {
+		return fmt.Errorf("failed to record history clear: %v", err)
+	}
+	return nil
+}
+
+// applyHistoryClear 删除清除范围内的收听记录，返回删除的条数。
+// 时间按 julianday 比较，不受记录时所用时区的影响
+func applyHistoryClear(tx *sql.Tx, stationID string, before time.Time) (int64, error) {
+	query := `DELETE FROM listening_sessions WHERE julianday(started_at) <= julianday(?)`
+	args := []interface{}{before}
+	if stationID != allStations {
+		query += ` AND station_id = ?`
+		args = append(args, stationID)
+	}
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear history: %v", err)
+	}
+	return result.RowsAffected()
+}
+
+// loadHistoryClears 返回各电台的清除范围，键为空字符串表示全部电台
+func loadHistoryClears(tx *sql.Tx) (map[string]time.Time, error) {
+	rows, err := tx.Query(`SELECT station_id, cleared_at FROM history_clears`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load history clears: %v", err)
+	}
+	defer rows.Close()
+
+	clears := make(map[string]time.Time)
+	for rows.Next() {
+		var stationID string
+		var clearedAt time.Time
+		if err := rows.Scan(&stationID, &clearedAt); err != nil {
+			return nil, err
+		}
+		clears[stationID] = clearedAt
+	}
+	return clears, rows.Err()
+}
+
+// historyCleared 返回会话是否在清除范围内
+func historyCleared(clears map[string]time.Time, stationID string, startedAt time.Time) bool {
+	for _, key := range []string{allStations, stationID} {
+		if before, ok := clears[key]; ok && !startedAt.After(before) {
+			return true
+		}
+	}
+	return false
+}
+
+// Vacuum 整理数据库文件，回收删除记录后的空间
+func (d *Database) Vacuum() error {
+	if _, err := d.db.Exec(`VACUUM`); err != nil {
+		return fmt.Errorf("failed to vacuum database: %v", err)
+	}
+	if _, err := d.db.Exec(`
+		INSERT INTO maintenance (task, ran_at) VALUES ('vacuum', ?)
+		ON CONFLICT(task) DO UPDATE SET ran_at = excluded.ran_at
+	`, time.Now()); err != nil {
+		return fmt.Errorf("failed to record vacuum: %v", err)
+	}
+	return nil
+}
+
+// LastVacuumAt 返回上次整理数据库的时间，从未整理时返回零值
+func (d *Database) LastVacuumAt() (time.Time, error) {
+	var t time.Time
+	err := d.db.QueryRow(`SELECT ran_at FROM maintenance WHERE task = 'vacuum'`).Scan(&t)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get maintenance state: %v", err)
+	}
+	return t, nil
+}
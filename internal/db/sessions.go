@@ -0,0 +1,154 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"FMgo/internal/model"
+)
+
+// migrateListeningSessions 用收听会话表取代只记录开始时间的历史记录表，
+// 已有的历史记录迁移为时长未知的会话
+func migrateListeningSessions(tx *sql.Tx) error {
+	if _, err := tx.Exec(`
+		CREATE TABLE listening_sessions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			station_id TEXT NOT NULL,
+			radio_name TEXT NOT NULL,
+			play_url TEXT NOT NULL,
+			started_at DATETIME NOT NULL,
+			ended_at DATETIME,
+			listened_ms INTEGER NOT NULL DEFAULT 0,
+			end_reason TEXT NOT NULL DEFAULT '',
+			bytes_received INTEGER NOT NULL DEFAULT 0
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create listening_sessions table: %v", err)
+	}
+	if _, err := tx.Exec(`
+		CREATE INDEX idx_listening_sessions_station ON listening_sessions (station_id)
+	`); err != nil {
+		return fmt.Errorf("failed to create listening_sessions index: %v", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO listening_sessions (station_id, radio_name, play_url, started_at, ended_at)
+		SELECT station_id, radio_name, play_url, played_at, played_at FROM history ORDER BY id
+	`); err != nil {
+		return fmt.Errorf("failed to migrate history: %v", err)
+	}
+	if _, err := tx.Exec(`DROP TABLE history`); err != nil {
+		return fmt.Errorf("failed to drop history table: %v", err)
+	}
+	return nil
+}
+
+// StartSession 记录开始收听电台，返回会话 ID
+func (d *Database) StartSession(radio model.Radio, startedAt time.Time) (int64, error) {
+	result, err := d.db.Exec(`
+		INSERT INTO listening_sessions (station_id, radio_name, play_url, started_at)
+		VALUES (?, ?, ?, ?)
+	`, radio.StationID(), radio.Name, radio.PlayURL, startedAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start session: %v", err)
+	}
+	return result.LastInsertId()
+}
+
+// EndSession 记录收听结束：结束时间、收听时长（不含暂停）、结束原因与下载字节数
+func (d *Database) EndSession(id int64, endedAt time.Time, listened time.Duration, reason string, bytes int64) error {
+	if _, err := d.db.Exec(`
+		UPDATE listening_sessions
+		SET ended_at = ?, listened_ms = ?, end_reason = ?, bytes_received = ?
+		WHERE id = ?
+	`, endedAt, listened.Milliseconds(), reason, bytes, id); err != nil {
+		return fmt.Errorf("failed to end session: %v", err)
+	}
+	return nil
+}
+
+// CloseOpenSessions 结束上次运行时未正常结束（如程序崩溃）的会话，时长按未知记为 0
+func (d *Database) CloseOpenSessions() error {
+	if _, err := d.db.Exec(`
+		UPDATE listening_sessions SET ended_at = started_at, end_reason = ?
+		WHERE ended_at IS NULL
+	`, model.EndReasonExit); err != nil {
+		return fmt.Errorf("failed to close open sessions: %v", err)
+	}
+	return nil
+}
+
+// GetHistory 按开始时间倒序返回收听会话，limit 为 -1 时不限制条数
+func (d *Database) GetHistory(limit int) ([]model.PlayHistory, error) {
+	rows, err := d.db.Query(`
+		SELECT id, station_id, radio_name, play_url, started_at, ended_at, listened_ms, end_reason, bytes_received
+		FROM listening_sessions
+		ORDER BY started_at DESC, id DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history: %v", err)
+	}
+	defer rows.Close()
+
+	var history []model.PlayHistory
+	for rows.Next() {
+		var h model.PlayHistory
+		var endedAt sql.NullTime
+		if err := rows.Scan(&h.ID, &h.StationID, &h.RadioName, &h.PlayURL, &h.PlayedAt,
+			&endedAt, &h.ListenedMs, &h.EndReason, &h.BytesReceived); err != nil {
+			return nil, err
+		}
+		if endedAt.Valid {
+			h.EndedAt = &endedAt.Time
+		}
+		history = append(history, h)
+	}
+	return history, rows.Err()
+}
+
+// GetStationHistory 按电台汇总收听会话：会话数、总收听时长与最近播放时间，按最近播放倒序
+func (d *Database) GetStationHistory(limit int) ([]model.StationHistory, error) {
+	// 与 MAX() 一同查询的其他列取自最近一次会话
+	rows, err := d.db.Query(`
+		SELECT station_id, radio_name, play_url, COUNT(*), SUM(listened_ms), MAX(started_at)
+		FROM listening_sessions
+		GROUP BY station_id
+		ORDER BY MAX(started_at) DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get station history: %v", err)
+	}
+	defer rows.Close()
+
+	var history []model.StationHistory
+	for rows.Next() {
+		var h model.StationHistory
+		var listenedMs int64
+		var lastPlayed string
+		if err := rows.Scan(&h.StationID, &h.RadioName, &h.PlayURL, &h.Sessions, &listenedMs, &lastPlayed); err != nil {
+			return nil, err
+		}
+		h.Listened = time.Duration(listenedMs) * time.Millisecond
+		h.LastPlayed, _ = parseSQLiteTime(lastPlayed)
+		history = append(history, h)
+	}
+	return history, rows.Err()
+}
+
+// parseSQLiteTime 解析聚合函数返回的时间文本（聚合结果不带列类型，驱动不会自动转换）
+func parseSQLiteTime(s string) (time.Time, error) {
+	for _, layout := range []string{
+		"2006-01-02 15:04:05.999999999-07:00",
+		"2006-01-02T15:04:05.999999999-07:00",
+		"2006-01-02 15:04:05.999999999",
+		"2006-01-02T15:04:05.999999999",
+		"2006-01-02 15:04:05",
+	} {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			return t.Local(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time: %s", s)
+}
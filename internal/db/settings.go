@@ -0,0 +1,172 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"FMgo/internal/model"
+)
+
+// 设置项的键
+const (
+	SettingLastStationID   = "last_station_id"
+	SettingLastStationName = "last_station_name"
+	SettingLastStationURL  = "last_station_url"
+	SettingLastView        = "last_view"
+	SettingVolume          = "volume"
+	SettingCollapsed       = "collapsed_categories"
+	SettingTheme           = "theme"
+	SettingBitrate         = "preferred_bitrate"
+	SettingAudioBackend    = "audio_backend"
+	SettingAutoResume      = "auto_resume"
+)
+
+// migrateSettings 创建用户设置表
+func migrateSettings(tx *sql.Tx) error {
+	if _, err := tx.Exec(`
+		CREATE TABLE settings (
+			key TEXT PRIMARY KEY,
+			value TEXT NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create settings table: %v", err)
+	}
+	return nil
+}
+
+// GetSetting 返回设置项的值，未设置时 ok 为 false
+func (d *Database) GetSetting(key string) (value string, ok bool, err error) {
+	err = d.db.QueryRow(`SELECT value FROM settings WHERE key = ?`, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get setting: %v", err)
+	}
+	return value, true, nil
+}
+
+// SetSetting 保存设置项
+func (d *Database) SetSetting(key, value string) error {
+	if _, err := d.db.Exec(`
+		INSERT INTO settings (key, value) VALUES (?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value
+	`, key, value); err != nil {
+		return fmt.Errorf("failed to save setting: %v", err)
+	}
+	return nil
+}
+
+// SetSettings 在一个事务中保存多个设置项
+func (d *Database) SetSettings(values map[string]string) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO settings (key, value) VALUES (?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare settings: %v", err)
+	}
+	defer stmt.Close()
+
+	for key, value := range values {
+		if _, err := stmt.Exec(key, value); err != nil {
+			return fmt.Errorf("failed to save setting: %v", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// GetSettings 返回全部设置项
+func (d *Database) GetSettings() (map[string]string, error) {
+	rows, err := d.db.Query(`SELECT key, value FROM settings`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settings: %v", err)
+	}
+	defer rows.Close()
+
+	settings := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		settings[key] = value
+	}
+	return settings, rows.Err()
+}
+
+// LoadSettings 读取用户设置，未设置或无法解析的项使用默认值
+func LoadSettings(s SettingsStore) (model.Settings, error) {
+	settings := model.DefaultSettings()
+	values, err := s.GetSettings()
+	if err != nil {
+		return settings, err
+	}
+
+	settings.LastStation = model.Radio{
+		ID:      values[SettingLastStationID],
+		Name:    values[SettingLastStationName],
+		PlayURL: values[SettingLastStationURL],
+	}
+	if v, ok := values[SettingLastView]; ok {
+		settings.LastView = v
+	}
+	if v, err := strconv.Atoi(values[SettingVolume]); err == nil && v >= 0 && v <= 100 {
+		settings.Volume = v
+	}
+	if v, ok := values[SettingCollapsed]; ok {
+		var collapsed map[string]bool
+		if err := json.Unmarshal([]byte(v), &collapsed); err == nil {
+			settings.CollapsedCategories = collapsed
+		}
+	}
+	if v, ok := values[SettingTheme]; ok {
+		settings.Theme = v
+	}
+	if v, err := strconv.Atoi(values[SettingBitrate]); err == nil && v >= 0 {
+		settings.PreferredBitrate = v
+	}
+	settings.AudioBackend = values[SettingAudioBackend]
+	if v, err := strconv.ParseBool(values[SettingAutoResume]); err == nil {
+		settings.AutoResume = v
+	}
+	return settings, nil
+}
+
+// SaveSettings 保存全部用户设置
+func SaveSettings(s SettingsStore, settings model.Settings) error {
+	collapsed, err := json.Marshal(settings.CollapsedCategories)
+	if err != nil {
+		return fmt.Errorf("failed to encode collapsed categories: %v", err)
+	}
+	values := lastStationSettings(settings.LastStation)
+	values[SettingLastView] = settings.LastView
+	values[SettingVolume] = strconv.Itoa(settings.Volume)
+	values[SettingCollapsed] = string(collapsed)
+	values[SettingTheme] = settings.Theme
+	values[SettingBitrate] = strconv.Itoa(settings.PreferredBitrate)
+	values[SettingAudioBackend] = settings.AudioBackend
+	values[SettingAutoResume] = strconv.FormatBool(settings.AutoResume)
+	return s.SetSettings(values)
+}
+
+// SaveLastStation 只保存最近播放的电台，切台时不必重写其他设置
+func SaveLastStation(s SettingsStore, radio model.Radio) error {
+	return s.SetSettings(lastStationSettings(radio))
+}
+
+func lastStationSettings(radio model.Radio) map[string]string {
+	return map[string]string{
+		SettingLastStationID:   radio.ID,
+		SettingLastStationName: radio.Name,
+		SettingLastStationURL:  radio.PlayURL,
+	}
+}
@@ -0,0 +1,255 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"FMgo/internal/model"
+)
+
+// migrateStationIDs 为旧版本数据库的历史记录、收藏、网络统计、流量统计与均衡器设置补充电台 ID。
+// 旧数据按播放地址匹配外部目录同步的电台，匹配不到时按播放地址生成 ID；
+// 收藏、流量统计与均衡器设置表的主键或唯一约束改为包含电台 ID，需要重建表。
+// 引入版本管理前已按新结构创建的表会被跳过
+func migrateStationIDs(tx *sql.Tx) error {
+	has := make(map[string]bool)
+	for _, table := range []string{"history", "favorites", "stream_stats", "data_usage", "station_eq"} {
+		exists, err := columnExists(tx, table, "station_id")
+		if err != nil {
+			return err
+		}
+		has[table] = exists
+	}
+	if has["history"] && has["favorites"] && has["stream_stats"] && has["data_usage"] && has["station_eq"] {
+		return nil
+	}
+
+	ids, err := syncedStationIDsByURL(tx)
+	if err != nil {
+		return err
+	}
+	stationID := func(playURL string) string {
+		if id, ok := ids[playURL]; ok {
+			return id
+		}
+		return model.URLStationID(playURL)
+	}
+
+	if !has["history"] {
+		if _, err := tx.Exec(`ALTER TABLE history ADD COLUMN station_id TEXT NOT NULL DEFAULT ''`); err != nil {
+			return fmt.Errorf("failed to add history.station_id: %v", err)
+		}
+		if err := backfillStationIDs(tx, "history", stationID); err != nil {
+			return err
+		}
+	}
+
+	if !has["favorites"] {
+		if _, err := tx.Exec(`
+			CREATE TABLE favorites_new (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				station_id TEXT NOT NULL UNIQUE,
+				radio_name TEXT NOT NULL,
+				play_url TEXT NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			)
+		`); err != nil {
+			return fmt.Errorf("failed to create favorites table: %v", err)
+		}
+		rows, err := tx.Query(`SELECT radio_name, play_url, created_at FROM favorites ORDER BY created_at`)
+		if err != nil {
+			return fmt.Errorf("failed to load favorites: %v", err)
+		}
+		type favorite struct {
+			name, url string
+			createdAt sql.NullTime
+		}
+		var favorites []favorite
+		for rows.Next() {
+			var f favorite
+			if err := rows.Scan(&f.name, &f.url, &f.createdAt); err != nil {
+				rows.Close()
+				return err
+			}
+			favorites = append(favorites, f)
+		}
+		rows.Close()
+
+		for _, f := range favorites {
+			// 同一地址被以不同名称收藏过时只保留最新的一条
+			if _, err := tx.Exec(`
+				INSERT OR REPLACE INTO favorites_new (station_id, radio_name, play_url, created_at)
+				VALUES (?, ?, ?, ?)
+			`, stationID(f.url), f.name, f.url, f.createdAt); err != nil {
+				return fmt.Errorf("failed to migrate favorite: %v", err)
+			}
+		}
+		if _, err := tx.Exec(`DROP TABLE favorites`); err != nil {
+			return fmt.Errorf("failed to drop old favorites table: %v", err)
+		}
+		if _, err := tx.Exec(`ALTER TABLE favorites_new RENAME TO favorites`); err != nil {
+			return fmt.Errorf("failed to rename favorites table: %v", err)
+		}
+	}
+
+	if !has["stream_stats"] {
+		if _, err := tx.Exec(`ALTER TABLE stream_stats ADD COLUMN station_id TEXT NOT NULL DEFAULT ''`); err != nil {
+			return fmt.Errorf("failed to add stream_stats.station_id: %v", err)
+		}
+		if err := backfillStationIDs(tx, "stream_stats", stationID); err != nil {
+			return err
+		}
+	}
+
+	if !has["data_usage"] {
+		// 旧的月度总量无法分到电台，记在空电台 ID 下，仍计入月度总量
+		if _, err := tx.Exec(`
+			CREATE TABLE data_usage_new (
+				month TEXT NOT NULL,
+				station_id TEXT NOT NULL,
+				bytes INTEGER NOT NULL DEFAULT 0,
+				PRIMARY KEY (month, station_id)
+			)
+		`); err != nil {
+			return fmt.Errorf("failed to create data_usage table: %v", err)
+		}
+		if _, err := tx.Exec(`INSERT INTO data_usage_new (month, station_id, bytes) SELECT month, '', bytes FROM data_usage`); err != nil {
+			return fmt.Errorf("failed to migrate data usage: %v", err)
+		}
+		if _, err := tx.Exec(`DROP TABLE data_usage`); err != nil {
+			return fmt.Errorf("failed to drop old data_usage table: %v", err)
+		}
+		if _, err := tx.Exec(`ALTER TABLE data_usage_new RENAME TO data_usage`); err != nil {
+			return fmt.Errorf("failed to rename data_usage table: %v", err)
+		}
+	}
+
+	if !has["station_eq"] {
+		if err := migrateStationEQ(tx, ids); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// syncedStationIDsByURL 返回外部目录同步的电台播放地址到电台 ID 的映射
+func syncedStationIDsByURL(tx *sql.Tx) (map[string]string, error) {
+	rows, err := tx.Query(`
+		SELECT play_url, source, external_id FROM local_stations
+		WHERE source != 'user' AND external_id != ''
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load synced stations: %v", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[string]string)
+	for rows.Next() {
+		var playURL, source, externalID string
+		if err := rows.Scan(&playURL, &source, &externalID); err != nil {
+			return nil, err
+		}
+		ids[playURL] = syncedStationID(source, externalID)
+	}
+	return ids, rows.Err()
+}
+
+// backfillStationIDs 按播放地址为 table 中没有电台 ID 的记录生成 ID
+func backfillStationIDs(tx *sql.Tx, table string, stationID func(playURL string) string) error {
+	rows, err := tx.Query(`SELECT DISTINCT play_url FROM ` + table + ` WHERE station_id = ''`)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %v", table, err)
+	}
+	var urls []string
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			rows.Close()
+			return err
+		}
+		urls = append(urls, url)
+	}
+	rows.Close()
+
+	for _, url := range urls {
+		if _, err := tx.Exec(`UPDATE `+table+` SET station_id = ? WHERE play_url = ? AND station_id = ''`, stationID(url), url); err != nil {
+			return fmt.Errorf("failed to backfill %s.station_id: %v", table, err)
+		}
+	}
+	return nil
+}
+
+// columnExists 返回表中是否存在指定列
+func columnExists(tx *sql.Tx, table, column string) (bool, error) {
+	rows, err := tx.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect %s: %v", table, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+// syncedStationID 返回外部目录同步的电台 ID
+func syncedStationID(source, externalID string) string {
+	return source + ":" + externalID
+}
+
+// updateStationRefs 将收藏、历史记录、网络统计、流量统计与均衡器设置中指向 oldIDs 的记录更新为 radio（ID、名称与播放地址）
+func updateStationRefs(tx *sql.Tx, radio model.Radio, oldIDs ...string) error {
+	now := time.Now()
+	for _, oldID := range oldIDs {
+		result, err := tx.Exec(`
+			UPDATE OR IGNORE favorites SET station_id = ?, radio_name = ?, play_url = ?, updated_at = ? WHERE station_id = ?
+		`, radio.StationID(), radio.Name, radio.PlayURL, now, oldID)
+		if err != nil {
+			return fmt.Errorf("failed to update favorite: %v", err)
+		}
+		// 换了 ID 的收藏在同步文件中按旧 ID 移除
+		if n, _ := result.RowsAffected(); n > 0 && oldID != radio.StationID() {
+			if err := addTombstone(tx, oldID, now); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Exec(`
+			UPDATE listening_sessions SET station_id = ?, radio_name = ? WHERE station_id = ?
+		`, radio.StationID(), radio.Name, oldID); err != nil {
+			return fmt.Errorf("failed to update history: %v", err)
+		}
+		if _, err := tx.Exec(`
+			UPDATE stream_stats SET station_id = ?, radio_name = ? WHERE station_id = ?
+		`, radio.StationID(), radio.Name, oldID); err != nil {
+			return fmt.Errorf("failed to update stream stats: %v", err)
+		}
+		if _, err := tx.Exec(`
+			UPDATE OR IGNORE station_eq SET station_id = ?, radio_name = ? WHERE station_id = ?
+		`, radio.StationID(), radio.Name, oldID); err != nil {
+			return fmt.Errorf("failed to update station eq: %v", err)
+		}
+		if oldID == radio.StationID() {
+			continue
+		}
+		// 新 ID 在同一月份已有流量时合并
+		if _, err := tx.Exec(`
+			INSERT INTO data_usage (month, station_id, bytes)
+			SELECT month, ?, bytes FROM data_usage WHERE station_id = ?
+			ON CONFLICT(month, station_id) DO UPDATE SET bytes = bytes + excluded.bytes
+		`, radio.StationID(), oldID); err != nil {
+			return fmt.Errorf("failed to update data usage: %v", err)
+		}
+		if _, err := tx.Exec(`DELETE FROM data_usage WHERE station_id = ?`, oldID); err != nil {
+			return fmt.Errorf("failed to update data usage: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package db
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"FMgo/internal/config"
+	"FMgo/internal/model"
+)
+
+// legacySchema 是引入电台 ID 之前按名称记录的表结构与数据
+const legacySchema = `
+CREATE TABLE history (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	radio_name TEXT NOT NULL,
+	play_url TEXT NOT NULL,
+	played_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE favorites (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	radio_name TEXT NOT NULL UNIQUE,
+	play_url TEXT NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE stream_stats (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	radio_name TEXT NOT NULL,
+	play_url TEXT NOT NULL,
+	started_at DATETIME NOT NULL,
+	ended_at DATETIME NOT NULL,
+	bytes_received INTEGER NOT NULL DEFAULT 0,
+	segments INTEGER NOT NULL DEFAULT 0,
+	avg_latency_ms INTEGER NOT NULL DEFAULT 0,
+	avg_throughput REAL NOT NULL DEFAULT 0,
+	underruns INTEGER NOT NULL DEFAULT 0,
+	reconnects INTEGER NOT NULL DEFAULT 0,
+	errors INTEGER NOT NULL DEFAULT 0
+);
+CREATE TABLE data_usage (
+	month TEXT PRIMARY KEY,
+	bytes INTEGER NOT NULL DEFAULT 0
+);
+CREATE TABLE station_eq (
+	radio_name TEXT PRIMARY KEY,
+	preset TEXT NOT NULL,
+	gains TEXT NOT NULL
+);
+CREATE TABLE local_stations (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	category_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	play_url TEXT NOT NULL,
+	position INTEGER NOT NULL DEFAULT 0,
+	source TEXT NOT NULL DEFAULT 'user',
+	external_id TEXT NOT NULL DEFAULT '',
+	province TEXT NOT NULL DEFAULT '',
+	logo TEXT NOT NULL DEFAULT '',
+	program TEXT NOT NULL DEFAULT '',
+	synced_at DATETIME,
+	removed_at DATETIME,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO local_stations (category_id, name, play_url, source, external_id)
+	VALUES (1, '喜马拉雅台', 'http://example.com/xmly', 'xmly', '42');
+INSERT INTO favorites (radio_name, play_url, created_at) VALUES
+	('中国之声', 'http://example.com/cnr1', '2024-05-01 08:00:00'),
+	('喜马拉雅台', 'http://example.com/xmly', '2024-05-02 08:00:00'),
+	('中国之声 新', 'http://example.com/cnr1', '2024-05-03 08:00:00');
+INSERT INTO history (radio_name, play_url, played_at) VALUES
+	('中国之声', 'http://example.com/cnr1', '2024-05-01 09:00:00'),
+	('喜马拉雅台', 'http://example.com/xmly', '2024-05-02 09:00:00');
+INSERT INTO stream_stats (radio_name, play_url, started_at, ended_at, underruns) VALUES
+	('中国之声', 'http://example.com/cnr1', '2024-05-01 09:00:00', '2024-05-01 10:00:00', 2);
+INSERT INTO data_usage (month, bytes) VALUES ('2024-05', 100);
+INSERT INTO station_eq (radio_name, preset, gains) VALUES
+	('中国之声 新', 'rock', '[1,2]'),
+	('喜马拉雅台', 'jazz', '[3]'),
+	('已删除的电台', 'pop', '[4]');
+`
+
+func openTestDatabase(t *testing.T, schema string) *Database {
+	t.Helper()
+	config.DBFile = filepath.Join(t.TempDir(), "fmgo.db")
+	if schema != "" {
+		raw, err := sql.Open("sqlite3", config.DBFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := raw.Exec(schema); err != nil {
+			t.Fatal(err)
+		}
+		raw.Close()
+	}
+	d, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { d.Close() })
+	return d
+}
+
+func TestMigrateStationIDs(t *testing.T) {
+	d := openTestDatabase(t, legacySchema)
+	cnr1 := model.URLStationID("http://example.com/cnr1")
+
+	favorites, err := d.GetFavorites()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []model.Radio{
+		{ID: cnr1, Name: "中国之声 新", PlayURL: "http://example.com/cnr1"},
+		{ID: "xmly:42", Name: "喜马拉雅台", PlayURL: "http://example.com/xmly"},
+	}
+	if len(favorites) != len(want) {
+		t.Fatalf("favorites = %+v, want %+v", favorites, want)
+	}
+	for i := range want {
+		if favorites[i].ID != want[i].ID || favorites[i].Name != want[i].Name || favorites[i].PlayURL != want[i].PlayURL {
+			t.Errorf("favorites[%d] = %+v, want %+v", i, favorites[i], want[i])
+		}
+	}
+
+	history, err := d.GetHistory(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[0].StationID != "xmly:42" || history[1].StationID != cnr1 {
+		t.Errorf("history = %+v", history)
+	}
+
+	flaky, err := d.GetFlakyStations(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flaky) != 1 || flaky[0].StationID != cnr1 || flaky[0].Underruns != 2 {
+		t.Errorf("flaky stations = %+v", flaky)
+	}
+
+	may := time.Date(2024, 5, 15, 0, 0, 0, 0, time.Local)
+	if bytes, err := d.GetMonthlyDataUsage(may); err != nil || bytes != 100 {
+		t.Errorf("data usage = %d, %v, want 100", bytes, err)
+	}
+
+	eqTests := []struct {
+		radio  model.Radio
+		preset string
+	}{
+		{model.Radio{Name: "中国之声", PlayURL: "http://example.com/cnr1"}, "rock"},
+		{model.Radio{Name: "喜马拉雅台", ID: "xmly:42", PlayURL: "http://example.com/xmly"}, "jazz"},
+		{model.Radio{Name: "已删除的电台", PlayURL: "http://example.com/gone"}, "pop"},
+		{model.Radio{Name: "中国之声 新", PlayURL: "http://example.com/other"}, ""},
+	}
+	for _, tt := range eqTests {
+		setting, err := d.GetStationEQ(tt.radio)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var preset string
+		if setting != nil {
+			preset = setting.Preset
+		}
+		if preset != tt.preset {
+			t.Errorf("eq for %s = %q, want %q", tt.radio.Name, preset, tt.preset)
+		}
+	}
+
+	// 再次打开时不重复升级
+	d.Close()
+	if d, err = New(); err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer d.Close()
+	if favorites, _ := d.GetFavorites(); len(favorites) != 2 {
+		t.Errorf("favorites after reopen = %+v", favorites)
+	}
+}
+
+func TestUpdateStationRefs(t *testing.T) {
+	d := openTestDatabase(t, "")
+	old := model.Radio{Name: "a", PlayURL: "http://example.com/a"}
+	moved := model.Radio{Name: "a2", PlayURL: "http://example.com/moved"}
+	now := time.Now()
+
+	check := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	check(d.AddLocalStation("音乐", old))
+	check(d.AddFavorite(old))
+	_, err := d.StartSession(old, now)
+	check(err)
+	check(d.AddStreamStats(model.StreamStats{StationID: old.StationID(), RadioName: "a", PlayURL: old.PlayURL, StartedAt: now, EndedAt: now, Errors: 1}))
+	check(d.AddDataUsage(now, old.StationID(), 10))
+	check(d.AddDataUsage(now, moved.StationID(), 5))
+	check(d.SaveStationEQ(model.EQSetting{StationID: old.StationID(), RadioName: "a", Preset: "rock"}))
+
+	check(d.UpdateLocalStation("音乐", "a", moved))
+
+	if favorites, err := d.GetFavorites(); err != nil || len(favorites) != 1 || favorites[0].ID != moved.StationID() || favorites[0].Name != "a2" {
+		t.Errorf("favorites = %+v, %v", favorites, err)
+	}
+	if history, err := d.GetHistory(10); err != nil || len(history) != 1 || history[0].StationID != moved.StationID() {
+		t.Errorf("history = %+v, %v", history, err)
+	}
+	if flaky, err := d.GetFlakyStations(10); err != nil || len(flaky) != 1 || flaky[0].StationID != moved.StationID() || flaky[0].RadioName != "a2" {
+		t.Errorf("flaky stations = %+v, %v", flaky, err)
+	}
+	if setting, err := d.GetStationEQ(moved); err != nil || setting == nil || setting.Preset != "rock" {
+		t.Errorf("eq = %+v, %v", setting, err)
+	}
+
+	usage := make(map[string]int64)
+	rows, err := d.db.Query(`SELECT station_id, bytes FROM data_usage`)
+	check(err)
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		var bytes int64
+		check(rows.Scan(&id, &bytes))
+		usage[id] = bytes
+	}
+	if len(usage) != 1 || usage[moved.StationID()] != 15 {
+		t.Errorf("data usage = %v, want 15 bytes for %s", usage, moved.StationID())
+	}
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"fmt"
+
+	"FMgo/internal/model"
+)
+
+// AddStreamStats 保存一次播放会话的网络统计摘要
+func (d *Database) AddStreamStats(s model.StreamStats) error {
+	_, err := d.db.Exec(`
+		INSERT INTO stream_stats (
+			station_id, radio_name, play_url, started_at, ended_at, bytes_received, segments,
+			avg_latency_ms, avg_throughput, underruns, reconnects, errors
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, s.StationID, s.RadioName, s.PlayURL, s.StartedAt, s.EndedAt, s.BytesReceived, s.Segments,
+		s.AvgLatencyMs, s.AvgThroughput, s.Underruns, s.Reconnects, s.Errors)
+	if err != nil {
+		return fmt.Errorf("failed to add stream stats: %v", err)
+	}
+	return nil
+}
+
+// GetFlakyStations 按电台 ID 汇总欠载、重连和错误次数，返回最不稳定的电台，名称取最近一次播放时的名称
+func (d *Database) GetFlakyStations(limit int) ([]model.StationHealth, error) {
+	rows, err := d.db.Query(`
+		SELECT station_id,
+			(SELECT radio_name FROM stream_stats latest WHERE latest.station_id = s.station_id ORDER BY started_at DESC LIMIT 1),
+			COUNT(*), SUM(underruns), SUM(reconnects), SUM(errors)
+		FROM stream_stats s
+		GROUP BY station_id
+		HAVING SUM(underruns) + SUM(reconnects) + SUM(errors) > 0
+		ORDER BY SUM(underruns) + SUM(reconnects) + SUM(errors) DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get flaky stations: %v", err)
+	}
+	defer rows.Close()
+
+	var stations []model.StationHealth
+	for rows.Next() {
+		var h model.StationHealth
+		if err := rows.Scan(&h.StationID, &h.RadioName, &h.Sessions, &h.Underruns, &h.Reconnects, &h.Errors); err != nil {
+			return nil, err
+		}
+		stations = append(stations, h)
+	}
+	return stations, rows.Err()
+}
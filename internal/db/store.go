@@ -0,0 +1,110 @@
+package db
+
+import (
+	"time"
+
+	"FMgo/internal/model"
+)
+
+// HistoryStore 保存收听会话及由其生成的历史与统计
+type HistoryStore interface {
+	StartSession(radio model.Radio, startedAt time.Time) (int64, error)
+	EndSession(id int64, endedAt time.Time, listened time.Duration, reason string, bytes int64) error
+	CloseOpenSessions() error
+	GetHistory(limit int) ([]model.PlayHistory, error)
+	GetStationHistory(limit int) ([]model.StationHistory, error)
+	ClearHistory() error
+	DeleteStationHistory(stationID string) error
+	PruneHistory(now time.Time, maxAge time.Duration, maxRows int) (int64, error)
+	ListeningReport(now time.Time, days int, categories []model.Category) (*model.ListeningReport, error)
+}
+
+// FavoriteStore 保存收藏及其文件夹、顺序、备注与评分
+type FavoriteStore interface {
+	AddFavorite(radio model.Radio) error
+	RemoveFavorite(stationID string) error
+	IsFavorite(stationID string) (bool, error)
+	GetFavorites() ([]model.Favorite, error)
+	MoveFavorite(stationID string, delta int) error
+	SetFavoriteFolder(stationID, folder string) error
+	SetFavoriteNote(stationID, note string) error
+	SetFavoriteRating(stationID string, rating int) error
+}
+
+// LocalCatalogStore 保存用户可编辑的本地电台目录。修改电台名称或播放地址时，
+// 指向它的收藏与历史记录随之更新
+type LocalCatalogStore interface {
+	GetLocalCatalog() ([]model.Category, error)
+	AddLocalCategory(name string) (int64, error)
+	RenameLocalCategory(oldName, newName string) error
+	DeleteLocalCategory(name string) error
+	MoveLocalCategory(name string, delta int) error
+	AddLocalStation(category string, radio model.Radio) error
+	ImportLocalStations(categories []model.Category, known map[string]bool) (added, skipped int, err error)
+	UpdateLocalStation(category, name string, radio model.Radio) error
+	DeleteLocalStation(category, name string) error
+	MoveLocalStation(category, name string, delta int) error
+	MoveLocalStationTo(category, name, target string) error
+}
+
+// StatsStore 保存播放会话的网络统计与月度流量
+type StatsStore interface {
+	AddStreamStats(s model.StreamStats) error
+	GetFlakyStations(limit int) ([]model.StationHealth, error)
+	AddDataUsage(at time.Time, stationID string, bytes int64) error
+	GetMonthlyDataUsage(at time.Time) (int64, error)
+}
+
+// EQStore 按电台 ID 保存各电台的均衡器设置
+type EQStore interface {
+	SaveStationEQ(setting model.EQSetting) error
+	GetStationEQ(radio model.Radio) (*model.EQSetting, error)
+}
+
+// SettingsStore 以键值对保存用户设置，LoadSettings 与 SaveSettings 在其上读写 model.Settings
+type SettingsStore interface {
+	GetSetting(key string) (value string, ok bool, err error)
+	SetSetting(key, value string) error
+	SetSettings(values map[string]string) error
+	GetSettings() (map[string]string, error)
+}
+
+// DirectoryCacheStore 缓存在线目录返回的电台，供离线浏览与按播放地址查找
+type DirectoryCacheStore interface {
+	CacheDirectoryStations(stations []model.DirectoryStation) error
+	SearchDirectoryCache(q model.DirectoryQuery, orderBy string) ([]model.DirectoryStation, error)
+	GetDirectoryStationByURL(url string) (*model.DirectoryStation, error)
+}
+
+// SyncStore 将外部目录的电台同步到本地目录并记录同步时间
+type SyncStore interface {
+	SyncExternalStations(source string, stations []model.ExternalStation) (*model.SyncReport, error)
+	LastSyncedAt(source string) (time.Time, error)
+}
+
+// UserDataStore 与同步目录合并收藏与收听历史。合并读写磁盘上的同步文件，
+// 只有 Database 实现，不在 Store 中
+type UserDataStore interface {
+	MergeUserData(dir string) (*model.MergeReport, error)
+}
+
+// Store 是界面与目录使用的全部存储，新增的数据表应在此加入对应的接口。
+// Database 是基于 SQLite 文件的实现，Memory 是供测试使用的内存实现，
+// 两者的行为由 storetest 包中的一致性测试约束
+type Store interface {
+	HistoryStore
+	FavoriteStore
+	LocalCatalogStore
+	StatsStore
+	EQStore
+	SettingsStore
+	DirectoryCacheStore
+	SyncStore
+	Close() error
+}
+
+var (
+	_ Store         = (*Database)(nil)
+	_ Store         = (*Memory)(nil)
+	_ UserDataStore = (*Database)(nil)
+)
@@ -0,0 +1,642 @@
+// Package storetest 是 db.Store 实现的一致性测试。每个实现在自己的测试中调用 Run，
+// 确保 SQLite 与内存实现在排序、去重、级联更新等细节上行为一致：
+//
+//	storetest.Run(t, func(t *testing.T) db.Store { return db.NewMemory() })
+package storetest
+
+import (
+	"testing"
+	"time"
+
+	"FMgo/internal/db"
+	"FMgo/internal/model"
+)
+
+// Run 对 open 创建的存储执行全部一致性测试，每个子测试使用一个新的空存储
+func Run(t *testing.T, open func(t *testing.T) db.Store) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s db.Store)
+	}{
+		{"Favorites", testFavorites},
+		{"FavoriteFolders", testFavoriteFolders},
+		{"Sessions", testSessions},
+		{"StationHistory", testStationHistory},
+		{"ClearHistory", testClearHistory},
+		{"PruneHistory", testPruneHistory},
+		{"ListeningReport", testListeningReport},
+		{"LocalCatalog", testLocalCatalog},
+		{"ImportLocalStations", testImportLocalStations},
+		{"StationRefs", testStationRefs},
+		{"StreamStats", testStreamStats},
+		{"DataUsage", testDataUsage},
+		{"StationEQ", testStationEQ},
+		{"Settings", testSettings},
+		{"DirectoryCache", testDirectoryCache},
+		{"ExternalSync", testExternalSync},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			s := open(t)
+			defer s.Close()
+			tt.fn(t, s)
+		})
+	}
+}
+
+var base = time.Date(2026, 3, 2, 20, 0, 0, 0, time.Local) // 周一晚上
+
+func radio(name string) model.Radio {
+	return model.Radio{Name: name, PlayURL: "http://example.com/" + name}
+}
+
+func check(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// listen 记录一次从 start 开始、收听 minutes 分钟的会话
+func listen(t *testing.T, s db.Store, r model.Radio, start time.Time, minutes int) int64 {
+	t.Helper()
+	id, err := s.StartSession(r, start)
+	check(t, err)
+	d := time.Duration(minutes) * time.Minute
+	check(t, s.EndSession(id, start.Add(d), d, model.EndReasonStop, 1024))
+	return id
+}
+
+func favoriteNames(t *testing.T, s db.Store) []string {
+	t.Helper()
+	favorites, err := s.GetFavorites()
+	check(t, err)
+	var names []string
+	for _, f := range favorites {
+		names = append(names, f.Folder+"/"+f.Name)
+	}
+	return names
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func testFavorites(t *testing.T, s db.Store) {
+	a, b := radio("a"), radio("b")
+	check(t, s.AddFavorite(a))
+	check(t, s.AddFavorite(b))
+	if ok, err := s.IsFavorite(a.StationID()); err != nil || !ok {
+		t.Fatalf("IsFavorite(a) = %v, %v", ok, err)
+	}
+
+	// 再次收藏只更新名称与地址，不改变位置
+	renamed := a
+	renamed.Name = "a2"
+	check(t, s.AddFavorite(renamed))
+	if got, want := favoriteNames(t, s), []string{"/a2", "/b"}; !equal(got, want) {
+		t.Fatalf("favorites = %v, want %v", got, want)
+	}
+
+	favorites, err := s.GetFavorites()
+	check(t, err)
+	if f := favorites[0]; f.ID != a.StationID() || f.PlayURL != a.PlayURL || f.Position != 1 {
+		t.Fatalf("favorite = %+v", f)
+	}
+
+	check(t, s.RemoveFavorite(a.StationID()))
+	if ok, err := s.IsFavorite(a.StationID()); err != nil || ok {
+		t.Fatalf("IsFavorite(a) after remove = %v, %v", ok, err)
+	}
+	if got, want := favoriteNames(t, s), []string{"/b"}; !equal(got, want) {
+		t.Fatalf("favorites = %v, want %v", got, want)
+	}
+	check(t, s.RemoveFavorite("missing"))
+}
+
+func testFavoriteFolders(t *testing.T, s db.Store) {
+	for _, name := range []string{"a", "b", "c", "d"} {
+		check(t, s.AddFavorite(radio(name)))
+	}
+	id := func(name string) string { return radio(name).StationID() }
+
+	check(t, s.MoveFavorite(id("c"), -1))
+	check(t, s.MoveFavorite(id("a"), -1)) // 已在最前，不移动
+	check(t, s.MoveFavorite(id("d"), 1))  // 已在最后，不移动
+	if got, want := favoriteNames(t, s), []string{"/a", "/c", "/b", "/d"}; !equal(got, want) {
+		t.Fatalf("after move = %v, want %v", got, want)
+	}
+
+	// 移入文件夹的收藏排在文件夹末尾，未分组的收藏在前
+	check(t, s.SetFavoriteFolder(id("b"), "news"))
+	check(t, s.SetFavoriteFolder(id("a"), "news"))
+	check(t, s.SetFavoriteFolder(id("a"), "news"))
+	if got, want := favoriteNames(t, s), []string{"/c", "/d", "news/b", "news/a"}; !equal(got, want) {
+		t.Fatalf("after folder = %v, want %v", got, want)
+	}
+	check(t, s.MoveFavorite(id("a"), -1))
+	if got, want := favoriteNames(t, s), []string{"/c", "/d", "news/a", "news/b"}; !equal(got, want) {
+		t.Fatalf("after move in folder = %v, want %v", got, want)
+	}
+	check(t, s.SetFavoriteFolder(id("a"), ""))
+	if got, want := favoriteNames(t, s), []string{"/c", "/d", "/a", "news/b"}; !equal(got, want) {
+		t.Fatalf("after leaving folder = %v, want %v", got, want)
+	}
+
+	check(t, s.SetFavoriteNote(id("c"), "morning"))
+	check(t, s.SetFavoriteRating(id("c"), 4))
+	favorites, err := s.GetFavorites()
+	check(t, err)
+	if f := favorites[0]; f.Note != "morning" || f.Rating != 4 {
+		t.Fatalf("favorite = %+v", f)
+	}
+	if err := s.SetFavoriteRating(id("c"), 6); err == nil {
+		t.Fatal("rating 6 accepted")
+	}
+	if err := s.SetFavoriteNote("missing", "x"); err == nil {
+		t.Fatal("note on missing favorite accepted")
+	}
+	if err := s.MoveFavorite("missing", 1); err == nil {
+		t.Fatal("moving missing favorite accepted")
+	}
+	if err := s.SetFavoriteFolder("missing", "x"); err == nil {
+		t.Fatal("foldering missing favorite accepted")
+	}
+}
+
+func testSessions(t *testing.T, s db.Store) {
+	a := radio("a")
+	first := listen(t, s, a, base, 30)
+	open, err := s.StartSession(a, base.Add(time.Hour))
+	check(t, err)
+
+	history, err := s.GetHistory(-1)
+	check(t, err)
+	if len(history) != 2 || history[0].ID != open || history[1].ID != first {
+		t.Fatalf("history = %+v", history)
+	}
+	if history[0].EndedAt != nil {
+		t.Fatalf("open session has end time %v", history[0].EndedAt)
+	}
+	h := history[1]
+	if h.StationID != a.StationID() || h.RadioName != "a" || h.PlayURL != a.PlayURL || !h.PlayedAt.Equal(base) ||
+		h.EndedAt == nil || !h.EndedAt.Equal(base.Add(30*time.Minute)) || h.Listened() != 30*time.Minute ||
+		h.EndReason != model.EndReasonStop || h.BytesReceived != 1024 {
+		t.Fatalf("session = %+v", h)
+	}
+
+	check(t, s.CloseOpenSessions())
+	history, err = s.GetHistory(1)
+	check(t, err)
+	if len(history) != 1 || history[0].EndedAt == nil || !history[0].EndedAt.Equal(history[0].PlayedAt) ||
+		history[0].EndReason != model.EndReasonExit || history[0].ListenedMs != 0 {
+		t.Fatalf("closed session = %+v", history)
+	}
+}
+
+func testStationHistory(t *testing.T, s db.Store) {
+	a, b := radio("a"), radio("b")
+	listen(t, s, a, base, 10)
+	listen(t, s, b, base.Add(time.Hour), 5)
+	renamed := a
+	renamed.Name = "a2"
+	listen(t, s, renamed, base.Add(2*time.Hour), 20)
+
+	history, err := s.GetStationHistory(10)
+	check(t, err)
+	if len(history) != 2 {
+		t.Fatalf("station history = %+v", history)
+	}
+	h := history[0]
+	if h.StationID != a.StationID() || h.RadioName != "a2" || h.Sessions != 2 || h.Listened != 30*time.Minute ||
+		!h.LastPlayed.Equal(base.Add(2*time.Hour)) {
+		t.Fatalf("station history[0] = %+v", h)
+	}
+	if history[1].StationID != b.StationID() {
+		t.Fatalf("station history[1] = %+v", history[1])
+	}
+
+	history, err = s.GetStationHistory(1)
+	check(t, err)
+	if len(history) != 1 {
+		t.Fatalf("limited station history = %+v", history)
+	}
+}
+
+func testClearHistory(t *testing.T, s db.Store) {
+	a, b := radio("a"), radio("b")
+	listen(t, s, a, base, 10)
+	listen(t, s, b, base, 10)
+	listen(t, s, a, base.Add(time.Hour), 10)
+
+	check(t, s.DeleteStationHistory(a.StationID()))
+	history, err := s.GetHistory(-1)
+	check(t, err)
+	if len(history) != 1 || history[0].StationID != b.StationID() {
+		t.Fatalf("history after delete = %+v", history)
+	}
+
+	check(t, s.ClearHistory())
+	history, err = s.GetHistory(-1)
+	check(t, err)
+	if len(history) != 0 {
+		t.Fatalf("history after clear = %+v", history)
+	}
+}
+
+func testPruneHistory(t *testing.T, s db.Store) {
+	a := radio("a")
+	for day := 0; day < 6; day++ {
+		listen(t, s, a, base.AddDate(0, 0, -day), 10)
+	}
+	// 未结束的会话既不计入保留条数也不会被删除
+	_, err := s.StartSession(a, base.AddDate(0, 0, -10))
+	check(t, err)
+	now := base.Add(time.Hour)
+
+	n, err := s.PruneHistory(now, 0, 0)
+	check(t, err)
+	if n != 0 {
+		t.Fatalf("pruned %d without limits", n)
+	}
+	n, err = s.PruneHistory(now, 3*24*time.Hour, 0)
+	check(t, err)
+	if n != 3 {
+		t.Fatalf("pruned %d by age, want 3", n)
+	}
+	n, err = s.PruneHistory(now, 0, 2)
+	check(t, err)
+	if n != 1 {
+		t.Fatalf("pruned %d by rows, want 1", n)
+	}
+	history, err := s.GetHistory(-1)
+	check(t, err)
+	if len(history) != 3 || !history[1].PlayedAt.Equal(base.AddDate(0, 0, -1)) || history[2].EndedAt != nil {
+		t.Fatalf("history after prune = %+v", history)
+	}
+}
+
+func testListeningReport(t *testing.T, s db.Store) {
+	a, b := radio("a"), radio("b")
+	listen(t, s, a, base, 30)
+	listen(t, s, b, base.Add(-24*time.Hour), 15)
+	_, err := s.StartSession(a, base.Add(time.Hour)) // 未结束的会话不计入
+	check(t, err)
+
+	categories := []model.Category{{Name: "music", RadioList: []model.Radio{a}}}
+	report, err := s.ListeningReport(base.Add(2*time.Hour), 0, categories)
+	check(t, err)
+	if report.TotalSeconds != 45*60 {
+		t.Fatalf("total = %d", report.TotalSeconds)
+	}
+	if len(report.TopWeek) != 1 || report.TopWeek[0].StationID != a.StationID() {
+		t.Fatalf("top week = %+v", report.TopWeek)
+	}
+	if report.CurrentStreak != 2 {
+		t.Fatalf("current streak = %d", report.CurrentStreak)
+	}
+	if len(report.Categories) != 2 || report.Categories[0].Category != "music" ||
+		report.Categories[1].Category != db.UncategorizedName {
+		t.Fatalf("categories = %+v", report.Categories)
+	}
+}
+
+func catalogNames(t *testing.T, s db.Store) []string {
+	t.Helper()
+	categories, err := s.GetLocalCatalog()
+	check(t, err)
+	var names []string
+	for _, cat := range categories {
+		names = append(names, cat.Name+":")
+		for _, r := range cat.RadioList {
+			names = append(names, r.Name)
+		}
+	}
+	return names
+}
+
+func testLocalCatalog(t *testing.T, s db.Store) {
+	id, err := s.AddLocalCategory("news")
+	check(t, err)
+	again, err := s.AddLocalCategory("news")
+	check(t, err)
+	if id != again {
+		t.Fatalf("AddLocalCategory returned %d then %d", id, again)
+	}
+	check(t, s.AddLocalStation("music", radio("m1")))
+	check(t, s.AddLocalStation("music", radio("m2")))
+	check(t, s.AddLocalStation("news", radio("n1")))
+	if got, want := catalogNames(t, s), []string{"news:", "n1", "music:", "m1", "m2"}; !equal(got, want) {
+		t.Fatalf("catalog = %v, want %v", got, want)
+	}
+
+	check(t, s.MoveLocalCategory("music", -1))
+	check(t, s.MoveLocalStation("music", "m2", -1))
+	check(t, s.MoveLocalStation("music", "m2", -1))
+	if got, want := catalogNames(t, s), []string{"music:", "m2", "m1", "news:", "n1"}; !equal(got, want) {
+		t.Fatalf("catalog after move = %v, want %v", got, want)
+	}
+	if err := s.MoveLocalCategory("missing", 1); err == nil {
+		t.Fatal("moving missing category accepted")
+	}
+
+	check(t, s.RenameLocalCategory("news", "talk"))
+	if err := s.RenameLocalCategory("talk", "music"); err == nil {
+		t.Fatal("rename to existing category accepted")
+	}
+	if err := s.RenameLocalCategory("missing", "x"); err == nil {
+		t.Fatal("renaming missing category accepted")
+	}
+
+	check(t, s.MoveLocalStationTo("music", "m1", "talk"))
+	check(t, s.MoveLocalStationTo("talk", "n1", "new"))
+	if got, want := catalogNames(t, s), []string{"music:", "m2", "talk:", "m1", "new:", "n1"}; !equal(got, want) {
+		t.Fatalf("catalog after move to = %v, want %v", got, want)
+	}
+
+	check(t, s.DeleteLocalStation("music", "m2"))
+	if err := s.DeleteLocalStation("music", "m2"); err == nil {
+		t.Fatal("deleting missing station accepted")
+	}
+	check(t, s.DeleteLocalCategory("talk"))
+	if got, want := catalogNames(t, s), []string{"music:", "new:", "n1"}; !equal(got, want) {
+		t.Fatalf("catalog after delete = %v, want %v", got, want)
+	}
+
+	categories, err := s.GetLocalCatalog()
+	check(t, err)
+	if r := categories[1].RadioList[0]; r.PlayURL != radio("n1").PlayURL || r.StationID() != radio("n1").StationID() {
+		t.Fatalf("station = %+v", r)
+	}
+}
+
+func testImportLocalStations(t *testing.T, s db.Store) {
+	check(t, s.AddLocalStation("music", radio("m1")))
+	added, skipped, err := s.ImportLocalStations([]model.Category{
+		{Name: "music", RadioList: []model.Radio{radio("m1"), radio("m2"), radio("m2")}},
+		{Name: "known", RadioList: []model.Radio{radio("k")}},
+	}, map[string]bool{radio("k").PlayURL: true})
+	check(t, err)
+	if added != 1 || skipped != 3 {
+		t.Fatalf("added %d, skipped %d", added, skipped)
+	}
+	// 全部跳过的分类不会被创建
+	if got, want := catalogNames(t, s), []string{"music:", "m1", "m2"}; !equal(got, want) {
+		t.Fatalf("catalog = %v, want %v", got, want)
+	}
+}
+
+func testStationRefs(t *testing.T, s db.Store) {
+	old := radio("a")
+	check(t, s.AddLocalStation("music", old))
+	check(t, s.AddFavorite(old))
+	listen(t, s, old, base, 10)
+	check(t, s.SaveStationEQ(model.EQSetting{StationID: old.StationID(), RadioName: "a", Preset: "rock"}))
+	check(t, s.AddStreamStats(model.StreamStats{StationID: old.StationID(), RadioName: "a", PlayURL: old.PlayURL,
+		StartedAt: base, EndedAt: base.Add(time.Minute), Errors: 1}))
+	check(t, s.AddDataUsage(base, old.StationID(), 100))
+
+	moved := model.Radio{Name: "a2", PlayURL: "http://example.com/moved"}
+	check(t, s.AddDataUsage(base, moved.StationID(), 20))
+	check(t, s.UpdateLocalStation("music", "a", moved))
+
+	if setting, err := s.GetStationEQ(moved); err != nil || setting == nil || setting.Preset != "rock" {
+		t.Fatalf("moved eq = %+v, %v", setting, err)
+	}
+
+	favorites, err := s.GetFavorites()
+	check(t, err)
+	if len(favorites) != 1 || favorites[0].ID != moved.StationID() || favorites[0].Name != "a2" ||
+		favorites[0].PlayURL != moved.PlayURL {
+		t.Fatalf("favorites = %+v", favorites)
+	}
+	history, err := s.GetHistory(-1)
+	check(t, err)
+	if len(history) != 1 || history[0].StationID != moved.StationID() || history[0].RadioName != "a2" {
+		t.Fatalf("history = %+v", history)
+	}
+	flaky, err := s.GetFlakyStations(5)
+	check(t, err)
+	if len(flaky) != 1 || flaky[0].StationID != moved.StationID() || flaky[0].RadioName != "a2" {
+		t.Fatalf("flaky = %+v", flaky)
+	}
+	if bytes, err := s.GetMonthlyDataUsage(base); err != nil || bytes != 120 {
+		t.Fatalf("usage = %d, %v", bytes, err)
+	}
+	if got, want := catalogNames(t, s), []string{"music:", "a2"}; !equal(got, want) {
+		t.Fatalf("catalog = %v, want %v", got, want)
+	}
+	if err := s.UpdateLocalStation("music", "a", moved); err == nil {
+		t.Fatal("updating missing station accepted")
+	}
+}
+
+func testStreamStats(t *testing.T, s db.Store) {
+	stats := func(r model.Radio, start time.Time, underruns, errors int) model.StreamStats {
+		return model.StreamStats{StationID: r.StationID(), RadioName: r.Name, PlayURL: r.PlayURL,
+			StartedAt: start, EndedAt: start.Add(time.Minute), Underruns: underruns, Errors: errors}
+	}
+	flakyRadio := radio("flaky")
+	check(t, s.AddStreamStats(stats(radio("ok"), base, 0, 0)))
+	check(t, s.AddStreamStats(stats(flakyRadio, base, 1, 0)))
+	check(t, s.AddStreamStats(stats(radio("bad"), base, 2, 3)))
+	// 改名后仍按电台 ID 汇总，显示最近一次的名称
+	flakyRadio.Name = "flaky2"
+	check(t, s.AddStreamStats(stats(flakyRadio, base.Add(time.Hour), 0, 1)))
+
+	flaky, err := s.GetFlakyStations(5)
+	check(t, err)
+	if len(flaky) != 2 || flaky[0].RadioName != "bad" || flaky[1].StationID != flakyRadio.StationID() ||
+		flaky[1].RadioName != "flaky2" || flaky[1].Sessions != 2 || flaky[1].Underruns != 1 || flaky[1].Errors != 1 {
+		t.Fatalf("flaky = %+v", flaky)
+	}
+	flaky, err = s.GetFlakyStations(1)
+	check(t, err)
+	if len(flaky) != 1 {
+		t.Fatalf("limited flaky = %+v", flaky)
+	}
+}
+
+func testDataUsage(t *testing.T, s db.Store) {
+	check(t, s.AddDataUsage(base, radio("a").StationID(), 100))
+	check(t, s.AddDataUsage(base.AddDate(0, 0, 1), radio("a").StationID(), 50))
+	check(t, s.AddDataUsage(base, radio("b").StationID(), 20))
+	check(t, s.AddDataUsage(base, "", 5))
+	check(t, s.AddDataUsage(base.AddDate(0, 1, 0), radio("a").StationID(), 7))
+	if bytes, err := s.GetMonthlyDataUsage(base); err != nil || bytes != 175 {
+		t.Fatalf("usage = %d, %v", bytes, err)
+	}
+	if bytes, err := s.GetMonthlyDataUsage(base.AddDate(-1, 0, 0)); err != nil || bytes != 0 {
+		t.Fatalf("empty month usage = %d, %v", bytes, err)
+	}
+}
+
+func testStationEQ(t *testing.T, s db.Store) {
+	a := radio("a")
+	if setting, err := s.GetStationEQ(a); err != nil || setting != nil {
+		t.Fatalf("unset eq = %+v, %v", setting, err)
+	}
+	check(t, s.SaveStationEQ(model.EQSetting{StationID: a.StationID(), RadioName: "a", Preset: "rock", Gains: []float64{1, 2}}))
+	check(t, s.SaveStationEQ(model.EQSetting{StationID: a.StationID(), RadioName: "a", Preset: "custom", Gains: []float64{3, -1.5}}))
+	setting, err := s.GetStationEQ(a)
+	check(t, err)
+	if setting == nil || setting.Preset != "custom" || len(setting.Gains) != 2 || setting.Gains[1] != -1.5 {
+		t.Fatalf("eq = %+v", setting)
+	}
+
+	// 同名的另一个电台不共用均衡器设置
+	other := model.Radio{Name: "a", PlayURL: "http://example.com/other"}
+	if setting, err := s.GetStationEQ(other); err != nil || setting != nil {
+		t.Fatalf("same-name eq = %+v, %v", setting, err)
+	}
+}
+
+func testSettings(t *testing.T, s db.Store) {
+	if _, ok, err := s.GetSetting(db.SettingTheme); err != nil || ok {
+		t.Fatalf("unset setting ok = %v, %v", ok, err)
+	}
+	settings, err := db.LoadSettings(s)
+	check(t, err)
+	if settings.Volume != 100 || settings.LastView != "main" || settings.AutoResume {
+		t.Fatalf("default settings = %+v", settings)
+	}
+
+	settings.LastStation = radio("a")
+	settings.Volume = 40
+	settings.CollapsedCategories = map[string]bool{"音乐": true, "新闻": false}
+	settings.PreferredBitrate = 64
+	settings.AutoResume = true
+	check(t, db.SaveSettings(s, settings))
+	check(t, s.SetSetting(db.SettingVolume, "35"))
+
+	got, err := db.LoadSettings(s)
+	check(t, err)
+	if got.LastStation.PlayURL != radio("a").PlayURL || got.Volume != 35 || got.PreferredBitrate != 64 ||
+		!got.AutoResume || !got.CollapsedCategories["音乐"] || got.CollapsedCategories["新闻"] {
+		t.Fatalf("settings = %+v", got)
+	}
+
+	// 只保存最近播放的电台时不改动其他设置
+	check(t, db.SaveLastStation(s, radio("b")))
+	if got, err := db.LoadSettings(s); err != nil || got.LastStation.Name != "b" || got.Volume != 35 || !got.AutoResume {
+		t.Fatalf("after last station = %+v, %v", got, err)
+	}
+
+	check(t, s.SetSetting(db.SettingVolume, "loud"))
+	if got, err := db.LoadSettings(s); err != nil || got.Volume != 100 {
+		t.Fatalf("invalid volume = %d, %v", got.Volume, err)
+	}
+}
+
+func testDirectoryCache(t *testing.T, s db.Store) {
+	check(t, s.CacheDirectoryStations([]model.DirectoryStation{
+		{UUID: "1", Name: "Jazz FM", URL: "http://example.com/jazz.pls", URLResolved: "http://example.com/jazz",
+			Country: "Germany", Tags: "jazz,smooth", Codec: "MP3", Bitrate: 128, Votes: 10, ClickCount: 50},
+		{UUID: "2", Name: "Rock Radio", URL: "http://example.com/rock", Country: "Germany", Tags: "rock",
+			Codec: "AAC", Bitrate: 64, Votes: 30, ClickCount: 5},
+		{UUID: "3", Name: "Smooth Jazz", URL: "http://example.com/smooth", Country: "France", Tags: "jazz",
+			Codec: "MP3", Bitrate: 192, Votes: 20, ClickCount: 20},
+	}))
+	// 同一 UUID 再次缓存时覆盖旧数据
+	check(t, s.CacheDirectoryStations([]model.DirectoryStation{
+		{UUID: "2", Name: "Rock Radio", URL: "http://example.com/rock", Country: "Germany", Tags: "rock",
+			Codec: "AAC", Bitrate: 64, Votes: 40, ClickCount: 5},
+	}))
+
+	names := func(stations []model.DirectoryStation, err error) []string {
+		t.Helper()
+		check(t, err)
+		var names []string
+		for _, st := range stations {
+			names = append(names, st.Name)
+		}
+		return names
+	}
+	if got, want := names(s.SearchDirectoryCache(model.DirectoryQuery{}, "votes")),
+		[]string{"Rock Radio", "Smooth Jazz", "Jazz FM"}; !equal(got, want) {
+		t.Fatalf("by votes = %v, want %v", got, want)
+	}
+	if got, want := names(s.SearchDirectoryCache(model.DirectoryQuery{Tag: "JAZZ"}, "click_count")),
+		[]string{"Jazz FM", "Smooth Jazz"}; !equal(got, want) {
+		t.Fatalf("jazz by clicks = %v, want %v", got, want)
+	}
+	if got, want := names(s.SearchDirectoryCache(model.DirectoryQuery{Country: "germany", BitrateMin: 100}, "")),
+		[]string{"Jazz FM"}; !equal(got, want) {
+		t.Fatalf("germany >= 100 = %v, want %v", got, want)
+	}
+	if got := names(s.SearchDirectoryCache(model.DirectoryQuery{Limit: 1}, "votes")); len(got) != 1 {
+		t.Fatalf("limited = %v", got)
+	}
+
+	for _, url := range []string{"http://example.com/jazz", "http://example.com/jazz.pls"} {
+		if st, err := s.GetDirectoryStationByURL(url); err != nil || st == nil || st.UUID != "1" {
+			t.Fatalf("station by %s = %+v, %v", url, st, err)
+		}
+	}
+	if st, err := s.GetDirectoryStationByURL("http://example.com/missing"); err != nil || st != nil {
+		t.Fatalf("missing station = %+v, %v", st, err)
+	}
+}
+
+func testExternalSync(t *testing.T, s db.Store) {
+	const source = "ximalaya"
+	if last, err := s.LastSyncedAt(source); err != nil || !last.IsZero() {
+		t.Fatalf("never synced = %v, %v", last, err)
+	}
+	station := func(id, name string) model.ExternalStation {
+		return model.ExternalStation{ExternalID: id, Category: "新闻", Name: name, PlayURL: "http://example.com/" + id}
+	}
+
+	report, err := s.SyncExternalStations(source, []model.ExternalStation{station("1", "a"), station("2", "b"), station("1", "a")})
+	check(t, err)
+	if !equal(report.Added, []string{"a", "b"}) || report.Updated != 0 {
+		t.Fatalf("first sync = %+v", report)
+	}
+	categories, err := s.GetLocalCatalog()
+	check(t, err)
+	a := categories[0].RadioList[0]
+	if a.ID != source+":1" {
+		t.Fatalf("synced station id = %q", a.ID)
+	}
+	check(t, s.AddFavorite(a))
+	listen(t, s, a, base, 10)
+
+	// 改名并移除 b
+	report, err = s.SyncExternalStations(source, []model.ExternalStation{station("1", "a2")})
+	check(t, err)
+	if report.Updated != 1 || len(report.Renamed) != 1 || report.Renamed[0] != (model.Rename{Old: "a", New: "a2"}) ||
+		!equal(report.Removed, []string{"b"}) {
+		t.Fatalf("second sync = %+v", report)
+	}
+	if got, want := catalogNames(t, s), []string{"新闻:", "a2"}; !equal(got, want) {
+		t.Fatalf("catalog = %v, want %v", got, want)
+	}
+	favorites, err := s.GetFavorites()
+	check(t, err)
+	if len(favorites) != 1 || favorites[0].ID != a.ID || favorites[0].Name != "a2" {
+		t.Fatalf("favorites = %+v", favorites)
+	}
+	history, err := s.GetHistory(-1)
+	check(t, err)
+	if len(history) != 1 || history[0].RadioName != "a2" {
+		t.Fatalf("history = %+v", history)
+	}
+
+	report, err = s.SyncExternalStations(source, []model.ExternalStation{station("1", "a2"), station("2", "b")})
+	check(t, err)
+	if !equal(report.Restored, []string{"b"}) || len(report.Added) != 0 {
+		t.Fatalf("third sync = %+v", report)
+	}
+	if last, err := s.LastSyncedAt(source); err != nil || last.IsZero() {
+		t.Fatalf("last synced = %v, %v", last, err)
+	}
+}
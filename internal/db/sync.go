@@ -0,0 +1,150 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"FMgo/internal/model"
+)
+
+// SyncExternalStations 将外部目录的电台同步到本地目录：新增、更新、检测改名与下架。
+// 改名或更换播放地址时同步更新收藏与历史记录
+func (d *Database) SyncExternalStations(source string, stations []model.ExternalStation) (*model.SyncReport, error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	type existing struct {
+		id      int64
+		name    string
+		playURL string
+		removed bool
+	}
+	rows, err := tx.Query(`
+		SELECT id, external_id, name, play_url, removed_at IS NOT NULL
+		FROM local_stations WHERE source = ?
+	`, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load synced stations: %v", err)
+	}
+	known := make(map[string]existing)
+	for rows.Next() {
+		var e existing
+		var externalID string
+		if err := rows.Scan(&e.id, &externalID, &e.name, &e.playURL, &e.removed); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		known[externalID] = e
+	}
+	rows.Close()
+
+	now := time.Now()
+	report := &model.SyncReport{Source: source, SyncedAt: now}
+	seen := make(map[string]bool)
+	categoryIDs := make(map[string]int64)
+
+	for _, s := range stations {
+		if seen[s.ExternalID] {
+			continue
+		}
+		seen[s.ExternalID] = true
+
+		categoryID, ok := categoryIDs[s.Category]
+		if !ok {
+			categoryID, err = addLocalCategory(tx, s.Category)
+			if err != nil {
+				return nil, err
+			}
+			categoryIDs[s.Category] = categoryID
+		}
+
+		e, ok := known[s.ExternalID]
+		if !ok {
+			if _, err := tx.Exec(`
+				INSERT INTO local_stations (
+					category_id, name, play_url, position, source, external_id, province, logo, program, synced_at
+				) VALUES (?, ?, ?, (SELECT COALESCE(MAX(position), 0) + 1 FROM local_stations WHERE category_id = ?), ?, ?, ?, ?, ?, ?)
+			`, categoryID, s.Name, s.PlayURL, categoryID, source, s.ExternalID, s.Province, s.Logo, s.Program, now); err != nil {
+				return nil, fmt.Errorf("failed to add synced station: %v", err)
+			}
+			report.Added = append(report.Added, s.Name)
+			continue
+		}
+
+		if e.name != s.Name {
+			report.Renamed = append(report.Renamed, model.Rename{Old: e.name, New: s.Name})
+		}
+		if e.name != s.Name || e.playURL != s.PlayURL {
+			radio := model.Radio{ID: syncedStationID(source, s.ExternalID), Name: s.Name, PlayURL: s.PlayURL}
+			// 旧版本按播放地址生成的 ID 一并迁移到稳定 ID
+			if err := updateStationRefs(tx, radio, radio.ID, model.URLStationID(e.playURL)); err != nil {
+				return nil, err
+			}
+		}
+		if e.removed {
+			report.Restored = append(report.Restored, s.Name)
+		}
+		if _, err := tx.Exec(`
+			UPDATE local_stations
+			SET category_id = ?, name = ?, play_url = ?, province = ?, logo = ?, program = ?,
+				synced_at = ?, removed_at = NULL
+			WHERE id = ?
+		`, categoryID, s.Name, s.PlayURL, s.Province, s.Logo, s.Program, now, e.id); err != nil {
+			return nil, fmt.Errorf("failed to update synced station: %v", err)
+		}
+		report.Updated++
+	}
+
+	for externalID, e := range known {
+		if seen[externalID] || e.removed {
+			continue
+		}
+		if _, err := tx.Exec(`UPDATE local_stations SET removed_at = ? WHERE id = ?`, now, e.id); err != nil {
+			return nil, fmt.Errorf("failed to mark station removed: %v", err)
+		}
+		report.Removed = append(report.Removed, e.name)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT OR REPLACE INTO sync_state (source, synced_at) VALUES (?, ?)
+	`, source, now); err != nil {
+		return nil, fmt.Errorf("failed to save sync state: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit sync: %v", err)
+	}
+	return report, nil
+}
+
+// LastSyncedAt 返回外部目录上次同步的时间，从未同步时返回零值
+func (d *Database) LastSyncedAt(source string) (time.Time, error) {
+	var t time.Time
+	err := d.db.QueryRow(`SELECT synced_at FROM sync_state WHERE source = ?`, source).Scan(&t)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get sync state: %v", err)
+	}
+	return t, nil
+}
+
+// addLocalCategory 在事务中添加本地分类，已存在时返回其 ID
+func addLocalCategory(tx *sql.Tx, name string) (int64, error) {
+	if _, err := tx.Exec(`
+		INSERT OR IGNORE INTO local_categories (name, position)
+		VALUES (?, (SELECT COALESCE(MAX(position), 0) + 1 FROM local_categories))
+	`, name); err != nil {
+		return 0, fmt.Errorf("failed to add local category: %v", err)
+	}
+	var id int64
+	if err := tx.QueryRow(`SELECT id FROM local_categories WHERE name = ?`, name).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to get local category: %v", err)
+	}
+	return id, nil
+}
@@ -0,0 +1,30 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// AddDataUsage 累加电台在指定时间所在月份的流量使用量
+func (d *Database) AddDataUsage(at time.Time, stationID string, bytes int64) error {
+	_, err := d.db.Exec(`
+		INSERT INTO data_usage (month, station_id, bytes) VALUES (?, ?, ?)
+		ON CONFLICT(month, station_id) DO UPDATE SET bytes = bytes + excluded.bytes
+	`, at.Format("2006-01"), stationID, bytes)
+	if err != nil {
+		return fmt.Errorf("failed to add data usage: %v", err)
+	}
+	return nil
+}
+
+// GetMonthlyDataUsage 获取指定时间所在月份全部电台的流量使用量
+func (d *Database) GetMonthlyDataUsage(at time.Time) (int64, error) {
+	var bytes int64
+	err := d.db.QueryRow(`
+		SELECT COALESCE(SUM(bytes), 0) FROM data_usage WHERE month = ?
+	`, at.Format("2006-01")).Scan(&bytes)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get data usage: %v", err)
+	}
+	return bytes, nil
+}
@@ -0,0 +1,349 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"FMgo/internal/model"
+)
+
+// UserDataFile 是同步目录中保存收藏与收听历史的文件名
+const UserDataFile = "fmgo-sync.json"
+
+// userDataVersion 是同步文件的格式版本
+const userDataVersion = 1
+
+// userData 是同步文件的内容
+type userData struct {
+	Version   int              `json:"version"`
+	UpdatedAt time.Time        `json:"updated_at"`
+	Favorites []syncedFavorite `json:"favorites"`
+	Sessions  []syncedSession  `json:"sessions"`
+	// HistoryCleared 是各电台（键为空时为全部电台）已清除的收听记录范围，见 history_clears 表
+	HistoryCleared map[string]time.Time `json:"history_cleared,omitempty"`
+}
+
+// syncedFavorite 是同步文件中的一条收藏，Deleted 表示收藏已在 UpdatedAt 时被移除
+type syncedFavorite struct {
+	StationID string    `json:"station_id"`
+	RadioName string    `json:"radio_name,omitempty"`
+	PlayURL   string    `json:"play_url,omitempty"`
+	Folder    string    `json:"folder,omitempty"`
+	Note      string    `json:"note,omitempty"`
+	Rating    int       `json:"rating,omitempty"`
+	Position  int       `json:"position,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Deleted   bool      `json:"deleted,omitempty"`
+}
+
+// syncedSession 是同步文件中的一次收听会话，按电台 ID 与开始时间识别
+type syncedSession struct {
+	StationID     string    `json:"station_id"`
+	RadioName     string    `json:"radio_name"`
+	PlayURL       string    `json:"play_url"`
+	StartedAt     time.Time `json:"started_at"`
+	EndedAt       time.Time `json:"ended_at"`
+	ListenedMs    int64     `json:"listened_ms"`
+	EndReason     string    `json:"end_reason"`
+	BytesReceived int64     `json:"bytes_received"`
+}
+
+func (s syncedSession) key() string {
+	return s.StationID + "|" + s.StartedAt.UTC().Format(time.RFC3339Nano)
+}
+
+// migrateFavoriteSync 为收藏记录修改时间，并记录移除的收藏，合并同步文件时按时间解决冲突
+func migrateFavoriteSync(tx *sql.Tx) error {
+	// ALTER TABLE 不支持非常量默认值，修改时间由写入收藏的语句设置
+	if _, err := tx.Exec(`ALTER TABLE favorites ADD COLUMN updated_at DATETIME`); err != nil {
+		return fmt.Errorf("failed to add favorites.updated_at: %v", err)
+	}
+	if _, err := tx.Exec(`UPDATE favorites SET updated_at = COALESCE(created_at, CURRENT_TIMESTAMP)`); err != nil {
+		return fmt.Errorf("failed to backfill favorites.updated_at: %v", err)
+	}
+	if _, err := tx.Exec(`
+		CREATE TABLE favorite_tombstones (
+			station_id TEXT PRIMARY KEY,
+			deleted_at DATETIME NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create favorite_tombstones table: %v", err)
+	}
+	return nil
+}
+
+// addTombstone 记录收藏在 at 时被移除
+func addTombstone(tx *sql.Tx, stationID string, at time.Time) error {
+	if _, err := tx.Exec(`
+		INSERT INTO favorite_tombstones (station_id, deleted_at) VALUES (?, ?)
+		ON CONFLICT(station_id) DO UPDATE SET deleted_at = excluded.deleted_at
+	`, stationID, at); err != nil {
+		return fmt.Errorf("failed to record removed favorite: %v", err)
+	}
+	return nil
+}
+
+// MergeUserData 将收藏与收听历史与目录 dir 中的同步文件双向合并，再将合并结果写回同步文件。
+// 同一电台的收藏以修改（或移除）时间较新的一方为准；收听会话取并集，任一方清除过的记录两边都删除
+func (d *Database) MergeUserData(dir string) (*model.MergeReport, error) {
+	path := filepath.Join(dir, UserDataFile)
+	remote, err := readUserData(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	report := &model.MergeReport{}
+	favorites, err := loadSyncedFavorites(tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range remote.Favorites {
+		local, ok := favorites[r.StationID]
+		if ok && !r.UpdatedAt.After(local.UpdatedAt) {
+			continue
+		}
+		switch {
+		case r.Deleted:
+			if _, err := tx.Exec(`DELETE FROM favorites WHERE station_id = ?`, r.StationID); err != nil {
+				return nil, fmt.Errorf("failed to remove favorite: %v", err)
+			}
+			if err := addTombstone(tx, r.StationID, r.UpdatedAt); err != nil {
+				return nil, err
+			}
+			if ok && !local.Deleted {
+				report.FavoritesRemoved++
+			}
+		default:
+			if err := putFavorite(tx, r); err != nil {
+				return nil, err
+			}
+			if ok && !local.Deleted {
+				report.FavoritesUpdated++
+			} else {
+				report.FavoritesAdded++
+			}
+		}
+		favorites[r.StationID] = r
+	}
+
+	clears, err := loadHistoryClears(tx)
+	if err != nil {
+		return nil, err
+	}
+	for stationID, before := range remote.HistoryCleared {
+		if current, ok := clears[stationID]; ok && !before.After(current) {
+			continue
+		}
+		if err := addHistoryClear(tx, stationID, before); err != nil {
+			return nil, err
+		}
+		removed, err := applyHistoryClear(tx, stationID, before)
+		if err != nil {
+			return nil, err
+		}
+		clears[stationID] = before
+		report.SessionsRemoved += int(removed)
+	}
+
+	sessions, err := loadSyncedSessions(tx)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(sessions))
+	for _, s := range sessions {
+		known[s.key()] = true
+	}
+	remoteKeys := make(map[string]bool, len(remote.Sessions))
+	for _, r := range remote.Sessions {
+		remoteKeys[r.key()] = true
+		if known[r.key()] || historyCleared(clears, r.StationID, r.StartedAt) {
+			continue
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO listening_sessions
+				(station_id, radio_name, play_url, started_at, ended_at, listened_ms, end_reason, bytes_received)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`, r.StationID, r.RadioName, r.PlayURL, r.StartedAt, r.EndedAt, r.ListenedMs, r.EndReason, r.BytesReceived); err != nil {
+			return nil, fmt.Errorf("failed to import session: %v", err)
+		}
+		known[r.key()] = true
+		sessions = append(sessions, r)
+		report.SessionsImported++
+	}
+	for _, s := range sessions {
+		if !remoteKeys[s.key()] {
+			report.SessionsExported++
+		}
+	}
+
+	merged := userData{Version: userDataVersion, UpdatedAt: time.Now(), Sessions: sessions, HistoryCleared: clears}
+	for _, f := range favorites {
+		merged.Favorites = append(merged.Favorites, f)
+	}
+	sort.Slice(merged.Favorites, func(i, j int) bool {
+		return merged.Favorites[i].StationID < merged.Favorites[j].StationID
+	})
+	sort.Slice(merged.Sessions, func(i, j int) bool {
+		return merged.Sessions[i].StartedAt.Before(merged.Sessions[j].StartedAt)
+	})
+	// 先写同步文件再提交：写入失败时本地数据保持不变，下次同步重新合并
+	if err := writeUserData(path, merged); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit merge: %v", err)
+	}
+	report.Path = path
+	return report, nil
+}
+
+// loadSyncedFavorites 返回本地的收藏与已移除收藏的记录，按电台 ID 索引
+func loadSyncedFavorites(tx *sql.Tx) (map[string]syncedFavorite, error) {
+	favorites := make(map[string]syncedFavorite)
+	rows, err := tx.Query(`SELECT station_id, deleted_at FROM favorite_tombstones`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load removed favorites: %v", err)
+	}
+	for rows.Next() {
+		f := syncedFavorite{Deleted: true}
+		if err := rows.Scan(&f.StationID, &f.UpdatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		favorites[f.StationID] = f
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = tx.Query(`
+		SELECT station_id, radio_name, play_url, folder, note, rating, position,
+			COALESCE(created_at, CURRENT_TIMESTAMP), COALESCE(updated_at, created_at, CURRENT_TIMESTAMP)
+		FROM favorites
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load favorites: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var f syncedFavorite
+		var createdAt, updatedAt string
+		if err := rows.Scan(&f.StationID, &f.RadioName, &f.PlayURL, &f.Folder, &f.Note, &f.Rating, &f.Position,
+			&createdAt, &updatedAt); err != nil {
+			return nil, err
+		}
+		if f.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+			return nil, err
+		}
+		if f.UpdatedAt, err = parseSQLiteTime(updatedAt); err != nil {
+			return nil, err
+		}
+		// 重新收藏后残留的移除记录以较新的一方为准
+		if removed, ok := favorites[f.StationID]; ok && removed.UpdatedAt.After(f.UpdatedAt) {
+			continue
+		}
+		favorites[f.StationID] = f
+	}
+	return favorites, rows.Err()
+}
+
+// loadSyncedSessions 返回本地已结束的收听会话
+func loadSyncedSessions(tx *sql.Tx) ([]syncedSession, error) {
+	rows, err := tx.Query(`
+		SELECT station_id, radio_name, play_url, started_at, ended_at, listened_ms, end_reason, bytes_received
+		FROM listening_sessions
+		WHERE ended_at IS NOT NULL
+		ORDER BY started_at, id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sessions: %v", err)
+	}
+	defer rows.Close()
+
+	var sessions []syncedSession
+	for rows.Next() {
+		var s syncedSession
+		if err := rows.Scan(&s.StationID, &s.RadioName, &s.PlayURL, &s.StartedAt, &s.EndedAt,
+			&s.ListenedMs, &s.EndReason, &s.BytesReceived); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+// putFavorite 以同步文件中的收藏覆盖本地收藏，并清除其移除记录
+func putFavorite(tx *sql.Tx, f syncedFavorite) error {
+	if _, err := tx.Exec(`
+		INSERT INTO favorites (station_id, radio_name, play_url, folder, note, rating, position, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(station_id) DO UPDATE SET
+			radio_name = excluded.radio_name, play_url = excluded.play_url, folder = excluded.folder,
+			note = excluded.note, rating = excluded.rating, position = excluded.position,
+			updated_at = excluded.updated_at
+	`, f.StationID, f.RadioName, f.PlayURL, f.Folder, f.Note, f.Rating, f.Position, f.CreatedAt, f.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to merge favorite: %v", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM favorite_tombstones WHERE station_id = ?`, f.StationID); err != nil {
+		return fmt.Errorf("failed to merge favorite: %v", err)
+	}
+	return nil
+}
+
+// readUserData 读取同步文件，文件不存在时返回空内容
+func readUserData(path string) (userData, error) {
+	var data userData
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return data, nil
+	}
+	if err != nil {
+		return data, fmt.Errorf("failed to read sync file: %v", err)
+	}
+	if err := json.Unmarshal(content, &data); err != nil {
+		return data, fmt.Errorf("failed to parse sync file %s: %v", path, err)
+	}
+	if data.Version > userDataVersion {
+		return data, fmt.Errorf("sync file version %d is newer than supported version %d", data.Version, userDataVersion)
+	}
+	return data, nil
+}
+
+// writeUserData 先写入临时文件再改名，避免同步工具读到写了一半的文件
+func writeUserData(path string, data userData) error {
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode sync file: %v", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+UserDataFile+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write sync file: %v", err)
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write sync file: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write sync file: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write sync file: %v", err)
+	}
+	return nil
+}
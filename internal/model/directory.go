@@ -0,0 +1,67 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// DirectoryStation represents a station from an online directory such as Radio Browser
+type DirectoryStation struct {
+	UUID        string    `json:"stationuuid"`
+	Name        string    `json:"name"`
+	URL         string    `json:"url"`
+	URLResolved string    `json:"url_resolved"`
+	Homepage    string    `json:"homepage"`
+	Favicon     string    `json:"favicon"`
+	Tags        string    `json:"tags"`
+	Country     string    `json:"country"`
+	CountryCode string    `json:"countrycode"`
+	Language    string    `json:"language"`
+	Codec       string    `json:"codec"`
+	Bitrate     int       `json:"bitrate"`
+	Votes       int       `json:"votes"`
+	ClickCount  int       `json:"clickcount"`
+	FetchedAt   time.Time `json:"-"`
+}
+
+// StreamURL returns the resolved stream URL when available
+func (s DirectoryStation) StreamURL() string {
+	if s.URLResolved != "" {
+		return s.URLResolved
+	}
+	return s.URL
+}
+
+// Radio converts the directory entry into a Radio with its metadata
+func (s DirectoryStation) Radio() Radio {
+	var tags []string
+	for _, tag := range strings.Split(s.Tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return Radio{
+		ID:       s.UUID,
+		Name:     strings.TrimSpace(s.Name),
+		PlayURL:  s.StreamURL(),
+		Homepage: s.Homepage,
+		Country:  s.Country,
+		Language: s.Language,
+		Tags:     tags,
+		Codec:    s.Codec,
+		Bitrate:  s.Bitrate,
+		Logo:     s.Favicon,
+	}
+}
+
+// DirectoryQuery represents search filters for an online directory
+type DirectoryQuery struct {
+	Name       string
+	Country    string
+	Language   string
+	Tag        string
+	Region     string // province, state or city
+	Codec      string
+	BitrateMin int
+	Limit      int
+}
@@ -0,0 +1,9 @@
+package model
+
+// EQSetting represents the equalizer preset remembered for a station
+type EQSetting struct {
+	StationID string    `json:"station_id"`
+	RadioName string    `json:"radio_name"`
+	Preset    string    `json:"preset"`
+	Gains     []float64 `json:"gains"`
+}
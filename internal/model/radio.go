@@ -1,11 +1,44 @@
 package model
 
-import "time"
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"strings"
+	"time"
+)
 
-// Radio represents a single radio station
+// Radio represents a single radio station. Only Name and PlayURL are required;
+// the remaining metadata is optional in catalog files.
 type Radio struct {
-	Name    string `json:"name"`
-	PlayURL string `json:"playUrl"`
+	ID          string   `json:"id,omitempty"` // stable identifier
+	Name        string   `json:"name"`
+	PlayURL     string   `json:"playUrl"`
+	Description string   `json:"description,omitempty"`
+	Homepage    string   `json:"homepage,omitempty"`
+	Country     string   `json:"country,omitempty"`
+	Province    string   `json:"province,omitempty"`
+	City        string   `json:"city,omitempty"`
+	Language    string   `json:"language,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Codec       string   `json:"codec,omitempty"`
+	Bitrate     int      `json:"bitrate,omitempty"` // kbps
+	Logo        string   `json:"logo,omitempty"`
+	Source      string   `json:"-"` // catalog provider the station came from
+}
+
+// StationID returns the station's stable identifier: the explicit ID when set,
+// otherwise one derived from the stream URL
+func (r Radio) StationID() string {
+	if r.ID != "" {
+		return r.ID
+	}
+	return URLStationID(r.PlayURL)
+}
+
+// URLStationID derives a station identifier from the full SHA-1 of a stream URL
+func URLStationID(playURL string) string {
+	sum := sha1.Sum([]byte(strings.TrimSpace(playURL)))
+	return "url:" + hex.EncodeToString(sum[:])
 }
 
 // Category represents a category of radio stations
@@ -14,10 +47,64 @@ type Category struct {
 	RadioList []Radio `json:"radioList"`
 }
 
-// PlayHistory represents a play history record
+// Favorite represents a favorited station with its folder, note and rating
+type Favorite struct {
+	Radio
+	Folder   string `json:"folder"` // empty for favorites not in any folder
+	Note     string `json:"note"`
+	Rating   int    `json:"rating"`   // 1-5, 0 means unrated
+	Position int    `json:"position"` // order within the folder
+}
+
+// Listening session end reasons
+const (
+	EndReasonStop   = "stop"   // stopped by the user
+	EndReasonSwitch = "switch" // switched to another station
+	EndReasonError  = "error"  // playback failed
+	EndReasonExit   = "exit"   // the program exited
+)
+
+// PlayHistory represents a listening session: one station played from start to stop
 type PlayHistory struct {
-	ID        int64     `json:"id"`
-	RadioName string    `json:"radio_name"`
-	PlayURL   string    `json:"play_url"`
-	PlayedAt  time.Time `json:"played_at"`
+	ID            int64      `json:"id"`
+	StationID     string     `json:"station_id"`
+	RadioName     string     `json:"radio_name"`
+	PlayURL       string     `json:"play_url"`
+	PlayedAt      time.Time  `json:"played_at"`
+	EndedAt       *time.Time `json:"ended_at"`    // nil while still listening
+	ListenedMs    int64      `json:"listened_ms"` // excluding pauses
+	EndReason     string     `json:"end_reason"`
+	BytesReceived int64      `json:"bytes_received"`
+}
+
+// Listened returns how long the session was listened to, excluding pauses
+func (h PlayHistory) Listened() time.Duration {
+	return time.Duration(h.ListenedMs) * time.Millisecond
+}
+
+// StationHistory is the play history of one station aggregated over its sessions
+type StationHistory struct {
+	StationID  string
+	RadioName  string // name of the most recent session
+	PlayURL    string
+	Sessions   int
+	Listened   time.Duration
+	LastPlayed time.Time
+}
+
+// Settings represents user preferences persisted across restarts
+type Settings struct {
+	LastStation         Radio           `json:"last_station"` // zero until a station has been played
+	LastView            string          `json:"last_view"`    // main, history or favorites
+	Volume              int             `json:"volume"`       // percent, 0-100
+	CollapsedCategories map[string]bool `json:"collapsed_categories"`
+	Theme               string          `json:"theme"`
+	PreferredBitrate    int             `json:"preferred_bitrate"` // kbps, 0 means the highest available
+	AudioBackend        string          `json:"audio_backend"`     // empty means automatic
+	AutoResume          bool            `json:"auto_resume"`       // play LastStation on launch
+}
+
+// DefaultSettings returns the settings used before the user changes anything
+func DefaultSettings() Settings {
+	return Settings{LastView: "main", Volume: 100, Theme: "default"}
 }
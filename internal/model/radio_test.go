@@ -0,0 +1,38 @@
+package model
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestStationID(t *testing.T) {
+	urlID := regexp.MustCompile(`^url:[0-9a-f]{40}$`)
+
+	a := Radio{Name: "a", PlayURL: "http://example.com/a"}
+	if id := a.StationID(); !urlID.MatchString(id) {
+		t.Errorf("derived id = %q, want url:<sha1>", id)
+	}
+	if a.StationID() != URLStationID("http://example.com/a") {
+		t.Error("StationID differs from URLStationID of the same URL")
+	}
+
+	tests := []struct {
+		name  string
+		a, b  Radio
+		equal bool
+	}{
+		{"renamed station keeps its id", a, Radio{Name: "b", PlayURL: "http://example.com/a"}, true},
+		{"surrounding whitespace is ignored", a, Radio{Name: "a", PlayURL: " http://example.com/a\n"}, true},
+		{"same name, different url", a, Radio{Name: "a", PlayURL: "http://example.com/b"}, false},
+		{"explicit id wins over url", Radio{ID: "xmly:1", PlayURL: "http://example.com/a"}, Radio{ID: "xmly:1", PlayURL: "http://example.com/moved"}, true},
+		{"explicit id differs from derived id", Radio{ID: "xmly:1", PlayURL: "http://example.com/a"}, a, false},
+	}
+	for _, tt := range tests {
+		if got := tt.a.StationID() == tt.b.StationID(); got != tt.equal {
+			t.Errorf("%s: %q == %q is %v, want %v", tt.name, tt.a.StationID(), tt.b.StationID(), got, tt.equal)
+		}
+	}
+	if id := (Radio{ID: "xmly:1"}).StationID(); id != "xmly:1" {
+		t.Errorf("explicit id = %q", id)
+	}
+}
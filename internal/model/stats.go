@@ -0,0 +1,58 @@
+package model
+
+import "time"
+
+// StreamStats represents the network summary of a single play session
+type StreamStats struct {
+	ID            int64     `json:"id"`
+	StationID     string    `json:"station_id"`
+	RadioName     string    `json:"radio_name"`
+	PlayURL       string    `json:"play_url"`
+	StartedAt     time.Time `json:"started_at"`
+	EndedAt       time.Time `json:"ended_at"`
+	BytesReceived int64     `json:"bytes_received"`
+	Segments      int       `json:"segments"`
+	AvgLatencyMs  int64     `json:"avg_latency_ms"`
+	AvgThroughput float64   `json:"avg_throughput"`
+	Underruns     int       `json:"underruns"`
+	Reconnects    int       `json:"reconnects"`
+	Errors        int       `json:"errors"`
+}
+
+// StationHealth represents aggregated stream health of a station across sessions
+type StationHealth struct {
+	StationID  string `json:"station_id"`
+	RadioName  string `json:"radio_name"`
+	Sessions   int    `json:"sessions"`
+	Underruns  int    `json:"underruns"`
+	Reconnects int    `json:"reconnects"`
+	Errors     int    `json:"errors"`
+}
+
+// StationTotal represents the listening time of one station over a period
+type StationTotal struct {
+	StationID       string `json:"station_id"`
+	RadioName       string `json:"radio_name"`
+	Sessions        int    `json:"sessions"`
+	ListenedSeconds int64  `json:"listened_seconds"`
+}
+
+// CategoryTotal represents the listening time of one catalog category over a period
+type CategoryTotal struct {
+	Category        string `json:"category"`
+	ListenedSeconds int64  `json:"listened_seconds"`
+}
+
+// ListeningReport summarizes listening sessions
+type ListeningReport struct {
+	GeneratedAt   time.Time       `json:"generated_at"`
+	Days          int             `json:"days"`           // period covered by totals, distributions and categories; 0 means all time
+	TotalSeconds  int64           `json:"total_seconds"`  // listened within the period
+	TopWeek       []StationTotal  `json:"top_week"`       // since Monday
+	TopMonth      []StationTotal  `json:"top_month"`      // since the first of the month
+	ByHour        [24]int64       `json:"by_hour"`        // seconds per hour of day, local time
+	ByWeekday     [7]int64        `json:"by_weekday"`     // seconds per weekday, Monday first
+	CurrentStreak int             `json:"current_streak"` // consecutive days with listening, ending today or yesterday
+	LongestStreak int             `json:"longest_streak"`
+	Categories    []CategoryTotal `json:"categories"`
+}
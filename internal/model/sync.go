@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// ExternalStation represents a station synced from an external directory into the local catalog
+type ExternalStation struct {
+	ExternalID string `json:"external_id"`
+	Category   string `json:"category"`
+	Name       string `json:"name"`
+	PlayURL    string `json:"play_url"`
+	Province   string `json:"province"`
+	Logo       string `json:"logo"`
+	Program    string `json:"program"`
+}
+
+// Rename represents a station whose display name changed upstream
+type Rename struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// SyncReport represents the outcome of syncing an external directory
+type SyncReport struct {
+	Source   string    `json:"source"`
+	SyncedAt time.Time `json:"synced_at"`
+	Added    []string  `json:"added"`
+	Updated  int       `json:"updated"`
+	Renamed  []Rename  `json:"renamed"`
+	Removed  []string  `json:"removed"`
+	Restored []string  `json:"restored"`
+}
+
+// MergeReport represents the outcome of merging favorites and history with a sync file
+type MergeReport struct {
+	Path             string `json:"path"`
+	FavoritesAdded   int    `json:"favorites_added"`
+	FavoritesUpdated int    `json:"favorites_updated"`
+	FavoritesRemoved int    `json:"favorites_removed"`
+	SessionsImported int    `json:"sessions_imported"`
+	SessionsExported int    `json:"sessions_exported"`
+	SessionsRemoved  int    `json:"sessions_removed"`
+}
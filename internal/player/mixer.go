@@ -0,0 +1,225 @@
+package player
+
+import (
+	"FMgo/internal/audio"
+	"FMgo/internal/config"
+	"FMgo/internal/logger"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// mixChunkFrames 是混音器每次输出的帧数
+	mixChunkFrames = 1024
+	// mixLead 是混音器允许领先实际播放进度的时长
+	mixLead = 500 * time.Millisecond
+)
+
+// mixer 持有音频输出，将当前电台与正在淡入的电台混合，经均衡器与音量调整后写入音频后端
+type mixer struct {
+	mu      sync.Mutex
+	backend string
+	eq      *audio.Equalizer
+	sink    audio.Sink
+	stop    chan struct{}
+	done    chan struct{}
+	paused  bool
+	volume  float64 // 输出增益，1 表示原始音量
+
+	current    *pcmSource
+	next       *pcmSource
+	fadeLen    int // 交叉淡入的总帧数
+	fadePos    int
+	onFadeDone func()
+}
+
+func newMixer(backend string, eq *audio.Equalizer) *mixer {
+	return &mixer{backend: backend, eq: eq, volume: 1}
+}
+
+// play 立即切换到 src，不做淡入
+func (m *mixer) play(src *pcmSource) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.finishFadeLocked()
+	m.current = src
+	return m.ensureRunningLocked()
+}
+
+// crossfade 在 d 时长内从当前电台淡入到 src，完成后调用 done
+func (m *mixer) crossfade(src *pcmSource, d time.Duration, done func()) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.finishFadeLocked()
+	if m.current == nil || d <= 0 {
+		m.current = src
+		if done != nil {
+			go done()
+		}
+		return m.ensureRunningLocked()
+	}
+	m.next = src
+	m.fadeLen = samplesFor(d) / audio.Channels
+	m.fadePos = 0
+	m.onFadeDone = done
+	return m.ensureRunningLocked()
+}
+
+// setPaused 暂停或恢复读取电台数据，暂停期间输出静音以保持音频后端连接
+func (m *mixer) setPaused(paused bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.paused = paused
+}
+
+// setVolume 设置输出增益，正在播放时立即生效
+func (m *mixer) setVolume(volume float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.volume = volume
+}
+
+// applyVolume 按输出增益缩放样本
+func (m *mixer) applyVolume(out []int16) {
+	m.mu.Lock()
+	volume := m.volume
+	m.mu.Unlock()
+
+	if volume == 1 {
+		return
+	}
+	for i := range out {
+		out[i] = audio.ClampSample(float64(out[i]) * volume)
+	}
+}
+
+// halt 停止输出并关闭音频后端
+func (m *mixer) halt() {
+	m.mu.Lock()
+	m.finishFadeLocked()
+	m.current = nil
+	m.paused = false
+	stop, done := m.stop, m.done
+	m.stop, m.done = nil, nil
+	m.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.sink != nil {
+		m.sink.Close()
+		m.sink = nil
+	}
+}
+
+// finishFadeLocked 立即完成正在进行的交叉淡入
+func (m *mixer) finishFadeLocked() {
+	if m.next != nil {
+		m.current = m.next
+		m.next = nil
+	}
+	if m.onFadeDone != nil {
+		go m.onFadeDone()
+		m.onFadeDone = nil
+	}
+}
+
+func (m *mixer) ensureRunningLocked() error {
+	if m.stop != nil {
+		return nil
+	}
+	if m.sink == nil {
+		sink, err := audio.OpenSink(m.backend, config.TempDir)
+		if err != nil {
+			return err
+		}
+		m.sink = sink
+	}
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+	go m.loop(m.sink, m.stop, m.done)
+	return nil
+}
+
+// loop 按实时速率混音并写入音频后端
+func (m *mixer) loop(sink audio.Sink, stop, done chan struct{}) {
+	defer close(done)
+
+	out := make([]int16, mixChunkFrames*audio.Channels)
+	in := make([]int16, len(out))
+	var buf []byte
+	start := time.Now()
+	written := 0
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		m.mix(out, in)
+		m.eq.Process(out)
+		m.applyVolume(out)
+		buf = audio.SamplesToBytes(out, buf)
+		if _, err := sink.Write(buf); err != nil {
+			logger.Error("写入音频输出失败: %v", err)
+			return
+		}
+
+		written += len(out)
+		if ahead := durationOf(written) - time.Since(start) - mixLead; ahead > 0 {
+			select {
+			case <-stop:
+				return
+			case <-time.After(ahead):
+			}
+		}
+	}
+}
+
+// mix 生成一块输出样本，交叉淡入期间按等功率曲线混合两个电台
+func (m *mixer) mix(out, in []int16) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range out {
+		out[i] = 0
+	}
+	if m.paused {
+		return
+	}
+	if m.current != nil {
+		m.current.read(out)
+	}
+	if m.next == nil {
+		return
+	}
+
+	for i := range in {
+		in[i] = 0
+	}
+	m.next.read(in)
+	for frame := 0; frame < len(out)/audio.Channels; frame++ {
+		t := float64(m.fadePos) / float64(m.fadeLen)
+		if t > 1 {
+			t = 1
+		}
+		gainOut := math.Cos(t * math.Pi / 2)
+		gainIn := math.Sin(t * math.Pi / 2)
+		for ch := 0; ch < audio.Channels; ch++ {
+			i := frame*audio.Channels + ch
+			out[i] = audio.ClampSample(float64(out[i])*gainOut + float64(in[i])*gainIn)
+		}
+		m.fadePos++
+	}
+	if m.fadePos >= m.fadeLen {
+		m.finishFadeLocked()
+	}
+}
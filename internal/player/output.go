@@ -0,0 +1,80 @@
+package player
+
+import (
+	"FMgo/internal/config"
+	"FMgo/internal/logger"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// output 接收下载到的 AAC 数据并负责播放
+type output interface {
+	io.Writer
+	Close()
+}
+
+// fileOutput 将 AAC 追加到缓冲文件并用 afplay 播放
+type fileOutput struct {
+	mu    sync.Mutex
+	path  string
+	file  *os.File
+	cmd   *exec.Cmd
+	stats *statsCollector
+	err   error // afplay 异常退出的原因，之后的写入都返回该错误
+}
+
+func openFileOutput(stats *statsCollector) (output, error) {
+	// 创建固定的缓冲文件
+	path := filepath.Join(config.TempDir, "stream-buffer.aac")
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("创建缓冲文件失败: %v", err)
+	}
+	return &fileOutput{path: path, file: file, stats: stats}, nil
+}
+
+func (o *fileOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.err != nil {
+		return 0, o.err
+	}
+	n, err := o.file.Write(p)
+	if err != nil {
+		return n, fmt.Errorf("写入缓冲文件失败: %v", err)
+	}
+	if o.cmd == nil && n > 0 {
+		cmd := exec.Command("afplay", o.path)
+		if err := cmd.Start(); err != nil {
+			return n, fmt.Errorf("启动 afplay 失败: %v", err)
+		}
+		o.cmd = cmd
+		o.stats.markPlaybackStarted()
+		go func() {
+			if err := cmd.Wait(); err != nil && !strings.Contains(err.Error(), "signal: killed") {
+				logger.Error("播放失败: %v", err)
+				o.mu.Lock()
+				o.err = fmt.Errorf("afplay 异常退出: %v", err)
+				o.mu.Unlock()
+			}
+		}()
+	}
+	return n, nil
+}
+
+func (o *fileOutput) Close() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.cmd != nil && o.cmd.Process != nil {
+		o.cmd.Process.Kill()
+	}
+	o.file.Close()
+	os.Remove(o.path)
+}
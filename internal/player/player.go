@@ -1,62 +1,376 @@
 package player
 
 import (
+	"FMgo/internal/audio"
 	"FMgo/internal/logger"
+	"FMgo/internal/model"
 	"fmt"
+	"sync"
 	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultCrossfade 是切换电台时默认的交叉淡入时长
+	DefaultCrossfade = 3 * time.Second
+	// prebufferTarget 是新电台开始淡入前需要缓冲的时长
+	prebufferTarget = 2 * time.Second
+	// prebufferTimeout 是等待预缓冲的最长时间，超时后直接切换
+	prebufferTimeout = 15 * time.Second
+	// DefaultPauseBuffer 是暂停期间默认保留的直播时长
+	DefaultPauseBuffer = 10 * time.Minute
 )
 
 // Player 是播放器的主控制器，负责管理音频流的播放
 type Player struct {
-	streamPlayer *StreamPlayer
-	isPlaying    atomic.Bool
-	currentURL   string
+	mu          sync.Mutex
+	current     *StreamPlayer // 正在播放的会话
+	currentSrc  *pcmSource
+	pending     *StreamPlayer // 正在预缓冲、等待淡入的会话
+	pendingSrc  *pcmSource
+	last        *StreamPlayer // 最近一次开始的会话，用于统计
+	mixer       *mixer        // 为 nil 时表示未安装 ffmpeg，直接用 afplay 播放
+	eq          *audio.Equalizer
+	limiter     *rateLimiter
+	metered     bool
+	bitrate     int // 偏好码率（kbps），0 表示最高码率
+	crossfade   time.Duration
+	pauseBuffer time.Duration
+	paused      bool
+	pausedAt    time.Time
+	behind      time.Duration // 恢复播放后落后直播的时长
+	isPlaying   atomic.Bool
+	currentURL  string
+
+	hook           func(StateChange)
+	session        Session         // 当前（或最近一次）收听
+	listening      bool            // session 是否尚未结束
+	sessionStreams []*StreamPlayer // 当前收听期间创建的下载会话，用于汇总字节数
+	streamBase     int64           // sessionStreams 中已计入之前收听的字节数
 }
 
 // NewPlayer 创建一个新的播放器实例
 func NewPlayer() (*Player, error) {
 	logger.Info("初始化播放器")
-	streamPlayer, err := NewStreamPlayer()
-	if err != nil {
-		logger.Error("创建流播放器失败: %v", err)
-		return nil, fmt.Errorf("创建流播放器失败: %v", err)
-	}
 
-	return &Player{
-		streamPlayer: streamPlayer,
-		isPlaying:    atomic.Bool{},
-	}, nil
+	p := &Player{
+		eq:          audio.NewEqualizer(),
+		crossfade:   DefaultCrossfade,
+		pauseBuffer: DefaultPauseBuffer,
+	}
+	if audio.DecoderAvailable() {
+		p.mixer = newMixer(audio.DefaultBackend(), p.eq)
+	} else {
+		logger.Info("未找到 ffmpeg，使用 afplay 直接播放，均衡器与交叉淡入不可用")
+	}
+	return p, nil
 }
 
-// Play 开始播放指定的URL
+// Play 开始播放指定的URL。已有电台在播放时，新电台先在后台预缓冲，再交叉淡入
 func (p *Player) Play(url string) error {
 	logger.Info("开始播放: %s", url)
 
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// 暂停状态下切台直接从直播开始，重新播放同一电台视为继续收听
+	resumed := false
+	if p.paused {
+		if p.currentURL == url {
+			p.session.Paused += time.Since(p.pausedAt)
+			resumed = true
+		} else {
+			p.endLocked(model.EndReasonSwitch)
+		}
+		p.stopLocked()
+	}
+
 	// 如果正在播放同一个URL，不做任何操作
 	if p.isPlaying.Load() && p.currentURL == url {
 		logger.Info("已经在播放该URL")
 		return nil
 	}
+	if !resumed {
+		p.endLocked(model.EndReasonSwitch)
+	}
 
-	p.Stop()
-	// 开始新的播放
-	if err := p.streamPlayer.PlayStream(url); err != nil {
+	// 快速切台时取消尚未完成的预缓冲
+	if p.pending != nil {
+		logger.Info("取消预缓冲: %s", p.pending.stats.url)
+		p.pending.Stop()
+		p.pending, p.pendingSrc = nil, nil
+	}
+
+	if p.mixer == nil {
+		p.stopLocked()
+	}
+	session, src, err := p.startSessionLocked(url)
+	if err != nil {
 		logger.Error("开始播放失败: %v", err)
+		p.failLocked(url)
 		return fmt.Errorf("开始播放失败: %v", err)
 	}
 
+	if p.mixer == nil || p.current == nil {
+		if src != nil {
+			if err := p.mixer.play(src); err != nil {
+				session.Stop()
+				logger.Error("开始播放失败: %v", err)
+				p.failLocked(url)
+				return fmt.Errorf("开始播放失败: %v", err)
+			}
+		}
+		p.current, p.currentSrc = session, src
+	} else {
+		p.pending, p.pendingSrc = session, src
+		go p.awaitPrebuffer(session, src)
+	}
+
+	p.behind = 0
 	p.isPlaying.Store(true)
 	p.currentURL = url
+	if resumed {
+		p.emitLocked(StatePlaying)
+	} else {
+		p.beginLocked(url)
+	}
 	return nil
 }
 
+// startSessionLocked 为 url 创建新的下载会话，使用 PCM 管线时同时返回其音频源
+func (p *Player) startSessionLocked(url string) (*StreamPlayer, *pcmSource, error) {
+	session := newStreamPlayer(p.limiter, p.metered, p.bitrate*1000)
+	session.onError = func(err error) { p.streamFailed(session, err) }
+	if p.mixer == nil {
+		if err := session.PlayStream(url, openFileOutput); err != nil {
+			return nil, nil, err
+		}
+		p.last = session
+		p.sessionStreams = append(p.sessionStreams, session)
+		return session, nil, nil
+	}
+
+	// 按流量计费模式下不做时移缓冲
+	var queueMax int64
+	if !p.metered {
+		queueMax = int64(p.pauseBuffer.Seconds() * defaultBitrate / 8)
+	}
+	var src *pcmSource
+	err := session.PlayStream(url, func(stats *statsCollector) (output, error) {
+		var err error
+		src, err = newPCMSource(stats, queueMax)
+		return src, err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	p.last = session
+	p.sessionStreams = append(p.sessionStreams, session)
+	return session, src, nil
+}
+
+// streamFailed 在下载协程中调用：正在播放或预缓冲的会话下载、解码失败时停止播放，收听以出错结束
+func (p *Player) streamFailed(session *StreamPlayer, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if session != p.current && session != p.pending {
+		return
+	}
+	if session == p.pending {
+		// 新电台预缓冲失败时原电台继续播放，新电台的收听以出错结束，原电台重新开始收听
+		logger.Error("切换电台失败，继续播放 %s: %v", p.current.stats.url, err)
+		p.pending.Stop()
+		p.pending, p.pendingSrc = nil, nil
+		p.endLocked(model.EndReasonError)
+		p.last = p.current
+		p.currentURL = p.current.stats.url
+		p.beginLocked(p.currentURL)
+		p.sessionStreams = []*StreamPlayer{p.current}
+		p.streamBase = p.current.Stats().BytesReceived
+		return
+	}
+	logger.Error("播放失败，停止播放: %v", err)
+	p.endLocked(model.EndReasonError)
+	p.stopLocked()
+}
+
+// awaitPrebuffer 等待新电台缓冲足够后交叉淡入，期间若被新的切台取消则直接返回
+func (p *Player) awaitPrebuffer(session *StreamPlayer, src *pcmSource) {
+	deadline := time.Now().Add(prebufferTimeout)
+	for src.buffered() < prebufferTarget && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+		p.mu.Lock()
+		cancelled := p.pending != session
+		p.mu.Unlock()
+		if cancelled {
+			return
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pending != session {
+		return
+	}
+	p.promotePendingLocked(p.crossfade)
+}
+
+// promotePendingLocked 将预缓冲中的会话切换为当前会话
+func (p *Player) promotePendingLocked(fade time.Duration) {
+	old := p.current
+	p.current, p.currentSrc = p.pending, p.pendingSrc
+	p.pending, p.pendingSrc = nil, nil
+	logger.Info("交叉淡入到: %s", p.current.stats.url)
+	if err := p.mixer.crossfade(p.currentSrc, fade, old.Stop); err != nil {
+		logger.Error("切换电台失败: %v", err)
+	}
+}
+
+// Pause 暂停播放。使用 PCM 管线时继续在后台下载到有界的时移缓冲中，
+// 否则（未安装 ffmpeg 或按流量计费模式）停止下载，恢复时回到直播
+func (p *Player) Pause() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.isPlaying.Load() || p.paused {
+		return
+	}
+	logger.Info("暂停播放: %s", p.currentURL)
+	if p.pending != nil {
+		p.promotePendingLocked(0)
+	}
+
+	p.paused = true
+	p.pausedAt = time.Now()
+	p.emitLocked(StatePaused)
+	if p.timeshiftLocked() {
+		p.mixer.setPaused(true)
+		p.current.stats.markPaused()
+		return
+	}
+	if p.mixer != nil {
+		p.mixer.halt()
+	}
+	p.current.Stop()
+}
+
+// Resume 从暂停处继续播放；时移缓冲已溢出或不可用时回到直播
+func (p *Player) Resume() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.paused {
+		return nil
+	}
+	if !p.timeshiftLocked() || p.currentSrc.overflow() {
+		logger.Info("时移缓冲不可用，回到直播")
+		return p.resumeLiveLocked()
+	}
+
+	logger.Info("继续播放: %s", p.currentURL)
+	p.behind += time.Since(p.pausedAt)
+	p.session.Paused += time.Since(p.pausedAt)
+	p.paused = false
+	p.current.stats.markResumed()
+	p.mixer.setPaused(false)
+	p.emitLocked(StatePlaying)
+	return nil
+}
+
+// ResumeLive 丢弃时移缓冲，从直播最新位置继续播放
+func (p *Player) ResumeLive() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.isPlaying.Load() || (!p.paused && p.behind == 0) {
+		return nil
+	}
+	return p.resumeLiveLocked()
+}
+
+func (p *Player) resumeLiveLocked() error {
+	url := p.currentURL
+	logger.Info("回到直播: %s", url)
+
+	if p.paused {
+		p.session.Paused += time.Since(p.pausedAt)
+	}
+	p.stopLocked()
+	session, src, err := p.startSessionLocked(url)
+	if err != nil {
+		p.endLocked(model.EndReasonError)
+		return fmt.Errorf("回到直播失败: %v", err)
+	}
+	if src != nil {
+		if err := p.mixer.play(src); err != nil {
+			session.Stop()
+			p.endLocked(model.EndReasonError)
+			return fmt.Errorf("回到直播失败: %v", err)
+		}
+	}
+	p.current, p.currentSrc = session, src
+	p.isPlaying.Store(true)
+	p.currentURL = url
+	p.emitLocked(StatePlaying)
+	return nil
+}
+
+// timeshiftLocked 返回当前会话是否支持暂停期间继续缓冲
+func (p *Player) timeshiftLocked() bool {
+	return p.mixer != nil && !p.metered && p.currentSrc != nil
+}
+
+// IsPaused 返回当前是否处于暂停状态
+func (p *Player) IsPaused() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.paused
+}
+
+// Behind 返回当前播放落后直播的时长（暂停中则包括本次暂停时长）
+func (p *Player) Behind() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.paused {
+		return p.behind + time.Since(p.pausedAt)
+	}
+	return p.behind
+}
+
+// PauseBufferFull 返回时移缓冲是否已满，已满时恢复播放将回到直播
+func (p *Player) PauseBufferFull() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.currentSrc != nil && p.currentSrc.overflow()
+}
+
 // Stop 停止当前播放
 func (p *Player) Stop() {
-	if p.isPlaying.Load() {
-		p.streamPlayer.Stop()
-		p.isPlaying.Store(false)
-		p.currentURL = ""
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.endLocked(model.EndReasonStop)
+	p.stopLocked()
+}
+
+func (p *Player) stopLocked() {
+	if !p.isPlaying.Load() {
+		return
+	}
+	if p.pending != nil {
+		p.pending.Stop()
+		p.pending, p.pendingSrc = nil, nil
+	}
+	if p.mixer != nil {
+		p.mixer.halt()
+	}
+	if p.current != nil {
+		p.current.Stop()
+		p.current, p.currentSrc = nil, nil
 	}
+	p.paused = false
+	p.behind = 0
+	p.isPlaying.Store(false)
+	p.currentURL = ""
 }
 
 // IsPlaying 返回当前是否正在播放
@@ -66,13 +380,108 @@ func (p *Player) IsPlaying() bool {
 
 // CurrentURL 返回当前正在播放的URL
 func (p *Player) CurrentURL() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	return p.currentURL
 }
 
-// Cleanup 清理播放器资源
-func (p *Player) Cleanup() {
-	p.Stop()
-	if p.streamPlayer != nil {
-		p.streamPlayer.Cleanup()
+// SetBandwidthLimit 设置下载带宽上限（KB/s），0 表示不限制
+func (p *Player) SetBandwidthLimit(kbps int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.limiter = newRateLimiter(int64(kbps) * 1024)
+}
+
+// SetMetered 设置按流量计费模式：优先最低码率并关闭预取
+func (p *Player) SetMetered(metered bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.metered = metered
+}
+
+// SetPreferredBitrate 设置偏好码率（kbps）：选择不超过该值的最高码率档位，0 表示最高码率。
+// 按流量计费模式优先，下次开始播放时生效
+func (p *Player) SetPreferredBitrate(kbps int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.bitrate = kbps
+}
+
+// SetVolume 设置音量百分比（0-100），正在播放时立即生效。音量经混音器调整，未安装 ffmpeg 时不可用
+func (p *Player) SetVolume(percent int) {
+	if percent < 0 {
+		percent = 0
+	}
+	if percent > 100 {
+		percent = 100
+	}
+	if p.mixer != nil {
+		p.mixer.setVolume(float64(percent) / 100)
+	}
+}
+
+// Mixing 返回是否经混音器播放。未安装 ffmpeg 时音量、均衡器与交叉淡入不可用
+func (p *Player) Mixing() bool {
+	return p.mixer != nil
+}
+
+// SetCrossfade 设置切换电台时的交叉淡入时长，0 表示直接切换
+func (p *Player) SetCrossfade(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.crossfade = d
+}
+
+// SetPauseBuffer 设置暂停期间最多保留的直播时长
+func (p *Player) SetPauseBuffer(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pauseBuffer = d
+}
+
+// SetBackend 设置音频输出后端（afplay/ffplay/aplay/play），为空时自动选择，下次开始播放时生效
+func (p *Player) SetBackend(backend string) {
+	if backend == "" {
+		backend = audio.DefaultBackend()
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.mixer != nil {
+		p.mixer.backend = backend
+	}
+}
+
+// SetEqualizer 设置均衡器频段，正在播放时立即生效
+func (p *Player) SetEqualizer(bands []audio.Band) {
+	p.eq.SetBands(bands)
+}
+
+// EqualizerBands 返回当前均衡器频段
+func (p *Player) EqualizerBands() []audio.Band {
+	return p.eq.Bands()
+}
+
+// Metered 返回是否处于按流量计费模式
+func (p *Player) Metered() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.metered
+}
+
+// Stats 返回当前（或最近一次）播放会话的网络与缓冲统计快照
+func (p *Player) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.last == nil {
+		return Stats{}
 	}
+	return p.last.Stats()
+}
+
+// Cleanup 清理播放器资源，正在进行的收听以退出结束
+func (p *Player) Cleanup() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.endLocked(model.EndReasonExit)
+	p.stopLocked()
 }
@@ -0,0 +1,74 @@
+package player
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// rateLimiter 是一个简单的令牌桶，用于限制分片下载的带宽
+type rateLimiter struct {
+	mu       sync.Mutex
+	rate     float64 // 字节/秒
+	burst    float64
+	tokens   float64
+	lastFill time.Time
+}
+
+// newRateLimiter 创建限速器，bytesPerSec <= 0 时返回 nil 表示不限速
+func newRateLimiter(bytesPerSec int64) *rateLimiter {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+	rate := float64(bytesPerSec)
+	return &rateLimiter{
+		rate:     rate,
+		burst:    rate,
+		tokens:   rate,
+		lastFill: time.Now(),
+	}
+}
+
+// wait 阻塞直到可以消费 n 个字节的令牌
+func (l *rateLimiter) wait(n int) {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.lastFill).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.lastFill = now
+	l.tokens -= float64(n)
+	deficit := -l.tokens
+	l.mu.Unlock()
+
+	if deficit > 0 {
+		time.Sleep(time.Duration(deficit / l.rate * float64(time.Second)))
+	}
+}
+
+// limitedReader 在读取时按限速器节流
+type limitedReader struct {
+	r       io.Reader
+	limiter *rateLimiter
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	// 单次读取不超过令牌桶容量，避免一次性透支过多
+	if max := int(r.limiter.burst); len(p) > max && max > 0 {
+		p = p[:max]
+	}
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.limiter.wait(n)
+	}
+	return n, err
+}
+
+// limitReader 在设置了限速器时包装 r
+func limitReader(r io.Reader, limiter *rateLimiter) io.Reader {
+	if limiter == nil {
+		return r
+	}
+	return &limitedReader{r: r, limiter: limiter}
+}
@@ -0,0 +1,96 @@
+package player
+
+import (
+	"time"
+
+	"FMgo/internal/model"
+)
+
+// State 是播放器的播放状态
+type State int
+
+const (
+	StateStopped State = iota
+	StatePlaying
+	StatePaused
+)
+
+// Session 是一次收听：从开始播放某个地址到停止、切台、出错或退出为止。
+// 暂停与回到直播不会结束收听
+type Session struct {
+	URL       string
+	StartedAt time.Time
+	EndedAt   time.Time     // 未结束时为零值
+	Paused    time.Duration // 暂停的总时长
+	Bytes     int64         // 期间下载的字节数
+	Reason    string        // 结束原因，见 model.EndReason*
+}
+
+// Listened 返回实际收听的时长（不含暂停）
+func (s Session) Listened() time.Duration {
+	end := s.EndedAt
+	if end.IsZero() {
+		end = time.Now()
+	}
+	listened := end.Sub(s.StartedAt) - s.Paused
+	if listened < 0 {
+		return 0
+	}
+	return listened
+}
+
+// StateChange 是一次播放状态变化，Session 为变化后（或刚结束）的收听
+type StateChange struct {
+	State   State
+	Session Session
+}
+
+// SetStateHook 设置播放状态变化的回调。回调在播放器内部同步调用，不能再调用 Player 的方法；
+// 播放中途下载或解码失败时回调在下载协程中调用
+func (p *Player) SetStateHook(hook func(StateChange)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.hook = hook
+}
+
+func (p *Player) emitLocked(state State) {
+	if p.hook != nil {
+		p.hook(StateChange{State: state, Session: p.session})
+	}
+}
+
+// beginLocked 开始新的收听
+func (p *Player) beginLocked(url string) {
+	p.session = Session{URL: url, StartedAt: time.Now()}
+	p.listening = true
+	p.emitLocked(StatePlaying)
+}
+
+// endLocked 结束当前收听，汇总期间所有下载会话的字节数
+func (p *Player) endLocked(reason string) {
+	if !p.listening {
+		return
+	}
+	now := time.Now()
+	if p.paused {
+		p.session.Paused += now.Sub(p.pausedAt)
+	}
+	p.session.Bytes = -p.streamBase
+	for _, stream := range p.sessionStreams {
+		p.session.Bytes += stream.Stats().BytesReceived
+	}
+	p.session.EndedAt = now
+	p.session.Reason = reason
+	p.sessionStreams, p.streamBase = nil, 0
+	p.listening = false
+	p.emitLocked(StateStopped)
+}
+
+// failLocked 以出错结束收听，url 尚未开始收听时记录一次立即结束的收听
+func (p *Player) failLocked(url string) {
+	if !p.listening {
+		p.session = Session{URL: url, StartedAt: time.Now()}
+		p.listening = true
+	}
+	p.endLocked(model.EndReasonError)
+}
@@ -0,0 +1,186 @@
+package player
+
+import (
+	"FMgo/internal/audio"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// maxSourceBuffer 是每个电台解码后 PCM 缓冲的上限
+const maxSourceBuffer = 30 * time.Second
+
+// pcmSource 将下载的 AAC 交给解码器，并缓冲解码后的 PCM 供混音器读取。
+// 暂停期间解码器被 PCM 缓冲反压，下载的 AAC 数据暂存在有界队列中（时移缓冲）
+type pcmSource struct {
+	decoder *audio.Decoder
+	stats   *statsCollector
+
+	mu         sync.Mutex
+	cond       *sync.Cond
+	samples    []int16
+	max        int
+	queue      [][]byte
+	queueBytes int64
+	queueMax   int64
+	overflowed bool
+	closed     bool
+	err        error // 解码器异常退出的原因，之后的写入都返回该错误
+	started    bool
+	done       chan struct{}
+	feedDone   chan struct{}
+}
+
+// newPCMSource 创建 PCM 源，queueMax 是暂存 AAC 数据的字节上限
+func newPCMSource(stats *statsCollector, queueMax int64) (*pcmSource, error) {
+	decoder, err := audio.NewDecoder()
+	if err != nil {
+		return nil, err
+	}
+	src := &pcmSource{
+		decoder:  decoder,
+		stats:    stats,
+		max:      samplesFor(maxSourceBuffer),
+		queueMax: queueMax,
+		done:     make(chan struct{}),
+		feedDone: make(chan struct{}),
+	}
+	src.cond = sync.NewCond(&src.mu)
+	go src.fill()
+	go src.feed()
+	return src, nil
+}
+
+// feed 将队列中的 AAC 数据依次写入解码器
+func (s *pcmSource) feed() {
+	defer close(s.feedDone)
+
+	for {
+		s.mu.Lock()
+		for len(s.queue) == 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if s.closed {
+			s.mu.Unlock()
+			return
+		}
+		chunk := s.queue[0]
+		s.queue = s.queue[1:]
+		s.queueBytes -= int64(len(chunk))
+		s.mu.Unlock()
+
+		if _, err := s.decoder.Write(chunk); err != nil {
+			s.setErr(fmt.Errorf("解码失败: %v", err))
+			return
+		}
+	}
+}
+
+// fill 持续读取解码器输出，缓冲已满时等待混音器消费
+func (s *pcmSource) fill() {
+	defer close(s.done)
+
+	buf := make([]byte, 4096*audio.BytesPerFrame)
+	var decoded []int16
+	for {
+		n, err := io.ReadFull(s.decoder, buf)
+		if n > 0 {
+			decoded = audio.BytesToSamples(buf[:n-n%2], decoded)
+			s.mu.Lock()
+			for len(s.samples) >= s.max && !s.closed {
+				s.cond.Wait()
+			}
+			s.samples = append(s.samples, decoded...)
+			s.cond.Broadcast()
+			s.mu.Unlock()
+		}
+		if err != nil {
+			s.setErr(fmt.Errorf("解码器已退出: %v", err))
+			return
+		}
+	}
+}
+
+// setErr 记录解码器异常退出的原因，主动关闭时不记录
+func (s *pcmSource) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed && s.err == nil {
+		s.err = err
+	}
+}
+
+// Write 将压缩音频数据放入队列，超过上限时丢弃最早的数据并标记溢出
+func (s *pcmSource) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return 0, io.ErrClosedPipe
+	}
+	if s.err != nil {
+		return 0, s.err
+	}
+	s.queue = append(s.queue, append([]byte(nil), p...))
+	s.queueBytes += int64(len(p))
+	for s.queueMax > 0 && s.queueBytes > s.queueMax && len(s.queue) > 1 {
+		s.queueBytes -= int64(len(s.queue[0]))
+		s.queue = s.queue[1:]
+		s.overflowed = true
+	}
+	s.cond.Broadcast()
+	return len(p), nil
+}
+
+// overflow 返回时移缓冲是否因超出上限而丢弃过数据
+func (s *pcmSource) overflow() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.overflowed
+}
+
+// read 读取最多 len(dst) 个样本，返回实际读取数量，不足部分由调用方补静音
+func (s *pcmSource) read(dst []int16) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := copy(dst, s.samples)
+	s.samples = s.samples[n:]
+	if n > 0 {
+		s.cond.Broadcast()
+		if !s.started {
+			s.started = true
+			s.stats.markPlaybackStarted()
+		}
+	}
+	return n
+}
+
+// buffered 返回已缓冲但尚未播放的时长
+func (s *pcmSource) buffered() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return durationOf(len(s.samples))
+}
+
+func (s *pcmSource) Close() {
+	s.mu.Lock()
+	s.closed = true
+	s.cond.Broadcast()
+	s.mu.Unlock()
+
+	s.decoder.Close()
+	<-s.feedDone
+	<-s.done
+}
+
+// samplesFor 返回指定时长对应的样本数（所有声道）
+func samplesFor(d time.Duration) int {
+	return int(d.Seconds()*audio.SampleRate) * audio.Channels
+}
+
+// durationOf 返回指定样本数对应的时长
+func durationOf(samples int) time.Duration {
+	return time.Duration(float64(samples/audio.Channels) / audio.SampleRate * float64(time.Second))
+}
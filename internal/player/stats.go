@@ -0,0 +1,231 @@
+package player
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// statsHistorySize 是迷你图保留的采样点数量
+	statsHistorySize = 60
+	// defaultBitrate 用于在播放列表缺少 #EXTINF 时估算分片时长（比特/秒）
+	defaultBitrate = 64000
+)
+
+// Stats 是一次播放会话的网络与缓冲状态快照
+type Stats struct {
+	URL               string
+	StartedAt         time.Time
+	EndedAt           time.Time
+	BytesReceived     int64 // 包含播放列表与分片的全部下载字节
+	Segments          int
+	LastLatency       time.Duration // 最近一个分片的下载延迟（首字节）
+	AvgLatency        time.Duration
+	LastThroughput    float64 // 最近一个分片的下载速率，字节/秒
+	AvgThroughput     float64
+	PlaylistAge       time.Duration // 距离上次成功刷新播放列表的时间
+	BufferFill        time.Duration // 估算的已缓冲但尚未播放的时长
+	Underruns         int
+	Reconnects        int
+	Errors            int
+	LatencyHistory    []float64 // 毫秒
+	ThroughputHistory []float64 // KB/s
+	BufferHistory     []float64 // 秒
+}
+
+// statsCollector 负责在下载与播放协程之间收集统计数据
+type statsCollector struct {
+	mu sync.Mutex
+
+	url             string
+	startedAt       time.Time
+	endedAt         time.Time
+	bytesReceived   int64
+	segmentBytes    int64
+	segments        int
+	lastLatency     time.Duration
+	totalLatency    time.Duration
+	lastThroughput  float64
+	totalDownload   time.Duration
+	playlistAt      time.Time
+	bufferedMedia   time.Duration
+	playbackStarted time.Time
+	pausedAt        time.Time
+	pausedTotal     time.Duration
+	inUnderrun      bool
+	underruns       int
+	reconnects      int
+	errors          int
+	failing         bool
+
+	latencyHistory    []float64
+	throughputHistory []float64
+	bufferHistory     []float64
+}
+
+func newStatsCollector(url string) *statsCollector {
+	return &statsCollector{
+		url:       url,
+		startedAt: time.Now(),
+	}
+}
+
+// recordPlaylist 记录一次播放列表刷新结果，失败后的首次成功计为一次重连
+func (c *statsCollector) recordPlaylist(n int64, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.bytesReceived += n
+
+	if err != nil {
+		c.errors++
+		c.failing = true
+		return
+	}
+	if c.failing {
+		c.reconnects++
+		c.failing = false
+	}
+	c.playlistAt = time.Now()
+	c.sampleBufferLocked()
+}
+
+// recordSegment 记录一个分片的下载结果
+func (c *statsCollector) recordSegment(n int64, latency, elapsed, duration time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.bytesReceived += n
+	c.segmentBytes += n
+	c.segments++
+	c.lastLatency = latency
+	c.totalLatency += latency
+	c.totalDownload += elapsed
+	if elapsed > 0 {
+		c.lastThroughput = float64(n) / elapsed.Seconds()
+	}
+	if duration <= 0 {
+		duration = time.Duration(float64(n*8) / defaultBitrate * float64(time.Second))
+	}
+	c.bufferedMedia += duration
+
+	c.latencyHistory = appendSample(c.latencyHistory, float64(latency.Milliseconds()))
+	c.throughputHistory = appendSample(c.throughputHistory, c.lastThroughput/1024)
+	c.sampleBufferLocked()
+}
+
+// recordError 记录一次分片下载失败
+func (c *statsCollector) recordError() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errors++
+}
+
+// markPlaybackStarted 记录播放器开始消费缓冲的时间点
+func (c *statsCollector) markPlaybackStarted() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.playbackStarted = time.Now()
+}
+
+// markPaused 记录暂停开始，暂停期间不计入播放进度
+func (c *statsCollector) markPaused() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pausedAt.IsZero() {
+		c.pausedAt = time.Now()
+	}
+}
+
+// markResumed 记录暂停结束
+func (c *statsCollector) markResumed() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.pausedAt.IsZero() {
+		c.pausedTotal += time.Since(c.pausedAt)
+		c.pausedAt = time.Time{}
+	}
+}
+
+// markEnded 标记会话结束
+func (c *statsCollector) markEnded() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.endedAt.IsZero() {
+		c.endedAt = time.Now()
+	}
+}
+
+// bufferFillLocked 估算当前缓冲中尚未播放的时长
+func (c *statsCollector) bufferFillLocked() time.Duration {
+	if c.playbackStarted.IsZero() {
+		return c.bufferedMedia
+	}
+	end := time.Now()
+	if !c.endedAt.IsZero() {
+		end = c.endedAt
+	}
+	if !c.pausedAt.IsZero() {
+		end = c.pausedAt
+	}
+	fill := c.bufferedMedia - (end.Sub(c.playbackStarted) - c.pausedTotal)
+	if fill < 0 {
+		return 0
+	}
+	return fill
+}
+
+// sampleBufferLocked 采样缓冲水位并检测欠载
+func (c *statsCollector) sampleBufferLocked() {
+	fill := c.bufferFillLocked()
+	if !c.playbackStarted.IsZero() {
+		if fill <= 0 && !c.inUnderrun {
+			c.underruns++
+			c.inUnderrun = true
+		} else if fill > 0 {
+			c.inUnderrun = false
+		}
+	}
+	c.bufferHistory = appendSample(c.bufferHistory, fill.Seconds())
+}
+
+// snapshot 返回当前统计数据的副本
+func (c *statsCollector) snapshot() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := Stats{
+		URL:               c.url,
+		StartedAt:         c.startedAt,
+		EndedAt:           c.endedAt,
+		BytesReceived:     c.bytesReceived,
+		Segments:          c.segments,
+		LastLatency:       c.lastLatency,
+		LastThroughput:    c.lastThroughput,
+		BufferFill:        c.bufferFillLocked(),
+		Underruns:         c.underruns,
+		Reconnects:        c.reconnects,
+		Errors:            c.errors,
+		LatencyHistory:    append([]float64(nil), c.latencyHistory...),
+		ThroughputHistory: append([]float64(nil), c.throughputHistory...),
+		BufferHistory:     append([]float64(nil), c.bufferHistory...),
+	}
+	if c.segments > 0 {
+		s.AvgLatency = c.totalLatency / time.Duration(c.segments)
+	}
+	if c.totalDownload > 0 {
+		s.AvgThroughput = float64(c.segmentBytes) / c.totalDownload.Seconds()
+	}
+	if !c.playlistAt.IsZero() {
+		s.PlaylistAge = time.Since(c.playlistAt)
+	}
+	return s
+}
+
+func appendSample(history []float64, v float64) []float64 {
+	history = append(history, v)
+	if len(history) > statsHistorySize {
+		history = history[len(history)-statsHistorySize:]
+	}
+	return history
+}
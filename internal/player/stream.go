@@ -1,57 +1,191 @@
 package player
 
 import (
-	"FMgo/internal/config"
 	"FMgo/internal/logger"
 	"bufio"
 	"container/ring"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
-	"os/exec"
-	"path/filepath"
+	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
+// StreamPlayer 负责单个电台的一次下载会话：刷新播放列表并把分片交给播放输出
 type StreamPlayer struct {
-	bufferFile string
-	currentCmd *exec.Cmd
-	stopChan   chan struct{}
-	urlCache   *ring.Ring
-	urlSet     map[string]bool
+	mu       sync.Mutex
+	out      output
+	stopChan chan struct{}
+	urlCache *ring.Ring
+	urlSet   map[string]bool
+	stats    *statsCollector
+	limiter  *rateLimiter
+	metered  bool
+	bitrate  int         // 偏好码率上限（bit/s），0 表示不限制
+	onError  func(error) // 下载或解码无法继续时在下载协程中调用，主动停止的会话不会调用
 }
 
-func NewStreamPlayer() (*StreamPlayer, error) {
+// maxConsecutiveErrors 是连续下载失败多少次后放弃播放
+const maxConsecutiveErrors = 5
+
+// outputError 表示播放输出（解码器或播放进程）已不可用，下载无法继续
+type outputError struct{ err error }
+
+func (e *outputError) Error() string {
+	return fmt.Sprintf("写入播放输出失败: %v", e.err)
+}
+
+// segment 是播放列表中的一个媒体分片
+type segment struct {
+	URL      string
+	Duration time.Duration
+}
+
+// variant 是主播放列表中的一个码率档位
+type variant struct {
+	URL       string
+	Bandwidth int
+}
+
+// countingReader 统计读取的字节数
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func newStreamPlayer(limiter *rateLimiter, metered bool, bitrate int) *StreamPlayer {
 	return &StreamPlayer{
-		stopChan: make(chan struct{}),
 		urlCache: ring.New(10),
 		urlSet:   make(map[string]bool),
-	}, nil
+		stats:    newStatsCollector(""),
+		limiter:  limiter,
+		metered:  metered,
+		bitrate:  bitrate,
+	}
 }
 
-func (s *StreamPlayer) parseM3U8(url string) ([]string, error) {
-	resp, err := http.Get(url)
+func (s *StreamPlayer) parseM3U8(playlistURL string) ([]segment, []variant, int64, error) {
+	resp, err := http.Get(playlistURL)
 	if err != nil {
-		return nil, fmt.Errorf("获取 M3U8 失败: %v", err)
+		return nil, nil, 0, fmt.Errorf("获取 M3U8 失败: %v", err)
 	}
 	defer resp.Body.Close()
 
-	var aacURLs []string
-	scanner := bufio.NewScanner(resp.Body)
+	base, err := url.Parse(playlistURL)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("解析 M3U8 地址失败: %v", err)
+	}
+
+	body := &countingReader{r: limitReader(resp.Body, s.limiter)}
+	var segments []segment
+	var variants []variant
+	var duration time.Duration
+	bandwidth := -1
+	scanner := bufio.NewScanner(body)
 	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.HasSuffix(line, ".aac") {
-			aacURLs = append(aacURLs, line)
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "#EXTINF:"):
+			duration = parseExtinf(line)
+		case strings.HasPrefix(line, "#EXT-X-STREAM-INF:"):
+			bandwidth = parseBandwidth(line)
+		case line == "" || strings.HasPrefix(line, "#"):
+		case bandwidth >= 0:
+			variants = append(variants, variant{URL: resolveURL(base, line), Bandwidth: bandwidth})
+			bandwidth = -1
+		case strings.HasSuffix(line, ".aac"):
+			segments = append(segments, segment{URL: resolveURL(base, line), Duration: duration})
+			duration = 0
 		}
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("扫描 M3U8 失败: %v", err)
+		return nil, nil, body.n, fmt.Errorf("扫描 M3U8 失败: %v", err)
+	}
+
+	return segments, variants, body.n, nil
+}
+
+// selectVariant 从主播放列表中选择码率档位：按流量计费模式下取最低码率；设置了偏好码率时
+// 取不超过偏好的最高码率，所有档位都超过时取最低码率；否则取最高码率
+func (s *StreamPlayer) selectVariant(variants []variant) variant {
+	lowest, chosen := variants[0], variant{Bandwidth: -1}
+	for _, v := range variants {
+		if v.Bandwidth < lowest.Bandwidth {
+			lowest = v
+		}
+		if s.bitrate > 0 && v.Bandwidth > s.bitrate {
+			continue
+		}
+		if v.Bandwidth > chosen.Bandwidth {
+			chosen = v
+		}
+	}
+	if s.metered || chosen.Bandwidth < 0 {
+		return lowest
+	}
+	return chosen
+}
+
+// fetchSegments 获取媒体播放列表，遇到主播放列表时解析所选档位并返回其地址
+func (s *StreamPlayer) fetchSegments(playlistURL string, stats *statsCollector) ([]segment, string, error) {
+	segments, variants, n, err := s.parseM3U8(playlistURL)
+	stats.recordPlaylist(n, err)
+	if err != nil || len(variants) == 0 {
+		return segments, playlistURL, err
 	}
 
-	return aacURLs, nil
+	chosen := s.selectVariant(variants)
+	logger.Info("选择码率档位: %d bps, %s", chosen.Bandwidth, chosen.URL)
+	segments, _, n, err = s.parseM3U8(chosen.URL)
+	stats.recordPlaylist(n, err)
+	return segments, chosen.URL, err
+}
+
+// parseBandwidth 解析 #EXT-X-STREAM-INF 中的 BANDWIDTH 属性
+func parseBandwidth(line string) int {
+	attrs := strings.TrimPrefix(line, "#EXT-X-STREAM-INF:")
+	for _, attr := range strings.Split(attrs, ",") {
+		if strings.HasPrefix(attr, "BANDWIDTH=") {
+			bandwidth, err := strconv.Atoi(strings.TrimPrefix(attr, "BANDWIDTH="))
+			if err == nil {
+				return bandwidth
+			}
+		}
+	}
+	return 0
+}
+
+// resolveURL 将播放列表中的相对地址解析为绝对地址
+func resolveURL(base *url.URL, ref string) string {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return base.ResolveReference(u).String()
+}
+
+// parseExtinf 解析 "#EXTINF:10.0," 形式的分片时长
+func parseExtinf(line string) time.Duration {
+	value := strings.TrimPrefix(line, "#EXTINF:")
+	if i := strings.Index(value, ","); i >= 0 {
+		value = value[:i]
+	}
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
 }
 
 func (s *StreamPlayer) addToURLCache(url string) bool {
@@ -70,70 +204,103 @@ func (s *StreamPlayer) addToURLCache(url string) bool {
 	return true
 }
 
-func (s *StreamPlayer) downloadAndAppendAAC(url string, bufferFile string) error {
-	if !s.addToURLCache(url) {
-		return nil
-	}
-
-	logger.Debug("开始下载新的 URL: %s", url)
-	resp, err := http.Get(url)
+func (s *StreamPlayer) downloadAndAppendAAC(seg segment, out output, stats *statsCollector) error {
+	logger.Debug("开始下载新的 URL: %s", seg.URL)
+	start := time.Now()
+	resp, err := http.Get(seg.URL)
 	if err != nil {
 		return fmt.Errorf("下载 AAC 失败: %v", err)
 	}
 	defer resp.Body.Close()
+	latency := time.Since(start)
 
-	file, err := os.OpenFile(bufferFile, os.O_APPEND|os.O_WRONLY, 0644)
+	n, err := io.Copy(&outputWriter{out}, limitReader(resp.Body, s.limiter))
 	if err != nil {
-		return fmt.Errorf("打开缓冲文件失败: %v", err)
-	}
-	defer file.Close()
-
-	if _, err := io.Copy(file, resp.Body); err != nil {
-		return fmt.Errorf("写入缓冲文件失败: %v", err)
+		var outErr *outputError
+		if errors.As(err, &outErr) {
+			return err
+		}
+		return fmt.Errorf("下载 AAC 失败: %v", err)
 	}
 
+	stats.recordSegment(n, latency, time.Since(start), seg.Duration)
 	return nil
 }
 
-func (s *StreamPlayer) PlayStream(url string) error {
+// PlayStream 开始下载 url，open 用于创建接收分片数据的播放输出
+func (s *StreamPlayer) PlayStream(url string, open func(stats *statsCollector) (output, error)) error {
 	s.Stop()
 
-	// 创建固定的缓冲文件
-	bufferFile := filepath.Join(config.TempDir, "stream-buffer.aac")
-	if err := os.WriteFile(bufferFile, nil, 0644); err != nil {
-		return fmt.Errorf("创建缓冲文件失败: %v", err)
+	stats := newStatsCollector(url)
+	out, err := open(stats)
+	if err != nil {
+		return fmt.Errorf("创建播放输出失败: %v", err)
+	}
+	s.out = out
+	stopChan := make(chan struct{})
+	s.stopChan = stopChan
+	s.stats = stats
+
+	// 非 HLS 地址（如 Icecast/Shoutcast 直播流）直接持续下载
+	if !isHLS(url) {
+		go s.streamDirect(url, out, stats, stopChan)
+		return nil
 	}
-	s.bufferFile = bufferFile
-	s.stopChan = make(chan struct{})
 
 	// 启动下载协程
 	go func() {
+		playlistURL := url
+		first := true
+		failures := 0
 		for {
 			select {
-			case <-s.stopChan:
+			case <-stopChan:
 				return
 			default:
-				aacURLs, err := s.parseM3U8(url)
+				segments, mediaURL, err := s.fetchSegments(playlistURL, stats)
 				if err != nil {
 					logger.Error("解析 M3U8 失败: %v", err)
+					if failures++; failures >= maxConsecutiveErrors {
+						s.fail(stopChan, err)
+						return
+					}
 					time.Sleep(time.Second * 5)
 					continue
 				}
+				playlistURL = mediaURL
 
-				for _, aacURL := range aacURLs {
+				// 按流量计费模式下不预取历史分片，只从直播最新位置开始
+				if first && s.metered && len(segments) > 1 {
+					for _, seg := range segments[:len(segments)-1] {
+						s.addToURLCache(seg.URL)
+					}
+				}
+				first = false
+
+				for _, seg := range segments {
 					select {
-					case <-s.stopChan:
+					case <-stopChan:
 						return
 					default:
-						if err := s.downloadAndAppendAAC(aacURL, bufferFile); err != nil {
+						if !s.addToURLCache(seg.URL) {
+							continue
+						}
+						if err := s.downloadAndAppendAAC(seg, out, stats); err != nil {
 							logger.Error("下载和追加 AAC 失败: %v", err)
+							stats.recordError()
+							var outErr *outputError
+							if failures++; errors.As(err, &outErr) || failures >= maxConsecutiveErrors {
+								s.fail(stopChan, err)
+								return
+							}
 							continue
 						}
+						failures = 0
 					}
 				}
 
 				select {
-				case <-s.stopChan:
+				case <-stopChan:
 					return
 				case <-time.After(time.Second * 5):
 				}
@@ -141,52 +308,125 @@ func (s *StreamPlayer) PlayStream(url string) error {
 		}
 	}()
 
-	// 启动播放协程
-	go func() {
-		// 等待文件可用，最多重试5次
-		for i := 0; i < 5; i++ {
-			info, err := os.Stat(bufferFile)
-			if err == nil && info.Size() > 0 {
-				cmd := exec.Command("afplay", bufferFile)
-				s.currentCmd = cmd
-				if err := cmd.Run(); err != nil && !strings.Contains(err.Error(), "signal: killed") {
-					logger.Error("播放失败: %v", err)
+	return nil
+}
+
+// streamDirect 持续下载非分片的直播流，连接断开后自动重连
+func (s *StreamPlayer) streamDirect(streamURL string, out output, stats *statsCollector, stopChan chan struct{}) {
+	buf := make([]byte, 64*1024)
+	failures := 0
+	for {
+		select {
+		case <-stopChan:
+			return
+		default:
+		}
+
+		start := time.Now()
+		resp, err := http.Get(streamURL)
+		stats.recordPlaylist(0, err)
+		if err != nil {
+			logger.Error("连接直播流失败: %v", err)
+			if failures++; failures >= maxConsecutiveErrors {
+				s.fail(stopChan, err)
+				return
+			}
+			select {
+			case <-stopChan:
+				return
+			case <-time.After(time.Second * 5):
+			}
+			continue
+		}
+		latency := time.Since(start)
+
+		body := limitReader(resp.Body, s.limiter)
+		for {
+			blockStart := time.Now()
+			n, err := io.ReadFull(body, buf)
+			if n > 0 {
+				if _, werr := out.Write(buf[:n]); werr != nil {
+					resp.Body.Close()
+					s.fail(stopChan, &outputError{werr})
+					return
 				}
+				stats.recordSegment(int64(n), latency, time.Since(blockStart), 0)
+				failures = 0
+			}
+			if err != nil {
+				break
+			}
+			select {
+			case <-stopChan:
+				resp.Body.Close()
 				return
+			default:
 			}
-			logger.Info("等待文件可用，重试次数: %d", i+1)
-			time.Sleep(time.Second)
 		}
-		logger.Error("等待文件可用超时，重试次数已用完")
-	}()
+		resp.Body.Close()
+		stats.recordError()
+		if failures++; failures >= maxConsecutiveErrors {
+			s.fail(stopChan, errors.New("直播流连接反复中断"))
+			return
+		}
+	}
+}
 
-	return nil
+// fail 在下载协程无法继续时通知 onError，会话已被主动停止时忽略
+func (s *StreamPlayer) fail(stopChan chan struct{}, err error) {
+	select {
+	case <-stopChan:
+		return
+	default:
+	}
+	logger.Error("播放中断: %v", err)
+	if s.onError != nil {
+		s.onError(err)
+	}
+}
+
+// outputWriter 将播放输出的写入错误包装为 outputError，以便与下载错误区分
+type outputWriter struct {
+	out output
+}
+
+func (w *outputWriter) Write(p []byte) (int, error) {
+	n, err := w.out.Write(p)
+	if err != nil {
+		return n, &outputError{err}
+	}
+	return n, nil
+}
+
+// isHLS 判断地址是否为 HLS 播放列表
+func isHLS(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return strings.Contains(rawURL, ".m3u8")
+	}
+	return strings.HasSuffix(strings.ToLower(u.Path), ".m3u8")
+}
+
+// Stats 返回当前（或最近一次）播放会话的统计快照
+func (s *StreamPlayer) Stats() Stats {
+	return s.stats.snapshot()
 }
 
 func (s *StreamPlayer) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stats.markEnded()
 	if s.stopChan != nil {
 		close(s.stopChan)
-		s.stopChan = make(chan struct{})
+		s.stopChan = nil
 	}
 
-	if s.currentCmd != nil && s.currentCmd.Process != nil {
-		s.currentCmd.Process.Kill()
-		s.currentCmd = nil
+	if s.out != nil {
+		s.out.Close()
+		s.out = nil
 	}
 
 	s.urlCache = ring.New(10)
 	s.urlSet = make(map[string]bool)
-
-	// 清空缓冲文件
-	if s.bufferFile != "" {
-		os.Truncate(s.bufferFile, 0)
-	}
-}
-
-func (s *StreamPlayer) Cleanup() {
-	s.Stop()
-	if s.bufferFile != "" {
-		os.Remove(s.bufferFile)
-		s.bufferFile = ""
-	}
 }
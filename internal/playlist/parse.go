@@ -0,0 +1,337 @@
+// Package playlist 负责电台列表与常见播放列表格式（M3U、PLS、OPML、JSON）之间的转换
+package playlist
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"FMgo/internal/model"
+)
+
+// 支持的格式
+const (
+	FormatM3U  = "m3u"
+	FormatPLS  = "pls"
+	FormatOPML = "opml"
+	FormatJSON = "json"
+)
+
+// Formats 是全部支持的格式
+var Formats = []string{FormatM3U, FormatPLS, FormatOPML, FormatJSON}
+
+// DetectFormat 根据文件扩展名判断格式，扩展名未知时根据内容判断
+func DetectFormat(name string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".m3u", ".m3u8":
+		return FormatM3U
+	case ".pls":
+		return FormatPLS
+	case ".opml", ".xml":
+		return FormatOPML
+	case ".json":
+		return FormatJSON
+	}
+
+	head := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(bytes.ToLower(head), []byte("[playlist]")):
+		return FormatPLS
+	case bytes.HasPrefix(head, []byte("[")), bytes.HasPrefix(head, []byte("{")):
+		return FormatJSON
+	case bytes.HasPrefix(head, []byte("<")):
+		return FormatOPML
+	}
+	return FormatM3U
+}
+
+// Parse 按 format 解析电台列表，format 为空时自动判断。
+// 没有分类信息的电台放在名称为空的分类中，由调用方决定归入哪个分类
+func Parse(name string, data []byte, format string) ([]model.Category, error) {
+	if format == "" {
+		format = DetectFormat(name, data)
+	}
+	switch format {
+	case FormatM3U:
+		return ParseM3U(data)
+	case FormatPLS:
+		return ParsePLS(data)
+	case FormatOPML:
+		return ParseOPML(data)
+	case FormatJSON:
+		return ParseJSON(data)
+	}
+	return nil, fmt.Errorf("不支持的格式: %s", format)
+}
+
+// grouper 按出现顺序把电台归入分类
+type grouper struct {
+	categories []model.Category
+	index      map[string]int
+}
+
+func (g *grouper) add(category string, radio model.Radio) {
+	radio.Name = strings.TrimSpace(radio.Name)
+	radio.PlayURL = strings.TrimSpace(radio.PlayURL)
+	if radio.PlayURL == "" {
+		return
+	}
+	if radio.Name == "" {
+		radio.Name = radio.PlayURL
+	}
+	if g.index == nil {
+		g.index = make(map[string]int)
+	}
+	category = strings.TrimSpace(category)
+	i, ok := g.index[category]
+	if !ok {
+		i = len(g.categories)
+		g.index[category] = i
+		g.categories = append(g.categories, model.Category{Name: category})
+	}
+	g.categories[i].RadioList = append(g.categories[i].RadioList, radio)
+}
+
+// ParseM3U 解析（扩展）M3U，#EXTINF 中的标题作为电台名称，group-title 属性或 #EXTGRP 作为分类
+func ParseM3U(data []byte) ([]model.Category, error) {
+	var g grouper
+	var name, group, logo, extgrp string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "#EXTINF:"):
+			name, group, logo = parseExtinf(line)
+		case strings.HasPrefix(line, "#EXTGRP:"):
+			extgrp = strings.TrimSpace(strings.TrimPrefix(line, "#EXTGRP:"))
+		case line == "" || strings.HasPrefix(line, "#"):
+		default:
+			if group == "" {
+				group = extgrp
+			}
+			g.add(group, model.Radio{Name: name, PlayURL: line, Logo: logo})
+			name, group, logo = "", "", ""
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取 M3U 失败: %v", err)
+	}
+	return g.categories, nil
+}
+
+// parseExtinf 解析 `#EXTINF:-1 tvg-logo="..." group-title="新闻",中国之声`，返回标题、分组与台标
+func parseExtinf(line string) (title, group, logo string) {
+	info := strings.TrimPrefix(line, "#EXTINF:")
+
+	// 标题在属性之后的第一个不在引号内的逗号之后
+	inQuote := false
+	comma := -1
+	for i, r := range info {
+		if r == '"' {
+			inQuote = !inQuote
+		}
+		if r == ',' && !inQuote {
+			comma = i
+			break
+		}
+	}
+	attrs := info
+	if comma >= 0 {
+		attrs = info[:comma]
+		title = info[comma+1:]
+	}
+	return strings.TrimSpace(title), extinfAttr(attrs, "group-title"), extinfAttr(attrs, "tvg-logo")
+}
+
+// extinfAttr 返回 #EXTINF 中 name="value" 形式的属性值
+func extinfAttr(attrs, name string) string {
+	i := strings.Index(attrs, name+`="`)
+	if i < 0 {
+		return ""
+	}
+	rest := attrs[i+len(name)+2:]
+	if j := strings.Index(rest, `"`); j >= 0 {
+		return rest[:j]
+	}
+	return ""
+}
+
+// ParsePLS 解析 PLS 播放列表，FileN 为地址，TitleN 为名称
+func ParsePLS(data []byte) ([]model.Category, error) {
+	files := make(map[int]string)
+	titles := make(map[int]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok {
+			continue
+		}
+		lower := strings.ToLower(key)
+		switch {
+		case strings.HasPrefix(lower, "file"):
+			if n, err := strconv.Atoi(lower[len("file"):]); err == nil {
+				files[n] = value
+			}
+		case strings.HasPrefix(lower, "title"):
+			if n, err := strconv.Atoi(lower[len("title"):]); err == nil {
+				titles[n] = value
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取 PLS 失败: %v", err)
+	}
+
+	entries := make([]int, 0, len(files))
+	for n := range files {
+		entries = append(entries, n)
+	}
+	sort.Ints(entries)
+
+	var g grouper
+	for _, n := range entries {
+		g.add("", model.Radio{Name: titles[n], PlayURL: files[n]})
+	}
+	return g.categories, nil
+}
+
+// opmlOutline 是 OPML 中的一个节点，带地址的节点为电台，不带地址的节点为分类
+type opmlOutline struct {
+	Text     string        `xml:"text,attr,omitempty"`
+	Title    string        `xml:"title,attr,omitempty"`
+	Type     string        `xml:"type,attr,omitempty"`
+	URL      string        `xml:"URL,attr,omitempty"`
+	LowerURL string        `xml:"url,attr,omitempty"`
+	XMLURL   string        `xml:"xmlUrl,attr,omitempty"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+type opmlDocument struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr,omitempty"`
+	Title   string   `xml:"head>title"`
+	Body    struct {
+		Outlines []opmlOutline `xml:"outline"`
+	} `xml:"body"`
+}
+
+func (o opmlOutline) name() string {
+	if o.Text != "" {
+		return o.Text
+	}
+	return o.Title
+}
+
+func (o opmlOutline) url() string {
+	for _, u := range []string{o.URL, o.LowerURL, o.XMLURL} {
+		if u != "" {
+			return u
+		}
+	}
+	return ""
+}
+
+// ParseOPML 解析 OPML 电台列表（如 TuneIn、RadioDroid 导出），嵌套的 outline 作为分类
+func ParseOPML(data []byte) ([]model.Category, error) {
+	var doc opmlDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("解析 OPML 失败: %v", err)
+	}
+	var g grouper
+	var walk func(category string, outlines []opmlOutline)
+	walk = func(category string, outlines []opmlOutline) {
+		for _, o := range outlines {
+			if u := o.url(); u != "" {
+				g.add(category, model.Radio{Name: o.name(), PlayURL: u})
+			}
+			if len(o.Outlines) > 0 {
+				walk(o.name(), o.Outlines)
+			}
+		}
+	}
+	walk("", doc.Body.Outlines)
+	return g.categories, nil
+}
+
+// jsonStation 兼容常见播放器导出的电台字段名
+type jsonStation struct {
+	model.Radio
+	Title       string          `json:"title"`
+	URL         string          `json:"url"`
+	URLResolved string          `json:"url_resolved"`
+	StreamURL   string          `json:"stream_url"`
+	Stream      string          `json:"stream"`
+	Category    string          `json:"category"`
+	Group       string          `json:"group"`
+	UUID        string          `json:"stationuuid"`
+	Favicon     string          `json:"favicon"`
+	RawTags     json.RawMessage `json:"tags"` // 字符串数组，或 Radio Browser 的逗号分隔字符串
+	RadioList   []jsonStation   `json:"radioList"`
+}
+
+func (s jsonStation) radio() model.Radio {
+	radio := s.Radio
+	if radio.Name == "" {
+		radio.Name = s.Title
+	}
+	for _, u := range []string{s.Radio.PlayURL, s.URLResolved, s.StreamURL, s.Stream, s.URL} {
+		if u != "" {
+			radio.PlayURL = u
+			break
+		}
+	}
+	if radio.ID == "" {
+		radio.ID = s.UUID
+	}
+	if radio.Logo == "" {
+		radio.Logo = s.Favicon
+	}
+
+	var tags string
+	if err := json.Unmarshal(s.RawTags, &radio.Tags); err != nil && json.Unmarshal(s.RawTags, &tags) == nil {
+		for _, tag := range strings.Split(tags, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				radio.Tags = append(radio.Tags, tag)
+			}
+		}
+	}
+	return radio
+}
+
+// ParseJSON 解析 JSON 电台列表：FMgo 自身的分类格式（同 radio.json），
+// 或其他播放器导出的电台数组（如 Radio Browser 的 name/url_resolved）
+func ParseJSON(data []byte) ([]model.Category, error) {
+	var items []jsonStation
+	if err := json.Unmarshal(data, &items); err != nil {
+		// 兼容 {"stations": [...]} 形式的导出
+		var wrapped struct {
+			Stations []jsonStation `json:"stations"`
+		}
+		if werr := json.Unmarshal(data, &wrapped); werr != nil || wrapped.Stations == nil {
+			return nil, fmt.Errorf("解析 JSON 失败: %v", err)
+		}
+		items = wrapped.Stations
+	}
+
+	var g grouper
+	for _, item := range items {
+		if item.RadioList != nil {
+			for _, s := range item.RadioList {
+				g.add(item.Name, s.radio())
+			}
+			continue
+		}
+		category := item.Category
+		if category == "" {
+			category = item.Group
+		}
+		g.add(category, item.radio())
+	}
+	return g.categories, nil
+}
@@ -0,0 +1,118 @@
+package playlist
+
+import (
+	"reflect"
+	"testing"
+
+	"FMgo/internal/model"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"stations.M3U8", "", FormatM3U},
+		{"stations.pls", "", FormatPLS},
+		{"export.xml", "", FormatOPML},
+		{"radio.json", "", FormatJSON},
+		{"stations", `  [{"name": "a"}]`, FormatJSON},
+		{"stations", `<opml version="2.0">`, FormatOPML},
+		{"stations", "[Playlist]\nFile1=http://a", FormatPLS},
+		{"stations", "#EXTM3U", FormatM3U},
+	}
+	for _, tt := range tests {
+		if got := DetectFormat(tt.name, []byte(tt.data)); got != tt.want {
+			t.Errorf("DetectFormat(%q, %q) = %s, want %s", tt.name, tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestParseExtinf(t *testing.T) {
+	tests := []struct {
+		line, title, group, logo string
+	}{
+		{`#EXTINF:-1,中国之声`, "中国之声", "", ""},
+		{`#EXTINF:-1 tvg-logo="http://x/logo.png" group-title="新闻",中国之声`, "中国之声", "新闻", "http://x/logo.png"},
+		{`#EXTINF:-1 group-title="新闻, 综合",中国之声, 北京`, "中国之声, 北京", "新闻, 综合", ""},
+		{`#EXTINF:-1 group-title="音乐"`, "", "音乐", ""},
+	}
+	for _, tt := range tests {
+		title, group, logo := parseExtinf(tt.line)
+		if title != tt.title || group != tt.group || logo != tt.logo {
+			t.Errorf("parseExtinf(%q) = %q, %q, %q, want %q, %q, %q", tt.line, title, group, logo, tt.title, tt.group, tt.logo)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	radio := func(name, url string) model.Radio { return model.Radio{Name: name, PlayURL: url} }
+	tests := []struct {
+		name string
+		data string
+		want []model.Category
+	}{
+		{"groups.m3u", `#EXTM3U
+#EXTINF:-1 group-title="新闻",中国之声
+http://example.com/cnr1.m3u8
+#EXTGRP:音乐
+#EXTINF:-1,音乐之声
+http://example.com/music.m3u8
+
+#EXTINF:-1 group-title="新闻",经济之声
+http://example.com/cnr2.m3u8
+http://example.com/bare.mp3
+`, []model.Category{
+			{Name: "新闻", RadioList: []model.Radio{radio("中国之声", "http://example.com/cnr1.m3u8"), radio("经济之声", "http://example.com/cnr2.m3u8")}},
+			{Name: "音乐", RadioList: []model.Radio{radio("音乐之声", "http://example.com/music.m3u8"), radio("http://example.com/bare.mp3", "http://example.com/bare.mp3")}},
+		}},
+		{"list.pls", `[playlist]
+File2=http://example.com/b
+Title2=B
+File1=http://example.com/a
+Title1=A
+NumberOfEntries=2
+`, []model.Category{
+			{RadioList: []model.Radio{radio("A", "http://example.com/a"), radio("B", "http://example.com/b")}},
+		}},
+		{"export.opml", `<?xml version="1.0"?>
+<opml version="2.0"><head><title>Radio</title></head><body>
+  <outline text="Jazz">
+    <outline text="Jazz FM" type="audio" URL="http://example.com/jazz"/>
+  </outline>
+  <outline text="Loose" type="link" url="http://example.com/loose"/>
+</body></opml>`, []model.Category{
+			{Name: "Jazz", RadioList: []model.Radio{radio("Jazz FM", "http://example.com/jazz")}},
+			{RadioList: []model.Radio{radio("Loose", "http://example.com/loose")}},
+		}},
+		{"radio.json", `[{"name": "新闻", "radioList": [{"name": "中国之声", "playUrl": "http://example.com/cnr1.m3u8"}]}]`,
+			[]model.Category{{Name: "新闻", RadioList: []model.Radio{radio("中国之声", "http://example.com/cnr1.m3u8")}}}},
+		{"browser.json", `{"stations": [{"name": " Jazz ", "url": "http://example.com/jazz.pls", "url_resolved": "http://example.com/jazz", "group": "jazz"}, {"name": "no url"}]}`,
+			[]model.Category{{Name: "jazz", RadioList: []model.Radio{radio("Jazz", "http://example.com/jazz")}}}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.name, []byte(tt.data), "")
+		if err != nil {
+			t.Errorf("Parse(%s): %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%s) = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tt := range []struct{ name, data string }{
+		{"broken.json", `{"name": `},
+		{"broken.opml", `<opml><body><outline></body>`},
+	} {
+		if _, err := Parse(tt.name, []byte(tt.data), ""); err == nil {
+			t.Errorf("Parse(%s) accepted invalid data", tt.name)
+		}
+	}
+	if _, err := Parse("a.txt", nil, "xspf"); err == nil {
+		t.Error("unsupported format accepted")
+	}
+}
@@ -0,0 +1,138 @@
+package playlist
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"FMgo/internal/model"
+)
+
+// 历史记录支持的格式
+const (
+	FormatCSV = "csv"
+)
+
+// HistoryFormats 是历史记录支持导出的格式
+var HistoryFormats = []string{FormatCSV, FormatJSON}
+
+// Write 按 format 将电台列表写入 w
+func Write(w io.Writer, categories []model.Category, format string) error {
+	switch format {
+	case FormatM3U:
+		return WriteM3U(w, categories)
+	case FormatPLS:
+		return WritePLS(w, categories)
+	case FormatOPML:
+		return WriteOPML(w, categories)
+	case FormatJSON:
+		return WriteJSON(w, categories)
+	}
+	return fmt.Errorf("不支持的格式: %s", format)
+}
+
+// WriteM3U 写入扩展 M3U，分类写入 group-title 属性
+func WriteM3U(w io.Writer, categories []model.Category) error {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	for _, cat := range categories {
+		for _, radio := range cat.RadioList {
+			b.WriteString("#EXTINF:-1")
+			if radio.Logo != "" {
+				fmt.Fprintf(&b, " tvg-logo=\"%s\"", radio.Logo)
+			}
+			if cat.Name != "" {
+				fmt.Fprintf(&b, " group-title=\"%s\"", strings.ReplaceAll(cat.Name, `"`, "'"))
+			}
+			fmt.Fprintf(&b, ",%s\n", radio.Name)
+			fmt.Fprintf(&b, "%s\n", radio.PlayURL)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WritePLS 写入 PLS 播放列表，PLS 不支持分类
+func WritePLS(w io.Writer, categories []model.Category) error {
+	var b strings.Builder
+	b.WriteString("[playlist]\n")
+	n := 0
+	for _, cat := range categories {
+		for _, radio := range cat.RadioList {
+			n++
+			fmt.Fprintf(&b, "File%d=%s\nTitle%d=%s\nLength%d=-1\n", n, radio.PlayURL, n, radio.Name, n)
+		}
+	}
+	fmt.Fprintf(&b, "NumberOfEntries=%d\nVersion=2\n", n)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteOPML 写入 OPML，每个分类为一个 outline，电台为其下 type="audio" 的 outline
+func WriteOPML(w io.Writer, categories []model.Category) error {
+	doc := opmlDocument{Version: "2.0", Title: "FMgo"}
+	for _, cat := range categories {
+		outline := opmlOutline{Text: cat.Name}
+		for _, radio := range cat.RadioList {
+			outline.Outlines = append(outline.Outlines, opmlOutline{Text: radio.Name, Type: "audio", URL: radio.PlayURL})
+		}
+		doc.Body.Outlines = append(doc.Body.Outlines, outline)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("写入 OPML 失败: %v", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteJSON 写入 FMgo 的分类格式（同 radio.json）
+func WriteJSON(w io.Writer, categories []model.Category) error {
+	if categories == nil {
+		categories = []model.Category{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(categories)
+}
+
+// WriteHistory 按 format（csv/json）写入播放历史
+func WriteHistory(w io.Writer, history []model.PlayHistory, format string) error {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"played_at", "ended_at", "radio_name", "play_url", "listened_seconds", "end_reason", "bytes_received"})
+		for _, h := range history {
+			endedAt := ""
+			if h.EndedAt != nil {
+				endedAt = h.EndedAt.Format(time.RFC3339)
+			}
+			cw.Write([]string{
+				h.PlayedAt.Format(time.RFC3339), endedAt, h.RadioName, h.PlayURL,
+				strconv.FormatInt(int64(h.Listened().Seconds()), 10), h.EndReason, strconv.FormatInt(h.BytesReceived, 10),
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	case FormatJSON:
+		if history == nil {
+			history = []model.PlayHistory{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(history)
+	}
+	return fmt.Errorf("不支持的历史记录格式: %s", format)
+}
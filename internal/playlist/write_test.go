@@ -0,0 +1,90 @@
+package playlist
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"FMgo/internal/model"
+)
+
+var roundTripCategories = []model.Category{
+	{Name: "新闻, 综合", RadioList: []model.Radio{
+		{Name: "中国之声", PlayURL: "http://example.com/cnr1.m3u8"},
+		{Name: "经济之声, 北京", PlayURL: "http://example.com/cnr2.m3u8?a=1&b=2"},
+	}},
+	{Name: "音乐", RadioList: []model.Radio{
+		{Name: "Jazz <FM>", PlayURL: "http://example.com/jazz"},
+	}},
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	flat := []model.Category{{RadioList: append(append([]model.Radio{}, roundTripCategories[0].RadioList...), roundTripCategories[1].RadioList...)}}
+	tests := []struct {
+		format string
+		want   []model.Category
+	}{
+		{FormatM3U, roundTripCategories},
+		{FormatPLS, flat},
+		{FormatOPML, roundTripCategories},
+		{FormatJSON, roundTripCategories},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := Write(&buf, roundTripCategories, tt.format); err != nil {
+			t.Errorf("Write(%s): %v", tt.format, err)
+			continue
+		}
+		if got := DetectFormat("export", buf.Bytes()); got != tt.format {
+			t.Errorf("DetectFormat of written %s = %s", tt.format, got)
+		}
+		got, err := Parse("export", buf.Bytes(), tt.format)
+		if err != nil {
+			t.Errorf("Parse(%s): %v\n%s", tt.format, err, buf.String())
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s round trip = %+v, want %+v\n%s", tt.format, got, tt.want, buf.String())
+		}
+	}
+}
+
+func TestWriteM3UGroupTitle(t *testing.T) {
+	var buf bytes.Buffer
+	categories := []model.Category{
+		{Name: `"精选"`, RadioList: []model.Radio{{Name: "中国之声", PlayURL: "http://example.com/cnr1"}}},
+		{RadioList: []model.Radio{{Name: "无分类", PlayURL: "http://example.com/none"}}},
+	}
+	if err := WriteM3U(&buf, categories); err != nil {
+		t.Fatal(err)
+	}
+	want := "#EXTM3U\n" +
+		"#EXTINF:-1 group-title=\"'精选'\",中国之声\nhttp://example.com/cnr1\n" +
+		"#EXTINF:-1,无分类\nhttp://example.com/none\n"
+	if buf.String() != want {
+		t.Errorf("WriteM3U = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteHistory(t *testing.T) {
+	playedAt := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	endedAt := playedAt.Add(90 * time.Second)
+	history := []model.PlayHistory{{
+		RadioName: "中国之声", PlayURL: "http://example.com/cnr1", PlayedAt: playedAt,
+		EndedAt: &endedAt, ListenedMs: 60000, EndReason: model.EndReasonSwitch, BytesReceived: 1024,
+	}}
+	var buf bytes.Buffer
+	if err := WriteHistory(&buf, history, FormatCSV); err != nil {
+		t.Fatal(err)
+	}
+	want := "played_at,ended_at,radio_name,play_url,listened_seconds,end_reason,bytes_received\n" +
+		"2024-05-01T08:00:00Z,2024-05-01T08:01:30Z,中国之声,http://example.com/cnr1,60," + model.EndReasonSwitch + ",1024\n"
+	if buf.String() != want {
+		t.Errorf("WriteHistory csv = %q, want %q", buf.String(), want)
+	}
+	if err := WriteHistory(&buf, history, FormatM3U); err == nil || !strings.Contains(err.Error(), FormatM3U) {
+		t.Errorf("WriteHistory(m3u) error = %v", err)
+	}
+}
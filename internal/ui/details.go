@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"FMgo/internal/model"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/gizak/termui/v3/widgets"
+)
+
+// setupDetailsPanel 创建电台详情面板
+func (u *UI) setupDetailsPanel() {
+	u.detailsText = widgets.NewParagraph()
+	u.detailsText.Title = "电台详情"
+	u.detailsText.BorderStyle = ui.NewStyle(colorBorder)
+	u.detailsText.TitleStyle = ui.NewStyle(colorTitle, ui.ColorClear, ui.ModifierBold)
+	u.detailsText.TextStyle = ui.NewStyle(colorText)
+	u.detailsText.PaddingLeft = 1
+}
+
+// toggleDetailsPanel 显示/隐藏电台详情面板
+func (u *UI) toggleDetailsPanel() {
+	u.showDetails = !u.showDetails
+	u.layout()
+	if u.showDetails {
+		u.refreshDetailsPanel()
+	}
+	ui.Render(u.grid)
+}
+
+// refreshDetailsPanel 显示当前选中电台的元数据
+func (u *UI) refreshDetailsPanel() {
+	if !u.showDetails {
+		return
+	}
+	radio, ok := u.selectedRadio()
+	if !ok {
+		u.detailsText.Text = "未选中电台"
+		return
+	}
+
+	var b strings.Builder
+	field := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%s: %s\n", label, value)
+		}
+	}
+	field("名称", radio.Name)
+	field("来源", radio.Source)
+	field("简介", radio.Description)
+	field("地区", joinNonEmpty(" ", radio.Country, radio.Province, radio.City))
+	field("语言", radio.Language)
+	field("标签", strings.Join(radio.Tags, ", "))
+	format := radio.Codec
+	if radio.Bitrate > 0 {
+		format = joinNonEmpty(" ", format, fmt.Sprintf("%d kbps", radio.Bitrate))
+	}
+	field("格式", format)
+	field("主页", radio.Homepage)
+	field("台标", radio.Logo)
+	field("ID", radio.ID)
+	field("地址", radio.PlayURL)
+	u.detailsText.Text = b.String()
+}
+
+// selectedRadio 返回当前视图中选中的电台
+func (u *UI) selectedRadio() (model.Radio, bool) {
+	if len(u.radioList.Rows) == 0 {
+		return model.Radio{}, false
+	}
+	switch u.currentView {
+	case "discover":
+		return u.selectedDiscoverRadio()
+	case "equalizer":
+		return model.Radio{}, false
+	case "editor":
+		item, ok := u.selectedEditorItem()
+		if !ok || item.radio == nil {
+			return model.Radio{}, false
+		}
+		return *item.radio, true
+	}
+	return u.rowRadio(u.radioList.SelectedRow)
+}
+
+func joinNonEmpty(sep string, values ...string) string {
+	var parts []string
+	for _, v := range values {
+		if v != "" {
+			parts = append(parts, v)
+		}
+	}
+	return strings.Join(parts, sep)
+}
@@ -0,0 +1,166 @@
+package ui
+
+import (
+	"fmt"
+
+	"FMgo/internal/catalog"
+	"FMgo/internal/logger"
+	"FMgo/internal/model"
+
+	ui "github.com/gizak/termui/v3"
+)
+
+// radioBrowser 返回目录中的 Radio Browser 来源
+func (u *UI) radioBrowser() *catalog.RadioBrowserProvider {
+	p, _ := u.catalog.Provider(catalog.RadioBrowserName).(*catalog.RadioBrowserProvider)
+	return p
+}
+
+// enterDiscover 打开发现视图，默认显示投票最多的电台
+func (u *UI) enterDiscover() {
+	if u.radioBrowser() == nil {
+		u.setStatus("未启用 Radio Browser 目录", colorStatusError)
+		return
+	}
+	u.currentView = "discover"
+	u.setStatus("Enter 播放 | '/' 搜索(如 jazz country:Germany codec:mp3 bitrate:128) | 't' 热门切换 | 'a' 收藏 | 'c' 加入本地分类 | Tab 返回", colorText)
+	u.loadDiscover("投票最多", func(rb *catalog.RadioBrowserProvider) ([]model.DirectoryStation, error) {
+		return rb.TopVoted(0)
+	})
+}
+
+// loadDiscover 在后台请求 Radio Browser，完成后刷新发现视图
+func (u *UI) loadDiscover(title string, fetch func(rb *catalog.RadioBrowserProvider) ([]model.DirectoryStation, error)) {
+	rb := u.radioBrowser()
+	u.discoverTitle = title
+	u.radioList.Title = "发现"
+	u.setRows([]string{fmt.Sprintf("[%s](fg:yellow)", title), "  正在加载..."}, nil)
+	u.radioList.SelectedRow = 0
+	ui.Render(u.grid)
+
+	go func() {
+		stations, err := fetch(rb)
+		u.post(func() {
+			if u.currentView != "discover" {
+				return
+			}
+			if err != nil {
+				logger.Error("加载 Radio Browser 失败: %v", err)
+				u.discoverStations = nil
+				u.setStatus(fmt.Sprintf("加载 Radio Browser 失败: %v", err), colorStatusError)
+			} else {
+				u.discoverStations = stations
+			}
+			u.showDiscover()
+		})
+	}()
+}
+
+// showDiscover 显示发现视图的结果
+func (u *UI) showDiscover() {
+	u.discoverResults = u.discoverResults[:0]
+	items := []string{fmt.Sprintf("[%s](fg:yellow)", u.discoverTitle)}
+	radios := make(map[int]model.Radio)
+	for _, s := range u.discoverStations {
+		radio := s.Radio()
+		radio.Source = catalog.RadioBrowserName
+		u.discoverResults = append(u.discoverResults, radio)
+		radios[len(items)] = radio
+		items = append(items, radioRow(radio))
+	}
+	if len(items) == 1 {
+		items = append(items, "  未找到电台")
+	}
+
+	u.radioList.Title = "发现"
+	u.setRows(items, radios)
+	u.radioList.SelectedRow = 1
+	u.showDiscoverDetails()
+	ui.Render(u.grid)
+}
+
+// showDiscoverDetails 在状态栏显示选中电台的详细信息
+func (u *UI) showDiscoverDetails() {
+	i := u.radioList.SelectedRow - 1
+	if i < 0 || i >= len(u.discoverStations) {
+		return
+	}
+	s := u.discoverStations[i]
+	u.setStatus(fmt.Sprintf("%s | %s | %s | %s %dkbps | ♥%d | 点击 %d | %s",
+		s.Name, s.Country, s.Language, s.Codec, s.Bitrate, s.Votes, s.ClickCount, s.Tags), colorText)
+}
+
+// handleDiscoverKeys 处理发现视图特有的按键，返回是否已处理
+func (u *UI) handleDiscoverKeys(e ui.Event) bool {
+	switch e.ID {
+	case "/":
+		u.startPrompt("搜索 Radio Browser", "", func(text string) {
+			q := catalog.ParseDirectoryQuery(text)
+			u.loadDiscover(fmt.Sprintf("搜索: %s", text), func(rb *catalog.RadioBrowserProvider) ([]model.DirectoryStation, error) {
+				return rb.SearchStations(q)
+			})
+		})
+		return true
+	case "t":
+		if u.discoverTitle == "投票最多" {
+			u.loadDiscover("点击最多", func(rb *catalog.RadioBrowserProvider) ([]model.DirectoryStation, error) {
+				return rb.TopClicked(0)
+			})
+		} else {
+			u.loadDiscover("投票最多", func(rb *catalog.RadioBrowserProvider) ([]model.DirectoryStation, error) {
+				return rb.TopVoted(0)
+			})
+		}
+		return true
+	case "c":
+		radio, ok := u.selectedDiscoverRadio()
+		if !ok {
+			return true
+		}
+		u.startPrompt(fmt.Sprintf("将 %s 加入本地分类", radio.Name), "", func(category string) {
+			if category == "" {
+				u.setStatus("分类名称不能为空", colorStatusError)
+				return
+			}
+			if err := u.db.AddLocalStation(category, radio); err != nil {
+				u.setStatus(fmt.Sprintf("加入本地分类失败: %v", err), colorStatusError)
+				return
+			}
+			if err := u.reloadCatalog(); err != nil {
+				logger.Error("重新加载电台目录失败: %v", err)
+			}
+			u.setStatus(fmt.Sprintf("已将 %s 加入本地分类: %s", radio.Name, category), colorStatusOK)
+		})
+		return true
+	case "a":
+		if radio, ok := u.selectedDiscoverRadio(); ok {
+			u.toggleFavorite(radio)
+		}
+		return true
+	case "<Enter>":
+		if radio, ok := u.selectedDiscoverRadio(); ok {
+			u.playRadio(radio)
+		}
+		return true
+	case "j", "<Down>", "k", "<Up>":
+		if e.ID == "j" || e.ID == "<Down>" {
+			if u.radioList.SelectedRow < len(u.radioList.Rows)-1 {
+				u.radioList.ScrollDown()
+			}
+		} else if u.radioList.SelectedRow > 1 {
+			u.radioList.ScrollUp()
+		}
+		u.showDiscoverDetails()
+		return true
+	}
+	return false
+}
+
+// selectedDiscoverRadio 返回发现视图中选中的电台
+func (u *UI) selectedDiscoverRadio() (model.Radio, bool) {
+	i := u.radioList.SelectedRow - 1
+	if i < 0 || i >= len(u.discoverResults) {
+		return model.Radio{}, false
+	}
+	return u.discoverResults[i], true
+}
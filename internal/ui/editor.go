@@ -0,0 +1,332 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"FMgo/internal/catalog"
+	"FMgo/internal/logger"
+	"FMgo/internal/model"
+
+	ui "github.com/gizak/termui/v3"
+)
+
+const editorHelp = "'a' 添加电台 | 'c' 添加分类 | 'r' 重命名 | 'u' 修改地址 | 'm' 移到分类 | 'J'/'K' 下移/上移 | 'x' 删除 | Enter 播放 | Esc 返回"
+
+// editorItem 是编辑视图中的一行：分类行的 radio 为 nil
+type editorItem struct {
+	category string
+	radio    *model.Radio
+}
+
+// enterEditor 打开本地目录编辑视图
+func (u *UI) enterEditor() {
+	u.currentView = "editor"
+	u.showEditor("", "")
+	u.setStatus(editorHelp, colorText)
+}
+
+// exitEditor 返回电台列表，并重新加载目录以显示修改
+func (u *UI) exitEditor() {
+	u.currentView = "main"
+	if err := u.reloadCatalog(); err != nil {
+		logger.Error("重新加载电台目录失败: %v", err)
+	}
+	u.updateRadioList(false)
+	u.setStatus(defaultStatus, colorText)
+}
+
+// showEditor 显示本地目录，并选中 category/name 所在的行（name 为空时选中分类）
+func (u *UI) showEditor(category, name string) {
+	categories, err := u.db.GetLocalCatalog()
+	if err != nil {
+		u.setStatus(fmt.Sprintf("加载本地目录失败: %v", err), colorStatusError)
+		return
+	}
+
+	prev := u.radioList.SelectedRow
+	u.editorItems = u.editorItems[:0]
+	rows := []string{}
+	radios := make(map[int]model.Radio)
+	selected := -1
+	for _, cat := range categories {
+		if cat.Name == category && name == "" {
+			selected = len(rows)
+		}
+		u.editorItems = append(u.editorItems, editorItem{category: cat.Name})
+		rows = append(rows, categoryRow("■ "+cat.Name))
+		for i := range cat.RadioList {
+			radio := cat.RadioList[i]
+			if cat.Name == category && radio.Name == name && selected < 0 {
+				selected = len(rows)
+			}
+			u.editorItems = append(u.editorItems, editorItem{category: cat.Name, radio: &radio})
+			radios[len(rows)] = radio
+			rows = append(rows, fmt.Sprintf(" •%s", radio.Name))
+		}
+	}
+	if len(rows) == 0 {
+		rows = append(rows, "  本地目录为空，按 'c' 添加分类")
+	}
+
+	if selected < 0 {
+		selected = prev
+	}
+	if selected >= len(rows) {
+		selected = len(rows) - 1
+	}
+	if selected < 0 {
+		selected = 0
+	}
+
+	u.radioList.Title = "编辑本地目录"
+	u.setRows(rows, radios)
+	u.radioList.SelectedRow = selected
+	ui.Render(u.grid)
+}
+
+// selectedEditorItem 返回编辑视图中选中的行
+func (u *UI) selectedEditorItem() (editorItem, bool) {
+	i := u.radioList.SelectedRow
+	if i < 0 || i >= len(u.editorItems) {
+		return editorItem{}, false
+	}
+	return u.editorItems[i], true
+}
+
+// handleEditorKeys 处理编辑视图特有的按键，返回是否已处理
+func (u *UI) handleEditorKeys(e ui.Event) bool {
+	item, ok := u.selectedEditorItem()
+
+	switch e.ID {
+	case "<Escape>", "<Tab>":
+		u.exitEditor()
+	case "/":
+		// 编辑视图中不支持搜索
+	case "j", "<Down>":
+		if u.radioList.SelectedRow < len(u.radioList.Rows)-1 {
+			u.radioList.ScrollDown()
+		}
+		u.showEditorDetails()
+	case "k", "<Up>":
+		if u.radioList.SelectedRow > 0 {
+			u.radioList.ScrollUp()
+		}
+		u.showEditorDetails()
+	case "<Enter>":
+		if ok && item.radio != nil {
+			radio := *item.radio
+			radio.Source = catalog.LocalName
+			u.playRadio(radio)
+		}
+	case "c":
+		u.startPrompt("新分类名称", "", func(name string) {
+			if name == "" {
+				u.setStatus("分类名称不能为空", colorStatusError)
+				return
+			}
+			if _, err := u.db.AddLocalCategory(name); err != nil {
+				u.setStatus(fmt.Sprintf("添加分类失败: %v", err), colorStatusError)
+				return
+			}
+			u.editorSaved(name, "", "已添加分类: "+name)
+		})
+	case "a":
+		if !ok {
+			u.setStatus("请先按 'c' 添加分类", colorStatusError)
+			return true
+		}
+		u.addEditorStation(item.category)
+	case "r":
+		if !ok {
+			return true
+		}
+		u.renameEditorItem(item)
+	case "u":
+		if !ok || item.radio == nil {
+			return true
+		}
+		radio := *item.radio
+		u.startPrompt(fmt.Sprintf("%s 的播放地址", radio.Name), radio.PlayURL, func(playURL string) {
+			if playURL == "" || playURL == radio.PlayURL {
+				u.setStatus("未修改", colorText)
+				return
+			}
+			u.probeAndSave(playURL, func() error {
+				return u.db.UpdateLocalStation(item.category, radio.Name, model.Radio{Name: radio.Name, PlayURL: playURL})
+			}, item.category, radio.Name, "已修改播放地址: "+radio.Name)
+		})
+	case "m":
+		if !ok || item.radio == nil {
+			return true
+		}
+		name := item.radio.Name
+		u.startPrompt(fmt.Sprintf("将 %s 移到分类", name), "", func(target string) {
+			if target == "" || target == item.category {
+				u.setStatus("未移动", colorText)
+				return
+			}
+			if err := u.db.MoveLocalStationTo(item.category, name, target); err != nil {
+				u.setStatus(fmt.Sprintf("移动失败: %v", err), colorStatusError)
+				return
+			}
+			u.editorSaved(target, name, fmt.Sprintf("已将 %s 移到分类: %s", name, target))
+		})
+	case "J", "K":
+		if !ok {
+			return true
+		}
+		delta := 1
+		if e.ID == "K" {
+			delta = -1
+		}
+		var err error
+		name := ""
+		if item.radio != nil {
+			name = item.radio.Name
+			err = u.db.MoveLocalStation(item.category, name, delta)
+		} else {
+			err = u.db.MoveLocalCategory(item.category, delta)
+		}
+		if err != nil {
+			u.setStatus(fmt.Sprintf("移动失败: %v", err), colorStatusError)
+			return true
+		}
+		u.showEditor(item.category, name)
+	case "x":
+		if !ok {
+			return true
+		}
+		u.deleteEditorItem(item)
+	default:
+		return false
+	}
+	return true
+}
+
+// addEditorStation 依次询问名称与播放地址，检查地址后添加到 category
+func (u *UI) addEditorStation(category string) {
+	u.startPrompt(fmt.Sprintf("添加到 %s 的电台名称", category), "", func(name string) {
+		if name == "" {
+			u.setStatus("电台名称不能为空", colorStatusError)
+			return
+		}
+		u.startPrompt(fmt.Sprintf("%s 的播放地址", name), "", func(playURL string) {
+			radio := model.Radio{Name: name, PlayURL: playURL}
+			u.probeAndSave(playURL, func() error {
+				return u.db.AddLocalStation(category, radio)
+			}, category, name, "已添加电台: "+name)
+		})
+	})
+}
+
+// renameEditorItem 重命名分类或电台
+func (u *UI) renameEditorItem(item editorItem) {
+	if item.radio == nil {
+		u.startPrompt("重命名分类", item.category, func(name string) {
+			if name == "" || name == item.category {
+				u.setStatus("未修改", colorText)
+				return
+			}
+			if err := u.db.RenameLocalCategory(item.category, name); err != nil {
+				u.setStatus(fmt.Sprintf("重命名失败: %v", err), colorStatusError)
+				return
+			}
+			u.collapsedCats[name] = u.collapsedCats[item.category]
+			u.editorSaved(name, "", fmt.Sprintf("已将分类 %s 重命名为 %s", item.category, name))
+		})
+		return
+	}
+
+	radio := *item.radio
+	u.startPrompt("重命名电台", radio.Name, func(name string) {
+		if name == "" || name == radio.Name {
+			u.setStatus("未修改", colorText)
+			return
+		}
+		if err := u.db.UpdateLocalStation(item.category, radio.Name, model.Radio{Name: name, PlayURL: radio.PlayURL}); err != nil {
+			u.setStatus(fmt.Sprintf("重命名失败: %v", err), colorStatusError)
+			return
+		}
+		u.editorSaved(item.category, name, fmt.Sprintf("已将 %s 重命名为 %s", radio.Name, name))
+	})
+}
+
+// deleteEditorItem 确认后删除分类（连同其中的电台）或电台
+func (u *UI) deleteEditorItem(item editorItem) {
+	label := fmt.Sprintf("删除分类 %s 及其中的全部电台? (y/N)", item.category)
+	if item.radio != nil {
+		label = fmt.Sprintf("删除电台 %s? (y/N)", item.radio.Name)
+	}
+	u.startPrompt(label, "", func(answer string) {
+		if !strings.EqualFold(answer, "y") {
+			u.setStatus("已取消", colorText)
+			return
+		}
+		var err error
+		var name string
+		if item.radio != nil {
+			name = item.radio.Name
+			err = u.db.DeleteLocalStation(item.category, name)
+		} else {
+			name = item.category
+			err = u.db.DeleteLocalCategory(item.category)
+		}
+		if err != nil {
+			u.setStatus(fmt.Sprintf("删除失败: %v", err), colorStatusError)
+			return
+		}
+		u.editorSaved("", "", "已删除: "+name)
+	})
+}
+
+// probeAndSave 在后台检查播放地址，可用时保存；不可用时询问是否仍要保存
+func (u *UI) probeAndSave(playURL string, save func() error, category, name, done string) {
+	u.setStatus("正在检查播放地址...", colorText)
+	go func() {
+		err := catalog.ProbeStreamURL(playURL)
+		u.post(func() {
+			if err == nil {
+				u.saveEdit(save, category, name, done)
+				return
+			}
+			logger.Info("检查播放地址失败: %s: %v", playURL, err)
+			u.startPrompt(fmt.Sprintf("播放地址不可用(%v)，仍要保存? (y/N)", err), "", func(answer string) {
+				if !strings.EqualFold(answer, "y") {
+					u.setStatus("未保存", colorText)
+					return
+				}
+				u.saveEdit(save, category, name, done)
+			})
+		})
+	}()
+}
+
+func (u *UI) saveEdit(save func() error, category, name, done string) {
+	if err := save(); err != nil {
+		u.setStatus(fmt.Sprintf("保存失败: %v", err), colorStatusError)
+		return
+	}
+	u.editorSaved(category, name, done)
+}
+
+// editorSaved 刷新编辑视图与合并目录，并选中修改的行
+func (u *UI) editorSaved(category, name, status string) {
+	if err := u.reloadCatalog(); err != nil {
+		logger.Error("重新加载电台目录失败: %v", err)
+	}
+	if u.currentView == "editor" {
+		u.showEditor(category, name)
+	}
+	u.setStatus(status, colorStatusOK)
+}
+
+// showEditorDetails 在状态栏显示选中电台的播放地址
+func (u *UI) showEditorDetails() {
+	item, ok := u.selectedEditorItem()
+	if !ok || item.radio == nil {
+		u.setStatus(editorHelp, colorText)
+		return
+	}
+	u.setStatus(fmt.Sprintf("%s | %s", item.radio.Name, item.radio.PlayURL), colorText)
+}
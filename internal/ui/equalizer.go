@@ -0,0 +1,169 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"FMgo/internal/audio"
+	"FMgo/internal/logger"
+	"FMgo/internal/model"
+
+	ui "github.com/gizak/termui/v3"
+)
+
+// enterEqualizer 打开当前电台的均衡器编辑器
+func (u *UI) enterEqualizer() {
+	if u.currentRadio == nil {
+		u.setStatus("请先播放一个电台再调整均衡器", colorStatusError)
+		return
+	}
+	u.eqRadio = *u.currentRadio
+	u.eqBands = u.player.EqualizerBands()
+	u.eqSaved = u.eqBands
+	u.eqSelected = 0
+	u.eqPrevView = u.currentView
+	u.currentView = "equalizer"
+	u.setStatus("↑↓ 选择频段 | ←→ 调整增益 | 'p' 切换预设 | Enter 保存 | Esc 取消", colorText)
+	u.showEqualizer()
+}
+
+// exitEqualizer 关闭均衡器编辑器，save 为 false 时恢复进入前的设置
+func (u *UI) exitEqualizer(save bool) {
+	if save {
+		setting := model.EQSetting{StationID: u.eqRadio.StationID(), RadioName: u.eqRadio.Name, Preset: u.eqPreset}
+		for _, b := range u.eqBands {
+			setting.Gains = append(setting.Gains, b.Gain)
+		}
+		if err := u.db.SaveStationEQ(setting); err != nil {
+			u.setStatus(fmt.Sprintf("保存均衡器失败: %v", err), colorStatusError)
+			return
+		}
+		u.setStatus(fmt.Sprintf("已为 %s 保存均衡器: %s", u.eqRadio.Name, presetLabel(u.eqPreset)), colorStatusOK)
+	} else {
+		u.player.SetEqualizer(u.eqSaved)
+		u.setStatus(defaultStatus, colorText)
+	}
+	u.currentView = u.eqPrevView
+	u.updateRadioList(false)
+}
+
+// leaveEqualizer 在播放停止或切台失败时关闭均衡器编辑器，不保存设置
+func (u *UI) leaveEqualizer() {
+	if u.currentView == "equalizer" {
+		u.exitEqualizer(false)
+	}
+}
+
+// handleEqualizerKeys 处理均衡器编辑器中的按键
+func (u *UI) handleEqualizerKeys(e ui.Event) {
+	switch e.ID {
+	case "<Escape>":
+		u.exitEqualizer(false)
+		return
+	case "<Enter>":
+		u.exitEqualizer(true)
+		return
+	case "j", "<Down>":
+		if u.eqSelected < len(u.eqBands)-1 {
+			u.eqSelected++
+		}
+	case "k", "<Up>":
+		if u.eqSelected > 0 {
+			u.eqSelected--
+		}
+	case "l", "<Right>":
+		u.adjustBand(1)
+	case "h", "<Left>":
+		u.adjustBand(-1)
+	case "p":
+		u.cyclePreset()
+	}
+	u.showEqualizer()
+}
+
+// adjustBand 调整选中频段的增益并切换到自定义预设
+func (u *UI) adjustBand(delta float64) {
+	bands := append([]audio.Band(nil), u.eqBands...)
+	gain := bands[u.eqSelected].Gain + delta
+	if gain > audio.MaxGain || gain < -audio.MaxGain {
+		return
+	}
+	bands[u.eqSelected].Gain = gain
+	u.eqBands = bands
+	u.eqPreset = audio.PresetCustom
+	u.player.SetEqualizer(bands)
+}
+
+// cyclePreset 切换到下一个内置预设
+func (u *UI) cyclePreset() {
+	next := 0
+	for i, p := range audio.Presets {
+		if p.Name == u.eqPreset {
+			next = (i + 1) % len(audio.Presets)
+		}
+	}
+	preset := audio.Presets[next]
+	u.eqPreset = preset.Name
+	u.eqBands = preset.Bands
+	u.player.SetEqualizer(preset.Bands)
+}
+
+// showEqualizer 在列表区域绘制频段与增益
+func (u *UI) showEqualizer() {
+	items := []string{fmt.Sprintf("[均衡器: %s](fg:yellow)", presetLabel(u.eqPreset))}
+	for _, b := range u.eqBands {
+		items = append(items, fmt.Sprintf(" %-8s %+5.1f dB  %s", formatFreq(b.Freq), b.Gain, gainBar(b.Gain)))
+	}
+
+	u.radioList.Title = fmt.Sprintf("均衡器 - %s", u.eqRadio.Name)
+	u.radioList.Rows = items
+	u.radioList.SelectedRow = u.eqSelected + 1
+	ui.Render(u.grid)
+}
+
+// applyStationEQ 应用电台保存的均衡器设置，未保存过则使用平直预设
+func (u *UI) applyStationEQ(radio model.Radio) {
+	u.eqPreset = audio.PresetFlat
+	bands := audio.FlatBands()
+
+	setting, err := u.db.GetStationEQ(radio)
+	if err != nil {
+		logger.Error("读取均衡器设置失败: %v", err)
+	}
+	if setting != nil {
+		u.eqPreset = setting.Preset
+		for i := range bands {
+			if i < len(setting.Gains) {
+				bands[i].Gain = setting.Gains[i]
+			}
+		}
+	}
+	u.player.SetEqualizer(bands)
+}
+
+func presetLabel(name string) string {
+	if name == audio.PresetCustom {
+		return "自定义"
+	}
+	if p, ok := audio.FindPreset(name); ok {
+		return p.Label
+	}
+	return name
+}
+
+func formatFreq(freq float64) string {
+	if freq >= 1000 {
+		return fmt.Sprintf("%.1fkHz", freq/1000)
+	}
+	return fmt.Sprintf("%.0fHz", freq)
+}
+
+// gainBar 用字符条表示增益，中间的 '|' 为 0 dB
+func gainBar(gain float64) string {
+	width := int(audio.MaxGain)
+	n := int(gain)
+	if n < 0 {
+		return strings.Repeat(" ", width+n) + strings.Repeat("█", -n) + "|"
+	}
+	return strings.Repeat(" ", width) + "|" + strings.Repeat("█", n)
+}
@@ -0,0 +1,130 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"FMgo/internal/model"
+
+	ui "github.com/gizak/termui/v3"
+)
+
+// maxPresets 是数字键可直接播放的收藏数量
+const maxPresets = 9
+
+// favoritesHelp 是收藏视图的按键说明
+const favoritesHelp = "Enter 播放 | J/K 下移/上移 | 'm' 移到文件夹 | 'N' 备注 | +/- 评分 | 'a' 取消收藏 | 1-9 播放预设 | Tab 返回"
+
+// noteMarkup 替换备注中会被当作样式标记的括号
+var noteMarkup = strings.NewReplacer("[", "(", "]", ")")
+
+// favoriteRow 返回收藏的显示行：前九个收藏带有预设编号，其后为评分与备注
+func favoriteRow(preset int, f model.Favorite, radio model.Radio, found bool) string {
+	row := savedRow(radio, found)
+	if preset <= maxPresets {
+		row = fmt.Sprintf(" •%d %s", preset, strings.TrimPrefix(row, " •"))
+	}
+	if f.Rating > 0 {
+		row += fmt.Sprintf(" [%s%s](fg:yellow)", strings.Repeat("★", f.Rating), strings.Repeat("☆", 5-f.Rating))
+	}
+	if f.Note != "" {
+		row += fmt.Sprintf(" [%s](fg:white)", noteMarkup.Replace(f.Note))
+	}
+	return row
+}
+
+// selectedFavorite 返回收藏视图中选中的收藏
+func (u *UI) selectedFavorite() (model.Favorite, bool) {
+	radio, ok := u.rowRadio(u.radioList.SelectedRow)
+	if !ok {
+		return model.Favorite{}, false
+	}
+	for _, f := range u.favorites {
+		if f.ID == radio.ID {
+			return f, true
+		}
+	}
+	return model.Favorite{}, false
+}
+
+// showFavorite 刷新收藏视图并选中 stationID 对应的行
+func (u *UI) showFavorite(stationID string) {
+	u.showFavorites()
+	for row, radio := range u.rowRadios {
+		if radio.ID == stationID {
+			u.radioList.SelectedRow = row
+			break
+		}
+	}
+	ui.Render(u.grid)
+}
+
+// handleFavoritesKeys 处理收藏视图中的管理按键，返回是否已处理
+func (u *UI) handleFavoritesKeys(e ui.Event) bool {
+	switch e.ID {
+	case "J", "K", "m", "N", "+", "=", "-":
+	default:
+		return false
+	}
+	f, ok := u.selectedFavorite()
+	if !ok {
+		return true
+	}
+
+	switch e.ID {
+	case "J", "K":
+		delta := 1
+		if e.ID == "K" {
+			delta = -1
+		}
+		u.saveFavorite(f.ID, func() error { return u.db.MoveFavorite(f.ID, delta) }, "")
+	case "m":
+		u.startPrompt("移到文件夹(留空移出文件夹): ", f.Folder, func(text string) {
+			folder := strings.TrimSpace(text)
+			u.saveFavorite(f.ID, func() error { return u.db.SetFavoriteFolder(f.ID, folder) }, "已移动: "+f.Name)
+		})
+	case "N":
+		u.startPrompt("备注: ", f.Note, func(text string) {
+			note := strings.TrimSpace(text)
+			u.saveFavorite(f.ID, func() error { return u.db.SetFavoriteNote(f.ID, note) }, "已保存备注: "+f.Name)
+		})
+	case "+", "=", "-":
+		rating := f.Rating + 1
+		if e.ID == "-" {
+			rating = f.Rating - 1
+		}
+		if rating < 0 || rating > 5 {
+			return true
+		}
+		u.saveFavorite(f.ID, func() error { return u.db.SetFavoriteRating(f.ID, rating) }, fmt.Sprintf("%s 评分: %d", f.Name, rating))
+	}
+	return true
+}
+
+// saveFavorite 执行收藏修改并刷新视图，status 非空时显示在状态栏
+func (u *UI) saveFavorite(stationID string, save func() error, status string) {
+	if err := save(); err != nil {
+		u.setStatus(fmt.Sprintf("保存收藏失败: %v", err), colorStatusError)
+		return
+	}
+	u.showFavorite(stationID)
+	if status != "" {
+		u.setStatus(status, colorStatusOK)
+	}
+}
+
+// playPreset 播放收藏列表中的第 n 个电台（1-9），与收藏视图中的编号一致
+func (u *UI) playPreset(n int) {
+	favorites, err := u.db.GetFavorites()
+	if err != nil {
+		u.setStatus(fmt.Sprintf("加载收藏失败: %v", err), colorStatusError)
+		return
+	}
+	if n < 1 || n > len(favorites) {
+		u.setStatus(fmt.Sprintf("预设 %d 未设置：收藏列表中的前九个电台依次对应数字键 1-9", n), colorStatusError)
+		return
+	}
+	f := favorites[n-1]
+	radio, _ := u.resolveStation(f.ID, f.Name, f.PlayURL)
+	u.playRadio(radio)
+}
@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"FMgo/internal/model"
+
+	ui "github.com/gizak/termui/v3"
+)
+
+// historyHelp 是历史视图的按键说明
+const historyHelp = "Enter 播放 | 'x' 删除该电台的记录 | 'X' 清空历史 | 'a' 收藏/取消 | Tab 切换到收藏"
+
+// selectedHistory 返回历史视图中选中的记录
+func (u *UI) selectedHistory() (model.StationHistory, bool) {
+	i := u.radioList.SelectedRow - 1 // 第一行是标题
+	if i < 0 || i >= len(u.history) {
+		return model.StationHistory{}, false
+	}
+	return u.history[i], true
+}
+
+// handleHistoryKeys 处理历史视图中的删除按键，返回是否已处理
+func (u *UI) handleHistoryKeys(e ui.Event) bool {
+	switch e.ID {
+	case "x":
+		h, ok := u.selectedHistory()
+		if !ok {
+			return true
+		}
+		u.confirm(fmt.Sprintf("删除 %s 的全部收听记录? (y/N)", h.RadioName), func() {
+			u.saveHistory(func() error { return u.db.DeleteStationHistory(h.StationID) }, "已删除: "+h.RadioName)
+		})
+	case "X":
+		u.confirm("清空全部播放历史? (y/N)", func() {
+			u.saveHistory(u.db.ClearHistory, "已清空播放历史")
+		})
+	default:
+		return false
+	}
+	return true
+}
+
+// confirm 显示确认提示，输入 y 时执行 action
+func (u *UI) confirm(label string, action func()) {
+	u.startPrompt(label, "", func(text string) {
+		if strings.EqualFold(text, "y") {
+			action()
+			return
+		}
+		u.setStatus("已取消", colorText)
+	})
+}
+
+// saveHistory 执行历史记录修改并刷新视图
+func (u *UI) saveHistory(save func() error, status string) {
+	if err := save(); err != nil {
+		u.setStatus(fmt.Sprintf("删除历史记录失败: %v", err), colorStatusError)
+		return
+	}
+	selected := u.radioList.SelectedRow
+	u.showHistory()
+	if selected < len(u.radioList.Rows) {
+		u.radioList.SelectedRow = selected
+	}
+	u.setStatus(status, colorStatusOK)
+}
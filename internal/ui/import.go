@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"FMgo/internal/catalog"
+	"FMgo/internal/logger"
+)
+
+// startImport 依次询问导入文件与目标分类，在后台导入到本地目录
+func (u *UI) startImport() {
+	u.startPrompt("导入文件(M3U/PLS/OPML/JSON)", "", func(path string) {
+		if path == "" {
+			u.setStatus("已取消", colorText)
+			return
+		}
+		path = expandHome(path)
+		u.startPrompt("导入到分类(留空使用文件中的分组)", "", func(category string) {
+			u.setStatus("正在导入...", colorText)
+			go func() {
+				result, err := catalog.ImportFile(u.catalog, u.db, path, "", category)
+				u.post(func() {
+					if err != nil {
+						logger.Error("导入电台失败: %v", err)
+						u.setStatus("导入失败: "+err.Error(), colorStatusError)
+						return
+					}
+					if err := u.reloadCatalog(); err != nil {
+						logger.Error("重新加载电台目录失败: %v", err)
+					}
+					u.setStatus(result.String(), colorStatusOK)
+				})
+			}()
+		})
+	})
+}
+
+// expandHome 展开路径开头的 ~
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
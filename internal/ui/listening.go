@@ -0,0 +1,149 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"FMgo/internal/model"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/gizak/termui/v3/widgets"
+)
+
+// listeningPeriods 是收听统计视图可切换的统计天数，0 表示全部
+var listeningPeriods = []int{7, 30, 0}
+
+var weekdayLabels = []string{"一", "二", "三", "四", "五", "六", "日"}
+
+// setupListeningView 创建收听统计视图的图表
+func (u *UI) setupListeningView() {
+	newBarChart := func(title string, color ui.Color) *widgets.BarChart {
+		bc := widgets.NewBarChart()
+		bc.Title = title
+		bc.BorderStyle = ui.NewStyle(colorBorder)
+		bc.TitleStyle = ui.NewStyle(colorTitle, ui.ColorClear, ui.ModifierBold)
+		bc.BarColors = []ui.Color{color}
+		bc.LabelStyles = []ui.Style{ui.NewStyle(colorText)}
+		bc.NumStyles = []ui.Style{ui.NewStyle(ui.ColorBlack)}
+		bc.BarWidth = 2
+		bc.NumFormatter = func(minutes float64) string {
+			if minutes < 1 {
+				return ""
+			}
+			return fmt.Sprintf("%.0f", minutes)
+		}
+		return bc
+	}
+	u.listeningHours = newBarChart("按时段 (分钟)", ui.ColorCyan)
+	u.listeningWeekdays = newBarChart("按星期 (分钟)", ui.ColorGreen)
+	u.listeningWeekdays.BarWidth = 3
+
+	newParagraph := func(title string) *widgets.Paragraph {
+		p := widgets.NewParagraph()
+		p.Title = title
+		p.BorderStyle = ui.NewStyle(colorBorder)
+		p.TitleStyle = ui.NewStyle(colorTitle, ui.ColorClear, ui.ModifierBold)
+		p.TextStyle = ui.NewStyle(colorText)
+		p.PaddingLeft = 1
+		return p
+	}
+	u.listeningTop = newParagraph("排行")
+	u.listeningSummary = newParagraph("概况")
+}
+
+// enterListeningView 打开收听统计视图，返回时回到主列表、历史或收藏视图
+func (u *UI) enterListeningView() {
+	u.listeningPrevView = "main"
+	if u.currentView == "history" || u.currentView == "favorites" {
+		u.listeningPrevView = u.currentView
+	}
+	u.currentView = "listening"
+	u.setStatus("'p' 切换统计范围 | Esc 返回", colorText)
+	u.refreshListeningView()
+	u.layout()
+	ui.Render(u.grid)
+}
+
+// exitListeningView 关闭收听统计视图
+func (u *UI) exitListeningView() {
+	u.currentView = u.listeningPrevView
+	u.layout()
+	u.setStatus(defaultStatus, colorText)
+	u.updateRadioList(false)
+}
+
+// handleListeningKeys 处理收听统计视图中的按键，返回是否已处理。退出与窗口大小变化交给主循环
+func (u *UI) handleListeningKeys(e ui.Event) bool {
+	switch e.ID {
+	case "q", "<C-c>", "<Resize>":
+		return false
+	case "<Escape>", "S":
+		u.exitListeningView()
+	case "p":
+		u.listeningPeriod = (u.listeningPeriod + 1) % len(listeningPeriods)
+		u.refreshListeningView()
+		ui.Render(u.grid)
+	}
+	return true
+}
+
+// refreshListeningView 重新生成统计报告并更新图表
+func (u *UI) refreshListeningView() {
+	days := listeningPeriods[u.listeningPeriod]
+	report, err := u.db.ListeningReport(time.Now(), days, u.categories)
+	if err != nil {
+		u.setStatus(fmt.Sprintf("生成收听统计失败: %v", err), colorStatusError)
+		return
+	}
+
+	u.listeningHours.Data, u.listeningHours.Labels = nil, nil
+	for hour, seconds := range report.ByHour {
+		u.listeningHours.Data = append(u.listeningHours.Data, float64(seconds)/60)
+		u.listeningHours.Labels = append(u.listeningHours.Labels, fmt.Sprintf("%02d", hour))
+	}
+	u.listeningWeekdays.Data = nil
+	for _, seconds := range report.ByWeekday {
+		u.listeningWeekdays.Data = append(u.listeningWeekdays.Data, float64(seconds)/60)
+	}
+	u.listeningWeekdays.Labels = weekdayLabels
+	// 没有数据时固定刻度，避免按 0 计算柱高
+	for _, bc := range []*widgets.BarChart{u.listeningHours, u.listeningWeekdays} {
+		bc.MaxVal = 0
+		if report.TotalSeconds < 60 {
+			bc.MaxVal = 1
+		}
+	}
+
+	var top strings.Builder
+	writeTop := func(title string, stations []model.StationTotal) {
+		fmt.Fprintf(&top, "[%s](fg:yellow)\n", title)
+		if len(stations) == 0 {
+			top.WriteString("  暂无记录\n")
+		}
+		for i, t := range stations {
+			fmt.Fprintf(&top, " %d. %s  %s\n", i+1, t.RadioName, formatListened(time.Duration(t.ListenedSeconds)*time.Second))
+		}
+	}
+	writeTop("本周", report.TopWeek)
+	top.WriteString("\n")
+	writeTop("本月", report.TopMonth)
+	u.listeningTop.Text = top.String()
+
+	period := "全部记录"
+	if days > 0 {
+		period = fmt.Sprintf("最近 %d 天", days)
+	}
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "范围: %s\n", period)
+	fmt.Fprintf(&summary, "总收听: %s\n", formatListened(time.Duration(report.TotalSeconds)*time.Second))
+	fmt.Fprintf(&summary, "连续收听: %d 天 (最长 %d 天)\n\n", report.CurrentStreak, report.LongestStreak)
+	summary.WriteString("[按分类](fg:yellow)\n")
+	if len(report.Categories) == 0 {
+		summary.WriteString("  暂无记录\n")
+	}
+	for _, c := range report.Categories {
+		fmt.Fprintf(&summary, " %s  %s\n", c.Category, formatListened(time.Duration(c.ListenedSeconds)*time.Second))
+	}
+	u.listeningSummary.Text = summary.String()
+}
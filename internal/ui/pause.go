@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+)
+
+// togglePause 暂停或继续播放
+func (u *UI) togglePause() {
+	if !u.player.IsPlaying() {
+		return
+	}
+	if !u.player.IsPaused() {
+		u.player.Pause()
+		u.updatePauseStatus()
+		return
+	}
+
+	full := u.player.PauseBufferFull()
+	if err := u.player.Resume(); err != nil {
+		u.setStatus(fmt.Sprintf("继续播放失败: %v", err), colorStatusError)
+		return
+	}
+	if full || u.player.Behind() == 0 {
+		u.setStatus(fmt.Sprintf("正在播放: %s (直播)", u.currentRadio.Name), colorStatusOK)
+		return
+	}
+	u.setStatus(fmt.Sprintf("正在播放: %s (落后直播 %v，按 'L' 回到直播)", u.currentRadio.Name, u.player.Behind().Round(time.Second)), colorStatusOK)
+}
+
+// resumeLive 丢弃时移缓冲并回到直播
+func (u *UI) resumeLive() {
+	if !u.player.IsPlaying() {
+		return
+	}
+	if err := u.player.ResumeLive(); err != nil {
+		u.setStatus(fmt.Sprintf("回到直播失败: %v", err), colorStatusError)
+		return
+	}
+	u.setStatus(fmt.Sprintf("正在播放: %s (直播)", u.currentRadio.Name), colorStatusOK)
+}
+
+// updatePauseStatus 在状态栏显示暂停时长与时移缓冲状态
+func (u *UI) updatePauseStatus() {
+	paused := u.player.Behind().Round(time.Second)
+	if u.player.PauseBufferFull() {
+		u.setStatus(fmt.Sprintf("已暂停 %v，缓冲已满，继续播放将回到直播 | 空格 继续", paused), colorHighlight)
+		return
+	}
+	u.setStatus(fmt.Sprintf("已暂停 %v | 空格 从暂停处继续 | 'L' 回到直播", paused), colorHighlight)
+}
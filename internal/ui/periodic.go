@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"time"
+
+	"FMgo/internal/logger"
+)
+
+// SchedulePeriodic 在后台定期执行 task：首次在 delay 后执行，之后每隔 interval 执行一次。
+// 执行完成后在状态栏显示结果并重新加载电台目录
+func (u *UI) SchedulePeriodic(name string, delay, interval time.Duration, task func() (string, error)) {
+	go func() {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		for range timer.C {
+			logger.Info("开始执行定时任务: %s", name)
+			msg, err := task()
+			u.post(func() {
+				if err != nil {
+					logger.Error("%s失败: %v", name, err)
+					u.setStatus(name+"失败: "+err.Error(), colorStatusError)
+					return
+				}
+				if err := u.reloadCatalog(); err != nil {
+					logger.Error("重新加载电台目录失败: %v", err)
+				}
+				u.setStatus(name+": "+msg, colorStatusOK)
+			})
+			timer.Reset(interval)
+		}
+	}()
+}
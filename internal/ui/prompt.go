@@ -0,0 +1,106 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	ui "github.com/gizak/termui/v3"
+)
+
+// startPrompt 在输入框中显示提示并等待用户输入，Enter 提交，Esc 取消
+func (u *UI) startPrompt(label, initial string, submit func(text string)) {
+	u.prompting = true
+	u.promptLabel = label
+	u.promptText = initial
+	u.promptSubmit = submit
+	u.renderPrompt()
+}
+
+// handlePromptKeys 处理输入提示中的按键
+func (u *UI) handlePromptKeys(e ui.Event) {
+	switch e.ID {
+	case "<Escape>":
+		u.endPrompt()
+		u.setStatus("已取消", colorText)
+		return
+	case "<Enter>":
+		text := strings.TrimSpace(u.promptText)
+		submit := u.promptSubmit
+		u.endPrompt()
+		submit(text)
+		return
+	case "<Backspace>":
+		if len(u.promptText) > 0 {
+			r := []rune(u.promptText)
+			u.promptText = string(r[:len(r)-1])
+		}
+	case "<Space>":
+		u.promptText += " "
+	default:
+		if len([]rune(e.ID)) == 1 { // 支持中文输入
+			u.promptText += e.ID
+		}
+	}
+	u.renderPrompt()
+}
+
+func (u *UI) endPrompt() {
+	u.prompting = false
+	u.promptText = ""
+	u.promptSubmit = nil
+	u.searchInput.Text = ""
+	ui.Render(u.grid)
+}
+
+func (u *UI) renderPrompt() {
+	u.searchInput.Text = fmt.Sprintf("%s: %s", u.promptLabel, u.promptText)
+	ui.Render(u.grid)
+}
+
+// post 将后台任务的结果交给事件循环在 UI 协程中执行
+func (u *UI) post(fn func()) {
+	u.updates <- fn
+}
+
+// reloadCatalog 重新加载合并后的电台目录，保留分类折叠状态与选中的行
+func (u *UI) reloadCatalog() error {
+	categories, err := u.catalog.Categories()
+	if err != nil {
+		return err
+	}
+
+	u.mu.Lock()
+	u.categories = categories
+	for _, cat := range categories {
+		if _, exists := u.collapsedCats[cat.Name]; !exists {
+			u.collapsedCats[cat.Name] = true
+		}
+	}
+	u.mu.Unlock()
+
+	if u.currentView == "main" && !u.isSearching {
+		var selected string
+		if len(u.radioList.Rows) > 0 {
+			selected = u.radioList.Rows[u.radioList.SelectedRow]
+		}
+		u.updateRadioList(false)
+		u.selectRow(selected)
+	}
+	return nil
+}
+
+// selectRow 选中与 row 相同的行，找不到时将选中项限制在列表范围内
+func (u *UI) selectRow(row string) {
+	for i, r := range u.radioList.Rows {
+		if r == row {
+			u.radioList.SelectedRow = i
+			return
+		}
+	}
+	if u.radioList.SelectedRow >= len(u.radioList.Rows) {
+		u.radioList.SelectedRow = len(u.radioList.Rows) - 1
+	}
+	if u.radioList.SelectedRow < 0 {
+		u.radioList.SelectedRow = 0
+	}
+}
@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"FMgo/internal/catalog"
+	"FMgo/internal/logger"
+	"FMgo/internal/model"
+)
+
+// configPollInterval 是检查配置文件是否变化的间隔
+const configPollInterval = 2 * time.Second
+
+// WatchConfig 监视配置文件（-config 与 catalog.d），变化后重新加载电台目录，
+// 保留当前选中项、分类折叠状态与正在播放的电台。新文件有误时保留原目录并在状态栏提示
+func (u *UI) WatchConfig(file *catalog.LayeredProvider) {
+	previous, _ := file.Categories()
+
+	go catalog.WatchFiles(file.Paths, configPollInterval, func() {
+		issues, err := file.Validate()
+		var categories []model.Category
+		if err == nil && !catalog.HasErrors(issues) {
+			categories, err = file.Categories()
+		}
+
+		u.post(func() {
+			if err != nil {
+				logger.Error("重新加载配置文件失败: %v", err)
+				u.setStatus(fmt.Sprintf("重新加载配置文件失败: %v", err), colorStatusError)
+				return
+			}
+			if catalog.HasErrors(issues) {
+				for _, issue := range issues {
+					logger.Error("%s", issue)
+				}
+				u.setStatus(fmt.Sprintf("配置文件有误，未重新加载: %s", firstError(issues)), colorStatusError)
+				return
+			}
+
+			added, removed := catalog.DiffCategories(previous, categories)
+			previous = categories
+			if err := u.reloadCatalog(); err != nil {
+				logger.Error("重新加载电台目录失败: %v", err)
+				u.setStatus(fmt.Sprintf("重新加载电台目录失败: %v", err), colorStatusError)
+				return
+			}
+			logger.Info("配置文件已重新加载: 新增 %v，移除 %v", added, removed)
+			u.setStatus("配置文件已重新加载: "+describeChanges(added, removed), colorStatusOK)
+		})
+	})
+}
+
+// CatalogUpdated 在电台目录来源于后台更新后重新加载电台目录，name 显示在状态栏
+func (u *UI) CatalogUpdated(name string) {
+	u.post(func() {
+		if err := u.reloadCatalog(); err != nil {
+			logger.Error("重新加载电台目录失败: %v", err)
+			u.setStatus(fmt.Sprintf("重新加载电台目录失败: %v", err), colorStatusError)
+			return
+		}
+		u.setStatus(name+": 已更新", colorStatusOK)
+	})
+}
+
+// firstError 返回第一个错误，并注明错误总数
+func firstError(issues []catalog.Issue) string {
+	var first string
+	count := 0
+	for _, issue := range issues {
+		if issue.Warning {
+			continue
+		}
+		if count == 0 {
+			first = issue.String()
+		}
+		count++
+	}
+	if count > 1 {
+		return fmt.Sprintf("%s（共 %d 个错误）", first, count)
+	}
+	return first
+}
+
+// describeChanges 描述新增与移除的电台，名称过多时只列出前几个
+func describeChanges(added, removed []string) string {
+	if len(added) == 0 && len(removed) == 0 {
+		return "电台无变化"
+	}
+	var parts []string
+	if len(added) > 0 {
+		parts = append(parts, fmt.Sprintf("新增 %d 个(%s)", len(added), truncateNames(added)))
+	}
+	if len(removed) > 0 {
+		parts = append(parts, fmt.Sprintf("移除 %d 个(%s)", len(removed), truncateNames(removed)))
+	}
+	return strings.Join(parts, "，")
+}
+
+func truncateNames(names []string) string {
+	const limit = 3
+	if len(names) <= limit {
+		return strings.Join(names, "、")
+	}
+	return strings.Join(names[:limit], "、") + "…"
+}
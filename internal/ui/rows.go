@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"fmt"
+
+	"FMgo/internal/model"
+)
+
+// radioRow 返回电台在列表中的显示行，带有来源标注
+func radioRow(radio model.Radio) string {
+	if radio.Source == "" {
+		return fmt.Sprintf(" •%s", radio.Name)
+	}
+	return fmt.Sprintf(" •%s [%s](fg:blue)", radio.Name, radio.Source)
+}
+
+// savedRow 返回收藏或历史记录的显示行，电台已不在目录中时标注为已失效
+func savedRow(radio model.Radio, found bool) string {
+	if !found {
+		return fmt.Sprintf(" •%s [已失效](fg:red)", radio.Name)
+	}
+	return fmt.Sprintf(" •%s", radio.Name)
+}
+
+// resolveStation 在目录中查找收藏或历史记录指向的电台：先按电台 ID，再按播放地址，
+// 最后在 Radio Browser 的缓存中按播放地址查找。都找不到时返回由记录构造的电台
+// （使用保存的播放地址），found 为 false
+func (u *UI) resolveStation(id, name, playURL string) (radio model.Radio, found bool) {
+	var byURL *model.Radio
+	for _, cat := range u.categories {
+		for i := range cat.RadioList {
+			r := &cat.RadioList[i]
+			if r.StationID() == id {
+				return *r, true
+			}
+			if byURL == nil && r.PlayURL == playURL {
+				byURL = r
+			}
+		}
+	}
+	if byURL != nil {
+		return *byURL, true
+	}
+	if rb := u.radioBrowser(); rb != nil {
+		if radio, ok := rb.Lookup(playURL); ok {
+			return radio, true
+		}
+	}
+	return model.Radio{ID: id, Name: name, PlayURL: playURL}, false
+}
+
+// setRows 设置列表行，radios 为行号到电台的映射，没有对应电台的行（如分类标题）不在其中
+func (u *UI) setRows(rows []string, radios map[int]model.Radio) {
+	u.radioList.Rows = rows
+	u.rowRadios = radios
+}
+
+// rowRadio 返回列表第 row 行对应的电台
+func (u *UI) rowRadio(row int) (model.Radio, bool) {
+	radio, ok := u.rowRadios[row]
+	return radio, ok
+}
@@ -0,0 +1,136 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"FMgo/internal/logger"
+	"FMgo/internal/model"
+	"FMgo/internal/player"
+)
+
+// listenEntry 是一次收听对应的电台与数据库中的会话 ID（尚未记录时为 0）
+type listenEntry struct {
+	radio model.Radio
+	id    int64
+}
+
+// listenStation 是一个电台及其播放地址，record 为 false 时（无痕模式）不记录收听
+type listenStation struct {
+	url    string
+	radio  model.Radio
+	record bool
+}
+
+// SetIncognito 设置无痕模式：开启后本次运行不记录收听历史、播放统计与流量
+func (u *UI) SetIncognito(on bool) {
+	u.incognito = on
+	u.updateUsageTitle()
+}
+
+// expectSession 在开始播放 playURL 前记下对应的电台，供状态回调记录收听会话
+func (u *UI) expectSession(playURL string, radio model.Radio) {
+	if u.incognito {
+		return
+	}
+	u.listeningMu.Lock()
+	defer u.listeningMu.Unlock()
+	if _, ok := u.listening[playURL]; !ok {
+		u.listening[playURL] = &listenEntry{radio: radio}
+	}
+}
+
+// onPlayerState 处理播放器状态变化：开始收听时记录会话，结束时写入时长、原因与流量
+func (u *UI) onPlayerState(change player.StateChange) {
+	u.listeningMu.Lock()
+	defer u.listeningMu.Unlock()
+
+	s := change.Session
+	entry, ok := u.listening[s.URL]
+	if !ok && change.State == player.StatePlaying && u.switchedFrom != nil && u.switchedFrom.url == s.URL && u.switchedFrom.record {
+		// 新电台缓冲失败，原电台继续播放并重新开始收听
+		entry = &listenEntry{radio: u.switchedFrom.radio}
+		u.listening[s.URL] = entry
+		ok = true
+	}
+	if !ok {
+		return
+	}
+
+	switch change.State {
+	case player.StatePlaying:
+		if entry.id != 0 {
+			return
+		}
+		id, err := u.db.StartSession(entry.radio, s.StartedAt)
+		if err != nil {
+			logger.Error("记录收听失败: %v", err)
+			return
+		}
+		entry.id = id
+	case player.StateStopped:
+		delete(u.listening, s.URL)
+		if entry.id == 0 {
+			id, err := u.db.StartSession(entry.radio, s.StartedAt)
+			if err != nil {
+				logger.Error("记录收听失败: %v", err)
+				return
+			}
+			entry.id = id
+		}
+		if err := u.db.EndSession(entry.id, s.EndedAt, s.Listened(), s.Reason, s.Bytes); err != nil {
+			logger.Error("记录收听失败: %v", err)
+		}
+	}
+}
+
+// checkPlaybackFailed 检查播放是否因下载或解码失败而中途停止或切台失败，并提示用户
+func (u *UI) checkPlaybackFailed() {
+	if u.currentRadio == nil {
+		return
+	}
+	if !u.player.IsPlaying() {
+		u.saveStreamStats()
+		u.leaveEqualizer()
+		u.setStatus(fmt.Sprintf("播放中断: %s，请检查网络后重新播放", u.currentRadio.Name), colorStatusError)
+		u.currentRadio = nil
+		return
+	}
+
+	url := u.player.CurrentURL()
+	if url == u.playingURL {
+		return
+	}
+	u.listeningMu.Lock()
+	from := u.switchedFrom
+	u.switchedFrom = nil
+	u.listeningMu.Unlock()
+	if from == nil || from.url != url {
+		return
+	}
+	failed := u.currentRadio.Name
+	u.leaveEqualizer()
+	radio := from.radio
+	u.currentRadio, u.playingURL = &radio, url
+	u.applyStationEQ(radio)
+	u.saveLastStation(radio)
+	u.setStatus(fmt.Sprintf("无法播放 %s，继续播放 %s", failed, radio.Name), colorStatusError)
+}
+
+// historyRow 返回按电台汇总的历史记录显示行
+func historyRow(h model.StationHistory, radio model.Radio, found bool) string {
+	return fmt.Sprintf("%s  [%d 次 · 共 %s · 最近 %s](fg:white)", savedRow(radio, found),
+		h.Sessions, formatListened(h.Listened), h.LastPlayed.Format("01-02 15:04"))
+}
+
+// formatListened 返回收听时长的简短描述
+func formatListened(d time.Duration) string {
+	switch {
+	case d >= time.Hour:
+		return fmt.Sprintf("%d 小时 %d 分", int(d.Hours()), int(d.Minutes())%60)
+	case d >= time.Minute:
+		return fmt.Sprintf("%d 分", int(d.Minutes()))
+	default:
+		return "不足 1 分"
+	}
+}
@@ -0,0 +1,302 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"FMgo/internal/audio"
+	"FMgo/internal/db"
+	"FMgo/internal/logger"
+	"FMgo/internal/model"
+
+	ui "github.com/gizak/termui/v3"
+)
+
+const settingsHelp = "↑↓ 选择 | ←→/Enter 修改 | Esc/'o' 返回"
+
+// theme 是一组界面颜色
+type theme struct {
+	label                                    string
+	title, text, highlight, border, selected ui.Color
+	category, statusOK, statusError          ui.Color
+}
+
+// themeNames 是设置界面中切换主题的顺序
+var themeNames = []string{"default", "light", "mono"}
+
+var themes = map[string]theme{
+	"default": {"默认", ui.ColorGreen, ui.ColorWhite, ui.ColorYellow, ui.ColorBlue, ui.ColorCyan,
+		ui.ColorYellow, ui.ColorGreen, ui.ColorRed},
+	"light": {"浅色终端", ui.ColorBlue, ui.ColorBlack, ui.ColorMagenta, ui.ColorBlack, ui.ColorBlue,
+		ui.ColorMagenta, ui.ColorGreen, ui.ColorRed},
+	"mono": {"单色", ui.ColorWhite, ui.ColorWhite, ui.ColorWhite, ui.ColorWhite, ui.ColorWhite,
+		ui.ColorWhite, ui.ColorWhite, ui.ColorRed},
+}
+
+// bitrateOptions 是可选的偏好码率（kbps），0 表示最高码率
+var bitrateOptions = []int{0, 32, 64, 128, 256}
+
+// settingItem 是设置界面中的一项：value 返回显示的值，change 按方向修改设置
+type settingItem struct {
+	label  string
+	value  func(u *UI) string
+	change func(u *UI, delta int)
+}
+
+var settingItems = []settingItem{
+	{"启动时继续播放", func(u *UI) string {
+		if u.settings.AutoResume {
+			return "开"
+		}
+		return "关"
+	}, func(u *UI, delta int) {
+		u.settings.AutoResume = !u.settings.AutoResume
+	}},
+	{"音量", func(u *UI) string {
+		if !u.player.Mixing() {
+			return fmt.Sprintf("%d%%（未安装 ffmpeg，不可调节）", u.settings.Volume)
+		}
+		return fmt.Sprintf("%3d%%  %s", u.settings.Volume, strings.Repeat("█", u.settings.Volume/10))
+	}, func(u *UI, delta int) {
+		volume := u.settings.Volume + delta*10
+		if volume < 0 || volume > 100 {
+			return
+		}
+		u.settings.Volume = volume
+		u.player.SetVolume(volume)
+	}},
+	{"偏好码率", func(u *UI) string {
+		value := bitrateLabel(u.settings.PreferredBitrate)
+		if u.player.Metered() {
+			value += "（按流量计费模式下使用最低码率）"
+		}
+		return value
+	}, func(u *UI, delta int) {
+		u.settings.PreferredBitrate = bitrateOptions[cycleIndex(len(bitrateOptions), indexOfInt(bitrateOptions, u.settings.PreferredBitrate), delta)]
+		u.player.SetPreferredBitrate(u.settings.PreferredBitrate)
+	}},
+	{"音频输出", func(u *UI) string {
+		if u.settings.AudioBackend == "" {
+			return "自动"
+		}
+		return u.settings.AudioBackend
+	}, func(u *UI, delta int) {
+		backends := append([]string{""}, audio.Backends...)
+		u.settings.AudioBackend = backends[cycleIndex(len(backends), indexOfString(backends, u.settings.AudioBackend), delta)]
+		u.player.SetBackend(u.settings.AudioBackend)
+	}},
+	{"主题", func(u *UI) string {
+		return themes[u.settings.Theme].label + "（重启后生效）"
+	}, func(u *UI, delta int) {
+		u.settings.Theme = themeNames[cycleIndex(len(themeNames), indexOfString(themeNames, u.settings.Theme), delta)]
+	}},
+}
+
+// loadSettings 读取保存的设置，应用主题、折叠状态与播放器设置
+func (u *UI) loadSettings() {
+	settings, err := db.LoadSettings(u.db)
+	if err != nil {
+		logger.Error("读取设置失败: %v", err)
+	}
+	if _, ok := themes[settings.Theme]; !ok {
+		settings.Theme = "default"
+	}
+	u.settings = settings
+
+	applyTheme(themes[settings.Theme])
+	for name, collapsed := range settings.CollapsedCategories {
+		if _, ok := u.collapsedCats[name]; ok {
+			u.collapsedCats[name] = collapsed
+		}
+	}
+	u.player.SetVolume(settings.Volume)
+	u.player.SetPreferredBitrate(settings.PreferredBitrate)
+	if settings.AudioBackend != "" {
+		u.player.SetBackend(settings.AudioBackend)
+	}
+}
+
+// saveSettings 记下当前视图与分类折叠状态并保存全部设置
+func (u *UI) saveSettings() {
+	switch u.currentView {
+	case "main", "history", "favorites":
+		u.settings.LastView = u.currentView
+	}
+	u.mu.RLock()
+	collapsed := make(map[string]bool, len(u.collapsedCats))
+	for name, c := range u.collapsedCats {
+		collapsed[name] = c
+	}
+	u.mu.RUnlock()
+	u.settings.CollapsedCategories = collapsed
+
+	if err := db.SaveSettings(u.db, u.settings); err != nil {
+		logger.Error("保存设置失败: %v", err)
+	}
+}
+
+// saveLastStation 记下最近播放的电台，供下次启动时继续播放。无痕模式下不记录
+func (u *UI) saveLastStation(radio model.Radio) {
+	if u.incognito {
+		return
+	}
+	u.settings.LastStation = radio
+	if err := db.SaveLastStation(u.db, radio); err != nil {
+		logger.Error("保存设置失败: %v", err)
+	}
+}
+
+// restoreLastView 打开上次退出时所在的视图
+func (u *UI) restoreLastView() {
+	switch u.settings.LastView {
+	case "history":
+		u.currentView = "history"
+		u.showHistory()
+		u.setStatus(historyHelp, colorText)
+	case "favorites":
+		u.currentView = "favorites"
+		u.showFavorites()
+		u.setStatus(favoritesHelp, colorText)
+	}
+}
+
+// autoResume 开启启动时继续播放时，播放上次收听的电台
+func (u *UI) autoResume() {
+	last := u.settings.LastStation
+	if !u.settings.AutoResume || last.PlayURL == "" {
+		return
+	}
+	radio, _ := u.resolveStation(last.StationID(), last.Name, last.PlayURL)
+	u.playRadio(radio)
+}
+
+// enterSettings 打开设置界面
+func (u *UI) enterSettings() {
+	u.settingsSelected = 0
+	u.settingsPrevView = u.currentView
+	u.currentView = "settings"
+	u.setStatus(settingsHelp, colorText)
+	u.showSettings()
+}
+
+// exitSettings 关闭设置界面，回到进入前的视图
+func (u *UI) exitSettings() {
+	u.currentView = u.settingsPrevView
+	switch u.currentView {
+	case "history":
+		u.showHistory()
+		u.setStatus(historyHelp, colorText)
+	case "favorites":
+		u.showFavorites()
+		u.setStatus(favoritesHelp, colorText)
+	default:
+		u.currentView = "main"
+		u.updateRadioList(false)
+		u.setStatus(defaultStatus, colorText)
+	}
+}
+
+// handleSettingsKeys 处理设置界面中的按键，修改后立即保存
+func (u *UI) handleSettingsKeys(e ui.Event) {
+	delta := 0
+	switch e.ID {
+	case "<Escape>", "o":
+		u.exitSettings()
+		return
+	case "j", "<Down>":
+		if u.settingsSelected < len(settingItems)-1 {
+			u.settingsSelected++
+		}
+	case "k", "<Up>":
+		if u.settingsSelected > 0 {
+			u.settingsSelected--
+		}
+	case "l", "<Right>", "<Enter>", "<Space>":
+		delta = 1
+	case "h", "<Left>":
+		delta = -1
+	}
+	if delta != 0 {
+		item := settingItems[u.settingsSelected]
+		item.change(u, delta)
+		u.saveSettings()
+		u.setStatus(fmt.Sprintf("%s: %s", item.label, item.value(u)), colorStatusOK)
+	}
+	u.showSettings()
+}
+
+// showSettings 在列表区域绘制设置项
+func (u *UI) showSettings() {
+	items := []string{"[设置](fg:yellow)"}
+	for _, item := range settingItems {
+		items = append(items, fmt.Sprintf(" %s  %s", padLabel(item.label, 16), item.value(u)))
+	}
+	last := "无"
+	if u.settings.LastStation.Name != "" {
+		last = u.settings.LastStation.Name
+	}
+	items = append(items, "", " 上次收听: "+last)
+
+	u.radioList.Title = "设置"
+	u.setRows(items, nil)
+	u.radioList.SelectedRow = u.settingsSelected + 1
+	ui.Render(u.grid)
+}
+
+// applyTheme 设置界面颜色，需在创建控件前调用
+func applyTheme(t theme) {
+	colorTitle = t.title
+	colorText = t.text
+	colorHighlight = t.highlight
+	colorBorder = t.border
+	colorSelected = t.selected
+	colorCategory = t.category
+	colorStatusOK = t.statusOK
+	colorStatusError = t.statusError
+}
+
+func bitrateLabel(kbps int) string {
+	if kbps == 0 {
+		return "最高"
+	}
+	return fmt.Sprintf("%d kbps", kbps)
+}
+
+// cycleIndex 返回从 i 按 delta 循环移动后的下标
+func cycleIndex(n, i, delta int) int {
+	return ((i+delta)%n + n) % n
+}
+
+func indexOfInt(values []int, v int) int {
+	for i, x := range values {
+		if x == v {
+			return i
+		}
+	}
+	return 0
+}
+
+func indexOfString(values []string, v string) int {
+	for i, x := range values {
+		if x == v {
+			return i
+		}
+	}
+	return 0
+}
+
+// padLabel 按显示宽度（中文字符占两列）在标签后补空格
+func padLabel(label string, width int) string {
+	w := 0
+	for _, r := range label {
+		if r > 0x7f {
+			w += 2
+		} else {
+			w++
+		}
+	}
+	if w >= width {
+		return label
+	}
+	return label + strings.Repeat(" ", width-w)
+}
@@ -0,0 +1,131 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"FMgo/internal/logger"
+	"FMgo/internal/model"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/gizak/termui/v3/widgets"
+)
+
+// setupStatsPanel 创建网络与缓冲统计面板
+func (u *UI) setupStatsPanel() {
+	latency := widgets.NewSparkline()
+	latency.Title = "分片延迟 (ms)"
+	latency.LineColor = ui.ColorYellow
+	throughput := widgets.NewSparkline()
+	throughput.Title = "下载速率 (KB/s)"
+	throughput.LineColor = ui.ColorGreen
+	buffer := widgets.NewSparkline()
+	buffer.Title = "缓冲水位 (s)"
+	buffer.LineColor = ui.ColorCyan
+
+	u.statsSparklines = widgets.NewSparklineGroup(latency, throughput, buffer)
+	u.statsSparklines.Title = "网络"
+	u.statsSparklines.BorderStyle = ui.NewStyle(colorBorder)
+	u.statsSparklines.TitleStyle = ui.NewStyle(colorTitle, ui.ColorClear, ui.ModifierBold)
+
+	u.statsText = widgets.NewParagraph()
+	u.statsText.Title = "统计"
+	u.statsText.BorderStyle = ui.NewStyle(colorBorder)
+	u.statsText.TitleStyle = ui.NewStyle(colorTitle, ui.ColorClear, ui.ModifierBold)
+	u.statsText.TextStyle = ui.NewStyle(colorText)
+	u.statsText.PaddingLeft = 1
+}
+
+// toggleStatsPanel 显示/隐藏统计面板
+func (u *UI) toggleStatsPanel() {
+	u.showStats = !u.showStats
+	u.layout()
+	if u.showStats {
+		u.refreshStatsPanel()
+	}
+	ui.Render(u.grid)
+}
+
+// refreshStatsPanel 使用播放器的最新快照刷新统计面板
+func (u *UI) refreshStatsPanel() {
+	stats := u.player.Stats()
+
+	sparklines := u.statsSparklines.Sparklines
+	setSparklineData(sparklines[0], stats.LatencyHistory)
+	setSparklineData(sparklines[1], stats.ThroughputHistory)
+	setSparklineData(sparklines[2], stats.BufferHistory)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "已接收: %s (%d 个分片)\n", formatBytes(stats.BytesReceived), stats.Segments)
+	fmt.Fprintf(&b, "延迟: %v (平均 %v)\n", stats.LastLatency.Round(time.Millisecond), stats.AvgLatency.Round(time.Millisecond))
+	fmt.Fprintf(&b, "速率: %.1f KB/s (平均 %.1f KB/s)\n", stats.LastThroughput/1024, stats.AvgThroughput/1024)
+	fmt.Fprintf(&b, "列表刷新: %v 前\n", stats.PlaylistAge.Round(time.Second))
+	fmt.Fprintf(&b, "缓冲: %v\n", stats.BufferFill.Round(time.Second))
+	fmt.Fprintf(&b, "欠载: %d | 重连: %d | 错误: %d\n", stats.Underruns, stats.Reconnects, stats.Errors)
+
+	if flaky, err := u.db.GetFlakyStations(3); err == nil && len(flaky) > 0 {
+		b.WriteString("不稳定电台:\n")
+		for _, h := range flaky {
+			fmt.Fprintf(&b, " •%s (欠载 %d, 重连 %d)\n", h.RadioName, h.Underruns, h.Reconnects)
+		}
+	}
+	u.statsText.Text = b.String()
+}
+
+// saveStreamStats 将当前播放会话的统计摘要写入数据库
+func (u *UI) saveStreamStats() {
+	if u.currentRadio == nil || u.incognito {
+		return
+	}
+	stats := u.player.Stats()
+	if stats.Segments == 0 && stats.Errors == 0 {
+		return
+	}
+
+	endedAt := stats.EndedAt
+	if endedAt.IsZero() {
+		endedAt = time.Now()
+	}
+	summary := model.StreamStats{
+		StationID:     u.currentRadio.StationID(),
+		RadioName:     u.currentRadio.Name,
+		PlayURL:       u.currentRadio.PlayURL,
+		StartedAt:     stats.StartedAt,
+		EndedAt:       endedAt,
+		BytesReceived: stats.BytesReceived,
+		Segments:      stats.Segments,
+		AvgLatencyMs:  stats.AvgLatency.Milliseconds(),
+		AvgThroughput: stats.AvgThroughput,
+		Underruns:     stats.Underruns,
+		Reconnects:    stats.Reconnects,
+		Errors:        stats.Errors,
+	}
+	if err := u.db.AddStreamStats(summary); err != nil {
+		logger.Error("保存网络统计失败: %v", err)
+	}
+}
+
+func setSparklineData(sl *widgets.Sparkline, data []float64) {
+	if len(data) == 0 {
+		data = []float64{0}
+	}
+	sl.Data = data
+	sl.MaxVal = 0
+	if max, _ := ui.GetMaxFloat64FromSlice(data); max == 0 {
+		sl.MaxVal = 1
+	}
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.2f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.2f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
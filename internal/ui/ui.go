@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
+	"FMgo/internal/audio"
+	"FMgo/internal/catalog"
 	"FMgo/internal/db"
 	"FMgo/internal/model"
 	"FMgo/internal/player"
@@ -14,7 +17,8 @@ import (
 	"github.com/gizak/termui/v3/widgets"
 )
 
-const (
+// 界面颜色，由 applyTheme 按主题设置
+var (
 	colorTitle       = ui.ColorGreen
 	colorText        = ui.ColorWhite
 	colorHighlight   = ui.ColorYellow
@@ -23,45 +27,132 @@ const (
 	colorCategory    = ui.ColorYellow
 	colorStatusOK    = ui.ColorGreen
 	colorStatusError = ui.ColorRed
-
-	defaultStatus = "按 '/' 搜索 | 'h' 历史 | 'f' 收藏 | 'a' 收藏/取消 | 'q' 退出 | 's' 停止 | '?' 帮助 | ↑↓ 选择 | Enter 播放"
 )
 
+// colorNames 是各颜色在 termui 样式标记中的名称
+var colorNames = map[ui.Color]string{
+	ui.ColorBlack:   "black",
+	ui.ColorRed:     "red",
+	ui.ColorGreen:   "green",
+	ui.ColorYellow:  "yellow",
+	ui.ColorBlue:    "blue",
+	ui.ColorMagenta: "magenta",
+	ui.ColorCyan:    "cyan",
+	ui.ColorWhite:   "white",
+}
+
+// categoryRow 返回按主题分类颜色显示的分类行
+func categoryRow(text string) string {
+	return fmt.Sprintf("[%s](fg:%s)", text, colorNames[colorCategory])
+}
+
+const defaultStatus = "按 '/' 搜索 | 'h' 历史 | 'f' 收藏 | 'a' 收藏/取消 | 'q' 退出 | 's' 停止 | 空格 暂停 | 'n' 网络 | 'e' 均衡器 | 'd' 发现 | 'i' 导入 | 'E' 编辑 | 'S' 收听统计 | 'o' 设置 | 1-9 预设 | '?' 帮助 | ↑↓ 选择 | Enter 播放"
+
 type UI struct {
+	catalog       *catalog.Catalog
 	categories    []model.Category
 	player        *player.Player
-	db            *db.Database
+	db            db.Store
 	grid          *ui.Grid
 	radioList     *widgets.List
 	statusBar     *widgets.Paragraph
 	searchInput   *widgets.Paragraph
 	isSearching   bool
 	searchText    string
-	currentView   string // "main", "history", "favorites"
+	currentView   string // "main", "history", "favorites", "equalizer", "discover", "editor", "listening", "settings"
 	mu            sync.RWMutex
 	collapsedCats map[string]bool
+	rowRadios     map[int]model.Radio // 主列表、搜索、历史与收藏视图中各行对应的电台
+
+	currentRadio    *model.Radio
+	playingURL      string                  // currentRadio 的播放地址
+	switchedFrom    *listenStation          // 最近一次切台前的电台，新电台缓冲失败时恢复
+	listening       map[string]*listenEntry // 按播放地址记录正在收听的电台
+	listeningMu     sync.Mutex              // 播放中途出错时状态回调在下载协程中访问 listening 与 switchedFrom
+	showStats       bool
+	showDetails     bool
+	detailsText     *widgets.Paragraph
+	statsSparklines *widgets.SparklineGroup
+	statsText       *widgets.Paragraph
+
+	sessionBytes     int64
+	monthBytes       int64
+	usageStreamStart time.Time
+	usageCounted     int64
+	prevUsageStart   time.Time // 上一个下载会话，切台失败时恢复其计数
+	prevUsageCounted int64
+
+	eqRadio    model.Radio // 打开均衡器时播放的电台，保存设置到该电台
+	eqBands    []audio.Band
+	eqSaved    []audio.Band
+	eqPreset   string
+	eqSelected int
+	eqPrevView string
+
+	prompting    bool
+	promptLabel  string
+	promptText   string
+	promptSubmit func(text string)
+	updates      chan func()
+
+	discoverTitle    string
+	discoverStations []model.DirectoryStation
+	discoverResults  []model.Radio
+
+	editorItems []editorItem
+	favorites   []model.Favorite       // 收藏视图中显示的收藏
+	history     []model.StationHistory // 历史视图中显示的记录
+	incognito   bool                   // 无痕模式：不记录收听历史、播放统计与流量
+
+	listeningHours    *widgets.BarChart
+	listeningWeekdays *widgets.BarChart
+	listeningTop      *widgets.Paragraph
+	listeningSummary  *widgets.Paragraph
+	listeningPeriod   int // listeningPeriods 中的下标
+	listeningPrevView string
+
+	settings         model.Settings
+	settingsSelected int
+	settingsPrevView string
 }
 
-func New(categories []model.Category, player *player.Player, db *db.Database) (*UI, error) {
+func New(catalog *catalog.Catalog, player *player.Player, db db.Store) (*UI, error) {
+	categories, err := catalog.Categories()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load catalog: %v", err)
+	}
+
 	if err := ui.Init(); err != nil {
 		return nil, fmt.Errorf("failed to initialize termui: %v", err)
 	}
 
 	u := &UI{
+		catalog:       catalog,
 		categories:    categories,
 		player:        player,
 		db:            db,
 		collapsedCats: make(map[string]bool),
 		currentView:   "main",
+		updates:       make(chan func(), 16),
+		listening:     make(map[string]*listenEntry),
+	}
+
+	// 上次运行未正常结束的收听按退出处理
+	if err := db.CloseOpenSessions(); err != nil {
+		logger.Error("结束未完成的收听失败: %v", err)
 	}
+	player.SetStateHook(u.onPlayerState)
 
-	// 初始化所有分类为折叠状态
+	// 初始化所有分类为折叠状态，再恢复上次保存的折叠状态
 	for _, cat := range categories {
 		u.collapsedCats[cat.Name] = true
 	}
+	u.loadSettings()
 
 	u.setupWidgets()
+	u.loadDataUsage()
 	u.updateRadioList(true)
+	u.restoreLastView()
 	return u, nil
 }
 
@@ -93,17 +184,61 @@ func (u *UI) setupWidgets() {
 	u.statusBar.PaddingLeft = 2
 	u.statusBar.PaddingRight = 2
 
+	u.setupStatsPanel()
+	u.setupDetailsPanel()
+	u.setupListeningView()
+
 	u.grid = ui.NewGrid()
 	termWidth, termHeight := ui.TerminalDimensions()
 	u.grid.SetRect(0, 0, termWidth, termHeight)
+	u.layout()
+}
 
+// layout 根据当前显示的面板重新排列网格
+func (u *UI) layout() {
+	u.grid.Items = nil
+	if u.currentView == "listening" {
+		u.grid.Set(
+			ui.NewRow(0.35, u.listeningHours),
+			ui.NewRow(0.5,
+				ui.NewCol(0.3, u.listeningWeekdays),
+				ui.NewCol(0.35, u.listeningTop),
+				ui.NewCol(0.35, u.listeningSummary),
+			),
+			ui.NewRow(0.15, u.statusBar),
+		)
+		return
+	}
+	if u.showStats {
+		u.grid.Set(
+			ui.NewRow(0.15, u.searchInput),
+			u.listRow(0.4),
+			ui.NewRow(0.3,
+				ui.NewCol(0.6, u.statsSparklines),
+				ui.NewCol(0.4, u.statsText),
+			),
+			ui.NewRow(0.15, u.statusBar),
+		)
+		return
+	}
 	u.grid.Set(
 		ui.NewRow(0.2, u.searchInput),
-		ui.NewRow(0.6, u.radioList),
+		u.listRow(0.6),
 		ui.NewRow(0.2, u.statusBar),
 	)
 }
 
+// listRow 返回电台列表所在的行，显示详情面板时列表与详情并排
+func (u *UI) listRow(ratio float64) ui.GridItem {
+	if u.showDetails {
+		return ui.NewRow(ratio,
+			ui.NewCol(0.55, u.radioList),
+			ui.NewCol(0.45, u.detailsText),
+		)
+	}
+	return ui.NewRow(ratio, u.radioList)
+}
+
 func (u *UI) updateRadioList(isFlushRow bool) {
 	u.mu.RLock()
 	defer u.mu.RUnlock()
@@ -118,25 +253,31 @@ func (u *UI) updateRadioList(isFlushRow bool) {
 		return
 	}
 
+	if u.currentView == "equalizer" {
+		u.showEqualizer()
+		return
+	}
+
 	var items []string
+	radios := make(map[int]model.Radio)
 	for _, cat := range u.categories {
 		collapsed := u.collapsedCats[cat.Name]
 		indicator := "▶"
 		if !collapsed {
 			indicator = "▼"
 		}
-		catName := fmt.Sprintf("[%s %s](fg:cyan)", indicator, cat.Name)
-		items = append(items, catName)
+		items = append(items, categoryRow(indicator+" "+cat.Name))
 
 		if !collapsed {
 			for _, radio := range cat.RadioList {
-				items = append(items, fmt.Sprintf(" •%s", radio.Name))
+				radios[len(items)] = radio
+				items = append(items, radioRow(radio))
 			}
 		}
 	}
 
 	u.radioList.Title = "电台列表"
-	u.radioList.Rows = items
+	u.setRows(items, radios)
 	if isFlushRow {
 		u.radioList.SelectedRow = 0
 	}
@@ -144,7 +285,7 @@ func (u *UI) updateRadioList(isFlushRow bool) {
 }
 
 func (u *UI) showHistory() {
-	history, err := u.db.GetHistory(20)
+	history, err := u.db.GetStationHistory(50)
 	if err != nil {
 		u.setStatus(fmt.Sprintf("加载历史记录失败: %v", err), colorStatusError)
 		return
@@ -152,17 +293,21 @@ func (u *UI) showHistory() {
 
 	var historyItems []string
 	historyItems = append(historyItems, "[播放历史](fg:yellow)")
+	radios := make(map[int]model.Radio)
 
 	for _, h := range history {
-		historyItems = append(historyItems, fmt.Sprintf(" •%s", h.RadioName))
+		radio, found := u.resolveStation(h.StationID, h.RadioName, h.PlayURL)
+		radios[len(historyItems)] = radio
+		historyItems = append(historyItems, historyRow(h, radio, found))
 	}
 
 	if len(history) == 0 {
 		historyItems = append(historyItems, "  暂无播放记录")
 	}
 
+	u.history = history
 	u.radioList.Title = "播放历史"
-	u.radioList.Rows = historyItems
+	u.setRows(historyItems, radios)
 	u.radioList.SelectedRow = 1 // 从第一个历史记录开始
 	ui.Render(u.grid)
 }
@@ -174,11 +319,23 @@ func (u *UI) showFavorites() {
 		return
 	}
 
+	u.favorites = favorites
+
 	var items []string
 	items = append(items, "[收藏列表](fg:yellow)")
+	radios := make(map[int]model.Radio)
 
-	for _, radio := range favorites {
-		items = append(items, fmt.Sprintf(" •%s", radio.Name))
+	folder := ""
+	for i, favorite := range favorites {
+		if favorite.Folder != folder {
+			folder = favorite.Folder
+			items = append(items, categoryRow(folder))
+		}
+		radio, found := u.resolveStation(favorite.ID, favorite.Name, favorite.PlayURL)
+		// 取消收藏时使用收藏记录中的 ID
+		radio.ID = favorite.ID
+		radios[len(items)] = radio
+		items = append(items, favoriteRow(i+1, favorite, radio, found))
 	}
 
 	if len(items) == 1 {
@@ -186,17 +343,20 @@ func (u *UI) showFavorites() {
 	}
 
 	u.radioList.Title = "收藏列表"
-	u.radioList.Rows = items
+	u.setRows(items, radios)
 	u.radioList.SelectedRow = 1 // 从第一个收藏开始
 	ui.Render(u.grid)
 }
 
 func (u *UI) toggleCategory(name string) {
-	// 去除可能的前缀和后缀
+	// 去除样式标记与折叠指示符
 	name = strings.TrimSpace(name)
-	name = strings.TrimRight(name, "](fg:cyan)")
-	name = strings.TrimLeft(name, "[▶")
-	name = strings.TrimLeft(name, "[▼")
+	if i := strings.LastIndex(name, "]("); i >= 0 {
+		name = name[:i]
+	}
+	name = strings.TrimPrefix(name, "[")
+	name = strings.TrimPrefix(name, "▶")
+	name = strings.TrimPrefix(name, "▼")
 	name = strings.TrimSpace(name)
 	if _, exists := u.collapsedCats[name]; exists {
 		u.collapsedCats[name] = !u.collapsedCats[name]
@@ -204,32 +364,11 @@ func (u *UI) toggleCategory(name string) {
 	}
 }
 
-func (u *UI) toggleFavorite(name string) {
-	name = strings.TrimPrefix(name, " •")
-
-	// 查找电台信息
-	var radio model.Radio
-	found := false
-	for _, cat := range u.categories {
-		for _, r := range cat.RadioList {
-			if r.Name == name {
-				radio = r
-				found = true
-				break
-			}
-		}
-		if found {
-			break
-		}
-	}
-
-	if !found {
-		u.setStatus(fmt.Sprintf("未找到电台: %s", name), colorStatusError)
-		return
-	}
+func (u *UI) toggleFavorite(radio model.Radio) {
+	name := radio.Name
 
 	// 检查是否已收藏
-	isFav, err := u.db.IsFavorite(name)
+	isFav, err := u.db.IsFavorite(radio.StationID())
 	if err != nil {
 		u.setStatus(fmt.Sprintf("检查收藏状态失败: %v", err), colorStatusError)
 		return
@@ -237,7 +376,7 @@ func (u *UI) toggleFavorite(name string) {
 
 	if isFav {
 		// 取消收藏
-		if err := u.db.RemoveFavorite(name); err != nil {
+		if err := u.db.RemoveFavorite(radio.StationID()); err != nil {
 			u.setStatus(fmt.Sprintf("取消收藏失败: %v", err), colorStatusError)
 			return
 		}
@@ -263,28 +402,48 @@ func (u *UI) setStatus(status string, color ui.Color) {
 	ui.Render(u.grid)
 }
 
-func (u *UI) findAndPlayRadio(name string) bool {
-	name = strings.TrimPrefix(name, " •")
-	logger.Info("查找电台: %s", name)
+// playSelected 播放列表中选中行对应的电台
+func (u *UI) playSelected() bool {
+	radio, ok := u.rowRadio(u.radioList.SelectedRow)
+	if !ok {
+		return false
+	}
+	return u.playRadio(radio)
+}
 
-	for _, cat := range u.categories {
-		for _, radio := range cat.RadioList {
-			if radio.Name == name {
-				logger.Info("播放电台: %s, URL: %s", radio.Name, radio.PlayURL)
-				if err := u.player.Play(radio.PlayURL); err != nil {
-					u.setStatus(fmt.Sprintf("播放错误: %v", err), colorStatusError)
-					return false
-				}
-				if err := u.db.AddHistory(radio); err != nil {
-					logger.Error("记录历史失败: %v", err)
-				}
-				u.setStatus(fmt.Sprintf("正在播放: %s", radio.Name), colorStatusOK)
-				return true
-			}
+// playRadio 解析电台的播放地址并开始播放
+func (u *UI) playRadio(radio model.Radio) bool {
+	playURL, err := u.catalog.ResolveStreamURL(radio)
+	if err != nil {
+		u.setStatus(fmt.Sprintf("解析播放地址失败: %v", err), colorStatusError)
+		return false
+	}
+
+	logger.Info("播放电台: %s, URL: %s", radio.Name, playURL)
+	if u.player.CurrentURL() != playURL {
+		u.trackDataUsage()
+		u.saveStreamStats()
+	}
+	u.expectSession(playURL, radio)
+	if err := u.player.Play(playURL); err != nil {
+		if !u.player.IsPlaying() {
+			u.currentRadio = nil
 		}
+		u.setStatus(fmt.Sprintf("播放错误: %v", err), colorStatusError)
+		return false
 	}
-	u.setStatus(fmt.Sprintf("未找到电台: %s", name), colorStatusError)
-	return false
+	u.listeningMu.Lock()
+	u.switchedFrom = nil
+	if u.currentRadio != nil && u.playingURL != playURL {
+		u.switchedFrom = &listenStation{url: u.playingURL, radio: *u.currentRadio, record: !u.incognito}
+	}
+	u.listeningMu.Unlock()
+	current := radio
+	u.currentRadio, u.playingURL = &current, playURL
+	u.applyStationEQ(radio)
+	u.saveLastStation(radio)
+	u.setStatus(fmt.Sprintf("正在播放: %s", radio.Name), colorStatusOK)
+	return true
 }
 
 func (u *UI) enterSearchMode() {
@@ -341,7 +500,7 @@ func (u *UI) handleSearchMode(e ui.Event) {
 
 func (u *UI) updateSearchResults() {
 	var items []string
-	searchText := strings.ToLower(u.searchText)
+	searchText := strings.TrimSpace(u.searchText)
 
 	if searchText == "" {
 		u.updateRadioList(true)
@@ -349,10 +508,12 @@ func (u *UI) updateSearchResults() {
 	}
 
 	items = append(items, "[搜索结果](fg:yellow)")
+	radios := make(map[int]model.Radio)
 	for _, cat := range u.categories {
 		for _, radio := range cat.RadioList {
-			if strings.Contains(strings.ToLower(radio.Name), searchText) {
-				items = append(items, fmt.Sprintf(" •%s", radio.Name))
+			if catalog.MatchRadio(radio, searchText) {
+				radios[len(items)] = radio
+				items = append(items, radioRow(radio))
 			}
 		}
 	}
@@ -362,15 +523,72 @@ func (u *UI) updateSearchResults() {
 	}
 
 	u.radioList.Title = "搜索结果"
-	u.radioList.Rows = items
+	u.setRows(items, radios)
 	u.radioList.SelectedRow = 1 // 从第一个搜索结果开始
 	ui.Render(u.grid)
 }
 
 func (u *UI) Run() {
+	u.autoResume()
 	uiEvents := ui.PollEvents()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
 	for {
-		e := <-uiEvents
+		var e ui.Event
+		select {
+		case e = <-uiEvents:
+		case fn := <-u.updates:
+			fn()
+			u.refreshDetailsPanel()
+			ui.Render(u.grid)
+			continue
+		case <-ticker.C:
+			u.trackDataUsage()
+			u.checkPlaybackFailed()
+			if u.player.IsPaused() {
+				u.updatePauseStatus()
+			}
+			if u.showStats {
+				u.refreshStatsPanel()
+			}
+			ui.Render(u.grid)
+			continue
+		}
+		if u.prompting && e.ID != "<C-c>" && e.ID != "<Resize>" {
+			u.handlePromptKeys(e)
+			continue
+		}
+		if u.currentView == "equalizer" && e.ID != "q" && e.ID != "<C-c>" && e.ID != "<Resize>" {
+			u.handleEqualizerKeys(e)
+			continue
+		}
+		if u.currentView == "settings" && e.ID != "q" && e.ID != "<C-c>" && e.ID != "<Resize>" {
+			u.handleSettingsKeys(e)
+			continue
+		}
+		if u.currentView == "editor" && u.handleEditorKeys(e) {
+			u.refreshDetailsPanel()
+			ui.Render(u.grid)
+			continue
+		}
+		if u.currentView == "favorites" && !u.isSearching && u.handleFavoritesKeys(e) {
+			u.refreshDetailsPanel()
+			ui.Render(u.grid)
+			continue
+		}
+		if u.currentView == "history" && !u.isSearching && u.handleHistoryKeys(e) {
+			u.refreshDetailsPanel()
+			ui.Render(u.grid)
+			continue
+		}
+		if u.currentView == "listening" && u.handleListeningKeys(e) {
+			continue
+		}
+		if u.currentView == "discover" && u.handleDiscoverKeys(e) {
+			u.refreshDetailsPanel()
+			ui.Render(u.grid)
+			continue
+		}
 		switch e.ID {
 		case "q", "<C-c>":
 			return
@@ -385,9 +603,7 @@ func (u *UI) Run() {
 				}
 				selected := u.radioList.Rows[u.radioList.SelectedRow]
 
-				if strings.HasPrefix(selected, " •") {
-					// 暂时注释掉播放逻辑
-					u.findAndPlayRadio(selected)
+				if u.playSelected() {
 					logger.Info("搜索结果中选中电台: %s", selected)
 					u.exitSearchMode()
 				}
@@ -395,8 +611,7 @@ func (u *UI) Run() {
 			}
 
 			if u.currentView == "history" {
-				// 暂时注释掉播放逻辑
-				u.findAndPlayRadio(u.radioList.Rows[u.radioList.SelectedRow])
+				u.playSelected()
 				logger.Info("选中历史记录: %s", u.radioList.Rows[u.radioList.SelectedRow])
 				u.currentView = "main"
 				u.updateRadioList(true)
@@ -404,17 +619,15 @@ func (u *UI) Run() {
 			}
 
 			if u.currentView == "favorites" {
-				// 暂时注释掉播放逻辑
-				u.findAndPlayRadio(u.radioList.Rows[u.radioList.SelectedRow])
+				u.playSelected()
 				logger.Info("选中收藏电台: %s", u.radioList.Rows[u.radioList.SelectedRow])
 				u.currentView = "main"
 				u.updateRadioList(true)
 				continue
 			}
 
-			if strings.HasPrefix(u.radioList.Rows[u.radioList.SelectedRow], " •") {
-				// 暂时注释掉播放逻辑
-				u.findAndPlayRadio(u.radioList.Rows[u.radioList.SelectedRow])
+			if _, ok := u.rowRadio(u.radioList.SelectedRow); ok {
+				u.playSelected()
 				logger.Info("选中电台: %s", u.radioList.Rows[u.radioList.SelectedRow])
 			} else {
 				// 折叠/展开分类
@@ -424,24 +637,94 @@ func (u *UI) Run() {
 			if !u.isSearching {
 				u.currentView = "history"
 				u.showHistory()
+				u.setStatus(historyHelp, colorText)
 			}
 		case "f":
 			if !u.isSearching {
 				u.currentView = "favorites"
 				u.showFavorites()
+				u.setStatus(favoritesHelp, colorText)
 			}
 		case "/":
 			u.enterSearchMode()
 		case "s":
 			if u.player.IsPlaying() {
 				u.player.Stop()
+				u.trackDataUsage()
+				u.saveStreamStats()
+				u.currentRadio = nil
 				u.setStatus("播放已停止", colorText)
 			}
+		case "<Space>":
+			if u.isSearching {
+				u.handleSearchMode(e)
+				continue
+			}
+			u.togglePause()
+		case "L":
+			if u.isSearching {
+				u.handleSearchMode(e)
+				continue
+			}
+			u.resumeLive()
+		case "e":
+			if u.isSearching {
+				u.handleSearchMode(e)
+				continue
+			}
+			u.enterEqualizer()
+		case "d":
+			if u.isSearching {
+				u.handleSearchMode(e)
+				continue
+			}
+			u.enterDiscover()
+		case "E":
+			if u.isSearching {
+				u.handleSearchMode(e)
+				continue
+			}
+			u.enterEditor()
+		case "S":
+			if u.isSearching {
+				u.handleSearchMode(e)
+				continue
+			}
+			u.enterListeningView()
+		case "o":
+			if u.isSearching {
+				u.handleSearchMode(e)
+				continue
+			}
+			u.enterSettings()
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			if u.isSearching {
+				u.handleSearchMode(e)
+				continue
+			}
+			u.playPreset(int(e.ID[0] - '0'))
+		case "i":
+			if u.isSearching {
+				u.handleSearchMode(e)
+				continue
+			}
+			u.startImport()
+		case "n":
+			if u.isSearching {
+				u.handleSearchMode(e)
+				continue
+			}
+			u.toggleStatsPanel()
+		case "v":
+			if u.isSearching {
+				u.handleSearchMode(e)
+				continue
+			}
+			u.toggleDetailsPanel()
 		case "a":
 			if !u.isSearching && len(u.radioList.Rows) > 0 {
-				selected := u.radioList.Rows[u.radioList.SelectedRow]
-				if strings.HasPrefix(selected, " •") {
-					u.toggleFavorite(selected)
+				if radio, ok := u.rowRadio(u.radioList.SelectedRow); ok {
+					u.toggleFavorite(radio)
 				}
 			}
 		case "<Resize>":
@@ -472,16 +755,20 @@ func (u *UI) Run() {
 					case "main":
 						u.currentView = "history"
 						u.showHistory()
+						u.setStatus(historyHelp, colorText)
 					case "history":
 						u.currentView = "favorites"
 						u.showFavorites()
-					case "favorites":
+						u.setStatus(favoritesHelp, colorText)
+					case "favorites", "discover":
 						u.currentView = "main"
 						u.updateRadioList(true)
+						u.setStatus(defaultStatus, colorText)
 					}
 				}
 			}
 		}
+		u.refreshDetailsPanel()
 		ui.Render(u.grid)
 	}
 }
@@ -489,6 +776,9 @@ func (u *UI) Run() {
 func (u *UI) Close() {
 	if u.player != nil {
 		u.player.Cleanup()
+		u.trackDataUsage()
+		u.saveStreamStats()
 	}
+	u.saveSettings()
 	ui.Close()
 }
@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"FMgo/internal/logger"
+)
+
+// trackDataUsage 将当前播放会话新增的下载字节计入本次与本月流量，并按电台记录
+func (u *UI) trackDataUsage() {
+	stats := u.player.Stats()
+	if !stats.StartedAt.Equal(u.usageStreamStart) {
+		// 切台失败回到原电台时接着原来的计数，避免重复计入
+		var counted int64
+		if stats.StartedAt.Equal(u.prevUsageStart) {
+			counted = u.prevUsageCounted
+		}
+		u.prevUsageStart, u.prevUsageCounted = u.usageStreamStart, u.usageCounted
+		u.usageStreamStart, u.usageCounted = stats.StartedAt, counted
+	}
+
+	delta := stats.BytesReceived - u.usageCounted
+	if delta <= 0 {
+		return
+	}
+	u.usageCounted = stats.BytesReceived
+	u.sessionBytes += delta
+	u.monthBytes += delta
+	u.recordDataUsage(time.Now(), delta)
+	u.updateUsageTitle()
+}
+
+// recordDataUsage 将新增流量按当前电台写入数据库，无痕模式下不记录
+func (u *UI) recordDataUsage(at time.Time, delta int64) {
+	if u.incognito {
+		return
+	}
+	var stationID string
+	if u.currentRadio != nil {
+		stationID = u.currentRadio.StationID()
+	}
+	if err := u.db.AddDataUsage(at, stationID, delta); err != nil {
+		logger.Error("记录流量失败: %v", err)
+	}
+}
+
+// loadDataUsage 从数据库读取本月已用流量
+func (u *UI) loadDataUsage() {
+	bytes, err := u.db.GetMonthlyDataUsage(time.Now())
+	if err != nil {
+		logger.Error("读取流量统计失败: %v", err)
+		return
+	}
+	u.monthBytes = bytes
+	u.updateUsageTitle()
+}
+
+// updateUsageTitle 在状态栏标题中显示流量使用情况
+func (u *UI) updateUsageTitle() {
+	title := fmt.Sprintf("状态 | 本次 %s · 本月 %s", formatBytes(u.sessionBytes), formatBytes(u.monthBytes))
+	if u.player.Metered() {
+		title += " [按流量计费]"
+	}
+	if u.incognito {
+		title += " [无痕]"
+	}
+	u.statusBar.Title = title
+}
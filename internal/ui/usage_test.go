@@ -0,0 +1,28 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"FMgo/internal/db"
+	"FMgo/internal/model"
+)
+
+func TestRecordDataUsageIncognito(t *testing.T) {
+	store := db.NewMemory()
+	radio := model.Radio{Name: "a", PlayURL: "http://example.com/a"}
+	u := &UI{db: store, currentRadio: &radio}
+	now := time.Now()
+
+	u.incognito = true
+	u.recordDataUsage(now, 100)
+	if bytes, err := store.GetMonthlyDataUsage(now); err != nil || bytes != 0 {
+		t.Fatalf("incognito usage = %d, %v", bytes, err)
+	}
+
+	u.incognito = false
+	u.recordDataUsage(now, 50)
+	if bytes, err := store.GetMonthlyDataUsage(now); err != nil || bytes != 50 {
+		t.Fatalf("usage = %d, %v", bytes, err)
+	}
+}
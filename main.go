@@ -1,17 +1,17 @@
 package main
 
 import (
+	"FMgo/internal/catalog"
 	"FMgo/internal/config"
 	"FMgo/internal/db"
 	"FMgo/internal/logger"
-	"FMgo/internal/model"
 	"FMgo/internal/player"
 	"FMgo/internal/ui"
 	_ "embed"
-	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"time"
 )
 
 const Version = "0.1.0"
@@ -20,8 +20,26 @@ const Version = "0.1.0"
 var defaultRadioConfig []byte
 
 func main() {
-	configFile := flag.String("config", "", "外部电台配置文件路径(可选)")
+	if runCommand() {
+		return
+	}
+
+	catalogFlags := addCatalogFlags(flag.CommandLine)
+	remoteFlags := addRemoteFlags(flag.CommandLine)
+	radioBrowserURL := flag.String("radio-browser", catalog.DefaultRadioBrowserURL, "Radio Browser API 地址，为空则不启用发现功能")
+	xmlyInterval := flag.Duration("xmly-sync-interval", 0, "定期同步喜马拉雅电台目录的间隔(如 24h)，0 表示不同步")
+	xmlyURL := flag.String("xmly-url", catalog.DefaultXimalayaURL, "喜马拉雅直播电台接口地址")
 	version := flag.Bool("version", false, "显示版本信息")
+	bandwidthLimit := flag.Int("bwlimit", 0, "下载带宽上限(KB/s)，0 表示不限制")
+	audioBackend := flag.String("audio-backend", "", "音频输出后端: afplay/ffplay/aplay/play(可选，默认自动选择)")
+	crossfade := flag.Duration("crossfade", player.DefaultCrossfade, "切换电台时的交叉淡入时长，0 表示直接切换")
+	pauseBuffer := flag.Duration("pause-buffer", player.DefaultPauseBuffer, "暂停期间最多缓冲的直播时长，超出后继续播放将回到直播")
+	metered := flag.Bool("metered", false, "按流量计费模式：优先最低码率，不预取缓冲")
+	historyMaxAge := flag.Duration("history-max-age", 0, "收听历史的保留时长(如 2160h)，0 表示不限制")
+	historyMaxRows := flag.Int("history-max-rows", 0, "最多保留的收听记录条数，0 表示不限制")
+	incognito := flag.Bool("incognito", false, "无痕模式：本次运行不记录收听历史、播放统计与流量")
+	vacuumInterval := flag.Duration("vacuum-interval", 7*24*time.Hour, "定期清理过期收听记录并整理数据库文件的间隔，0 表示不整理")
+	syncDir := flag.String("sync-dir", "", "同步目录，启动与退出时将收藏与收听历史与其中的文件合并(可选)")
 	flag.Parse()
 
 	if *version {
@@ -29,30 +47,14 @@ func main() {
 		os.Exit(0)
 	}
 
-	var categories []model.Category
-	var err error
-
-	if *configFile != "" {
-		// 使用外部配置文件
-		data, err := os.ReadFile(*configFile)
-		if err != nil {
-			logger.Error("读取配置文件失败: %v", err)
-			os.Exit(1)
-		}
-		if err := json.Unmarshal(data, &categories); err != nil {
-			logger.Error("解析配置文件失败: %v", err)
-			os.Exit(1)
-		}
-	} else {
-		// 使用内置配置
-		if err := json.Unmarshal(defaultRadioConfig, &categories); err != nil {
-			logger.Error("解析内置配置失败: %v", err)
-			os.Exit(1)
-		}
+	if err := config.Init(); err != nil {
+		fmt.Fprintf(os.Stderr, "初始化目录失败: %v\n", err)
+		os.Exit(1)
+	}
+	if err := logger.Init(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
 	}
-
-	config.Init()
-	logger.Init()
 
 	// Initialize database
 	db, err := db.New()
@@ -62,21 +64,140 @@ func main() {
 	}
 	defer db.Close()
 
+	if *syncDir != "" {
+		mergeUserData(db, *syncDir)
+		// 在界面关闭、收听会话全部结束后再合并一次
+		defer mergeUserData(db, *syncDir)
+	}
+
+	pruneHistory := func() (int64, error) {
+		pruned, err := db.PruneHistory(time.Now(), *historyMaxAge, *historyMaxRows)
+		if err != nil {
+			return 0, err
+		}
+		if pruned > 0 {
+			logger.Info("按保留策略删除了 %d 条收听记录", pruned)
+		}
+		return pruned, nil
+	}
+	if _, err := pruneHistory(); err != nil {
+		logger.Error("清理收听历史失败: %v", err)
+	}
+
+	// 组装电台目录：内置列表、catalog.d 与外部配置文件叠加，本地目录与远程目录合并显示
+	sources, err := catalogFlags.sources(remoteFlags, db)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	providers := sources.providers
+	if *radioBrowserURL != "" {
+		providers = append(providers, catalog.NewRadioBrowser(*radioBrowserURL, db))
+	}
+	stations := catalog.New(providers...)
+
 	// Initialize player
 	player, err := player.NewPlayer()
 	if err != nil {
 		fmt.Printf("Error initializing player: %v\n", err)
 		os.Exit(1)
 	}
+	player.SetBandwidthLimit(*bandwidthLimit)
+	player.SetMetered(*metered)
+	player.SetCrossfade(*crossfade)
+	player.SetPauseBuffer(*pauseBuffer)
 
 	// Initialize UI
-	ui, err := ui.New(categories, player, db)
+	ui, err := ui.New(stations, player, db)
 	if err != nil {
 		fmt.Printf("Error initializing UI: %v\n", err)
 		os.Exit(1)
 	}
 	defer ui.Close()
+	ui.SetIncognito(*incognito)
+	// 命令行指定的音频后端优先于设置界面中保存的后端
+	if *audioBackend != "" {
+		player.SetBackend(*audioBackend)
+	}
+
+	if *xmlyInterval > 0 {
+		var delay time.Duration
+		if last, err := db.LastSyncedAt(catalog.XimalayaSource); err == nil {
+			delay = *xmlyInterval - time.Since(last)
+		}
+		if delay < 0 {
+			delay = 0
+		}
+		client := catalog.NewXimalaya(*xmlyURL)
+		ui.SchedulePeriodic("同步喜马拉雅电台", delay, *xmlyInterval, func() (string, error) {
+			report, err := catalog.SyncXimalaya(client, db)
+			if err != nil {
+				return "", err
+			}
+			return catalog.FormatSyncReport(report), nil
+		})
+	}
+
+	if *vacuumInterval > 0 {
+		var delay time.Duration
+		if last, err := db.LastVacuumAt(); err == nil {
+			delay = *vacuumInterval - time.Since(last)
+		}
+		if delay < 0 {
+			delay = 0
+		}
+		// 长时间运行时按保留策略定期删除过期的收听记录，再整理数据库文件
+		ui.SchedulePeriodic("整理数据库", delay, *vacuumInterval, func() (string, error) {
+			pruned, err := pruneHistory()
+			if err != nil {
+				return "", fmt.Errorf("清理收听历史失败: %v", err)
+			}
+			if err := db.Vacuum(); err != nil {
+				return "", err
+			}
+			if pruned > 0 {
+				return fmt.Sprintf("已完成，删除了 %d 条过期收听记录", pruned), nil
+			}
+			return "已完成", nil
+		})
+	}
+
+	ui.WatchConfig(sources.files)
+	for _, remote := range sources.remotes {
+		name := "远程目录 " + remote.Name()
+		remote.OnUpdate(func() { ui.CatalogUpdated(name) })
+	}
+
+	if *remoteFlags.refresh > 0 {
+		for _, remote := range sources.remotes {
+			remote := remote
+			delay := *remoteFlags.refresh - time.Since(remote.FetchedAt())
+			if delay < 0 {
+				delay = 0
+			}
+			ui.SchedulePeriodic("刷新远程目录 "+remote.Name(), delay, *remoteFlags.refresh, func() (string, error) {
+				changed, err := remote.Refresh()
+				if err != nil {
+					return "", err
+				}
+				if !changed {
+					return "无变化", nil
+				}
+				return "已更新", nil
+			})
+		}
+	}
 
 	// Run the application
 	ui.Run()
 }
+
+// mergeUserData 与同步目录合并收藏与收听历史，失败时只记录日志，不影响使用
+func mergeUserData(database db.UserDataStore, dir string) {
+	report, err := database.MergeUserData(dir)
+	if err != nil {
+		logger.Error("同步收藏与历史失败: %v", err)
+		return
+	}
+	logger.Info("%s", formatMergeReport(report))
+}